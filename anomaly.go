@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"time"
+)
+
+const (
+	AlertHighRate      = "high_rate"
+	AlertZeroAmount    = "zero_amount"
+	AlertAmountOutlier = "amount_outlier"
+
+	anomalyBatchSize     = 1000
+	anomalyRateWindow    = time.Minute
+	anomalyZeroWindow    = time.Hour
+	anomalyMinSamples    = 20
+	anomalyMinBurst      = 10
+	anomalyRateFactor    = 3
+	anomalyZeroBurst     = 5
+	anomalyMaxZeroShare  = 0.5
+	anomalyAmountSigmas  = 4
+	anomalyRateSmoothing = 0.1
+
+	anomalyQuarantineSetting = "anomaly_quarantine"
+)
+
+type DeviceBaseline struct {
+	DeviceID   string
+	Plays      int
+	ZeroPlays  int
+	AmountMean float64
+	AmountM2   float64
+	RateMean   float64
+}
+
+// Observe folds one play into the baseline: a running mean and variance of
+// the amount paid, and a moving average of the per-minute play rate.
+func (b *DeviceBaseline) Observe(amount float64, rate int) {
+	b.Plays++
+	if amount == 0 {
+		b.ZeroPlays++
+	}
+	delta := amount - b.AmountMean
+	b.AmountMean += delta / float64(b.Plays)
+	b.AmountM2 += delta * (amount - b.AmountMean)
+	if b.Plays == 1 {
+		b.RateMean = float64(rate)
+	} else {
+		b.RateMean += anomalyRateSmoothing * (float64(rate) - b.RateMean)
+	}
+}
+
+func (b *DeviceBaseline) AmountStdDev() float64 {
+	if b.Plays < 2 {
+		return 0
+	}
+	return math.Sqrt(b.AmountM2 / float64(b.Plays-1))
+}
+
+// detectAnomalies checks a play against the device's baseline before the play
+// is folded into it. rate is the number of plays on the device in the last
+// minute and zeroPlays the number of zero-amount plays in the last hour, both
+// including the play itself.
+func detectAnomalies(log PlaybackLog, baseline DeviceBaseline, rate, zeroPlays int) []Alert {
+	var alerts []Alert
+	learned := baseline.Plays >= anomalyMinSamples
+
+	if rate >= anomalyMinBurst && (!learned || float64(rate) > anomalyRateFactor*baseline.RateMean) {
+		alerts = append(alerts, Alert{
+			Kind:        AlertHighRate,
+			Message:     fmt.Sprintf("%d plays in the last minute (baseline %.1f)", rate, baseline.RateMean),
+			WindowStart: log.PlayedAt.Add(-anomalyRateWindow),
+		})
+	}
+
+	if log.AmountPaid == 0 && zeroPlays >= anomalyZeroBurst {
+		share := 0.0
+		if baseline.Plays > 0 {
+			share = float64(baseline.ZeroPlays) / float64(baseline.Plays)
+		}
+		if !learned || share < anomalyMaxZeroShare {
+			alerts = append(alerts, Alert{
+				Kind:        AlertZeroAmount,
+				Message:     fmt.Sprintf("%d zero-amount plays in the last hour", zeroPlays),
+				WindowStart: log.PlayedAt.Add(-anomalyZeroWindow),
+			})
+		}
+	}
+
+	if sd := baseline.AmountStdDev(); learned && sd > 0 && math.Abs(log.AmountPaid-baseline.AmountMean) > anomalyAmountSigmas*sd {
+		alerts = append(alerts, Alert{
+			Kind:        AlertAmountOutlier,
+			Message:     fmt.Sprintf("amount %.2f deviates from mean %.2f (stddev %.2f)", log.AmountPaid, baseline.AmountMean, sd),
+			WindowStart: log.PlayedAt,
+		})
+	}
+
+	for i := range alerts {
+		alerts[i].DeviceID = log.DeviceID
+		alerts[i].LogID = log.ID
+		alerts[i].Count = 1
+		alerts[i].WindowEnd = log.PlayedAt
+	}
+	return alerts
+}
+
+// AnomalyDetector evaluates new playback logs against per-device baselines
+// and records alerts for suspicious patterns. When quarantine is enabled the
+// plays covered by an alert are excluded from play statistics.
+type AnomalyDetector struct {
+	repo IRepository
+}
+
+func NewAnomalyDetector(repo IRepository) *AnomalyDetector {
+	return &AnomalyDetector{repo: repo}
+}
+
+func (d *AnomalyDetector) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := d.RunOnce(); err != nil {
+			slog.Error("anomaly detector failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce evaluates every log added since the previous run and returns how
+// many alerts were raised or extended.
+func (d *AnomalyDetector) RunOnce() (int, error) {
+	lastID, err := d.repo.GetAnomalyCursor()
+	if err != nil {
+		return 0, err
+	}
+	quarantine, err := d.repo.GetSetting(anomalyQuarantineSetting)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		logs, err := d.repo.GetLogsAfter(lastID, anomalyBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(logs) == 0 {
+			return total, nil
+		}
+
+		baselines := make(map[string]*DeviceBaseline)
+		open := make(map[string]*Alert)
+		var touched []*Alert
+		for _, log := range logs {
+			if log.DeviceID == "" || log.VoidedAt != nil {
+				continue
+			}
+
+			baseline, ok := baselines[log.DeviceID]
+			if !ok {
+				if baseline, err = d.repo.GetDeviceBaseline(log.DeviceID); err != nil {
+					return total, err
+				}
+				baselines[log.DeviceID] = baseline
+			}
+
+			rate, _, err := d.repo.GetDeviceActivity(log.DeviceID, log.PlayedAt.Add(-anomalyRateWindow), log.ID)
+			if err != nil {
+				return total, err
+			}
+			_, zeroPlays, err := d.repo.GetDeviceActivity(log.DeviceID, log.PlayedAt.Add(-anomalyZeroWindow), log.ID)
+			if err != nil {
+				return total, err
+			}
+
+			for _, alert := range detectAnomalies(log, *baseline, rate, zeroPlays) {
+				key := alert.DeviceID + "/" + alert.Kind
+				current, ok := open[key]
+				if !ok {
+					if current, err = d.repo.GetOpenAlert(alert.DeviceID, alert.Kind); err != nil {
+						return total, err
+					}
+					if current != nil {
+						touched = append(touched, current)
+					}
+				}
+
+				// Extend an open alert while the pattern continues, otherwise start a new one.
+				if current != nil && !current.WindowEnd.Before(alert.WindowStart) {
+					current.WindowEnd = alert.WindowEnd
+					current.Count++
+				} else {
+					alert.Quarantined = quarantine == "true"
+					alert.CreatedAt = time.Now()
+					current = &alert
+					touched = append(touched, current)
+				}
+				open[key] = current
+				total++
+			}
+			baseline.Observe(log.AmountPaid, rate)
+		}
+
+		var updated []DeviceBaseline
+		for _, baseline := range baselines {
+			updated = append(updated, *baseline)
+		}
+		var alerts []Alert
+		for _, alert := range touched {
+			alerts = append(alerts, *alert)
+		}
+
+		lastID = logs[len(logs)-1].ID
+		if err := d.repo.SaveAnomalyBatch(updated, alerts, lastID); err != nil {
+			return total, err
+		}
+	}
+}
@@ -0,0 +1,345 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// isrcSource is the external id source that resolves through the
+	// tracks' own ISRC rather than the partner id mapping.
+	isrcSource = "isrc"
+
+	maxTagLength = 50
+)
+
+var isrcPattern = regexp.MustCompile(`^[A-Z]{2}[A-Z0-9]{3}[0-9]{7}$`)
+
+// normalizeISRC upper-cases an ISRC and drops the hyphens it is often
+// printed with (US-RC1-76-07839).
+func normalizeISRC(isrc string) string {
+	return strings.ToUpper(strings.ReplaceAll(strings.TrimSpace(isrc), "-", ""))
+}
+
+func normalizeSource(source string) string {
+	return strings.ToLower(strings.TrimSpace(source))
+}
+
+// normalizeTag lower-cases and trims a tag, so "Rock" and " rock" are the
+// same tag.
+func normalizeTag(tag string) (string, error) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" || len(tag) > maxTagLength || strings.Contains(tag, ",") {
+		return "", InvalidTag
+	}
+	return tag, nil
+}
+
+// normalizeTags normalizes tags and returns them sorted without duplicates.
+func normalizeTags(tags []string) ([]string, error) {
+	var normalized []string
+	for _, tag := range tags {
+		tag, err := normalizeTag(tag)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, tag)
+	}
+	slices.Sort(normalized)
+	return slices.Compact(normalized), nil
+}
+
+const (
+	CatalogCreate = "create"
+	CatalogUpdate = "update"
+
+	CatalogFormatCSV  = "csv"
+	CatalogFormatJSON = "json"
+
+	// matchedByTitleArtist marks rows matched on title and artist rather
+	// than an identifier.
+	matchedByTitleArtist = "title_artist"
+
+	maxCatalogUpload = 32 << 20
+
+	// catalogImportAttempts is how often an import is planned and applied
+	// before a catalog that keeps changing fails it.
+	catalogImportAttempts = 2
+)
+
+// catalogKey is the title and artist a catalog row is matched on when it
+// has no identifier that is already known, ignoring case and spacing.
+func catalogKey(title, artist string) string {
+	fold := func(s string) string {
+		return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+	}
+	return fold(title) + "\x00" + fold(artist)
+}
+
+// normalizeCatalogEntry trims and validates a catalog row.
+func normalizeCatalogEntry(entry CatalogEntry, now time.Time) (CatalogEntry, error) {
+	entry.Title = strings.TrimSpace(entry.Title)
+	entry.Artist = strings.TrimSpace(entry.Artist)
+	entry.Album = strings.TrimSpace(entry.Album)
+	if entry.Title == "" || entry.Artist == "" {
+		return entry, MissingTitleOrArtist
+	}
+	if entry.Price < 0 {
+		return entry, PriceMustBeGreater
+	}
+	if entry.DurationSeconds < 0 {
+		return entry, InvalidDuration
+	}
+	if entry.ReleaseYear < 0 || entry.ReleaseYear > now.Year()+1 {
+		return entry, InvalidReleaseYear
+	}
+
+	entry.ISRC = normalizeISRC(entry.ISRC)
+	if entry.ISRC != "" && !isrcPattern.MatchString(entry.ISRC) {
+		return entry, InvalidISRC
+	}
+
+	tags, err := normalizeTags(entry.Tags)
+	if err != nil {
+		return entry, err
+	}
+	entry.Tags = tags
+
+	ids := make(map[string]string, len(entry.ExternalIDs))
+	for source, id := range entry.ExternalIDs {
+		source, id = normalizeSource(source), strings.TrimSpace(id)
+		if source == "" || source == isrcSource || id == "" {
+			return entry, InvalidExternalID
+		}
+		ids[source] = id
+	}
+	entry.ExternalIDs = ids
+	return entry, nil
+}
+
+// catalogCompatible reports whether a row could describe the track, i.e.
+// the track has no ISRC or partner id that differs from the row's.
+func catalogCompatible(track Track, ids map[string]string, entry CatalogEntry) bool {
+	if entry.ISRC != "" && track.ISRC != "" && track.ISRC != entry.ISRC {
+		return false
+	}
+	for source, id := range entry.ExternalIDs {
+		if existing, ok := ids[source]; ok && existing != id {
+			return false
+		}
+	}
+	return true
+}
+
+// applyCatalogEntry returns the track with the row's non-zero fields. Title
+// and artist are kept when they only differ in case; the artist's spelling
+// comes from the artists table anyway.
+func applyCatalogEntry(track Track, entry CatalogEntry) Track {
+	if !strings.EqualFold(track.Title, entry.Title) {
+		track.Title = entry.Title
+	}
+	if !strings.EqualFold(track.Artist, entry.Artist) {
+		track.Artist = entry.Artist
+	}
+	if entry.Price > 0 {
+		track.Price = entry.Price
+	}
+	if entry.DurationSeconds > 0 {
+		track.DurationSeconds = entry.DurationSeconds
+	}
+	if entry.ISRC != "" {
+		track.ISRC = entry.ISRC
+	}
+	if entry.Album != "" {
+		track.Album = entry.Album
+	}
+	if entry.ReleaseYear > 0 {
+		track.ReleaseYear = entry.ReleaseYear
+	}
+	if len(entry.Tags) > 0 {
+		track.Tags = entry.Tags
+	}
+	return track
+}
+
+// diffTrack lists the catalog fields that differ between two versions of a
+// track. Unset numbers are shown as empty.
+func diffTrack(old, updated Track) []FieldChange {
+	number := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+	price := func(p float64) string {
+		if p == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(p, 'f', 2, 64)
+	}
+
+	fields := []FieldChange{
+		{"title", old.Title, updated.Title},
+		{"artist", old.Artist, updated.Artist},
+		{"price", price(old.Price), price(updated.Price)},
+		{"duration_seconds", number(old.DurationSeconds), number(updated.DurationSeconds)},
+		{"isrc", old.ISRC, updated.ISRC},
+		{"album", old.Album, updated.Album},
+		{"release_year", number(old.ReleaseYear), number(updated.ReleaseYear)},
+		{"tags", strings.Join(old.Tags, "|"), strings.Join(updated.Tags, "|")},
+	}
+	var changes []FieldChange
+	for _, field := range fields {
+		if field.Old != field.New {
+			changes = append(changes, field)
+		}
+	}
+	return changes
+}
+
+// parseCatalog reads catalog entries from a JSON array or a CSV file with
+// a header row. CSV columns are named like the JSON fields, tags are
+// separated by "|" and an "id:<source>" column holds a partner's ids.
+func parseCatalog(r io.Reader, format string) ([]CatalogEntry, error) {
+	switch format {
+	case CatalogFormatJSON:
+		var entries []CatalogEntry
+		decoder := json.NewDecoder(r)
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&entries); err != nil {
+			return nil, fmt.Errorf("%w: %v", InvalidCatalogFile, err)
+		}
+		return entries, nil
+	case CatalogFormatCSV:
+		return parseCatalogCSV(r)
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", InvalidCatalogFile, format)
+	}
+}
+
+func parseCatalogCSV(r io.Reader) ([]CatalogEntry, error) {
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", InvalidCatalogFile, err)
+	}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(column))
+		switch {
+		case column == "title", column == "artist", column == "price", column == "duration_seconds",
+			column == "isrc", column == "album", column == "release_year", column == "tags":
+		case strings.HasPrefix(column, "id:") && len(column) > len("id:"):
+		default:
+			return nil, fmt.Errorf("%w: unknown column %q", InvalidCatalogFile, header[i])
+		}
+		header[i] = column
+	}
+
+	var entries []CatalogEntry
+	for row := 1; ; row++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return entries, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", InvalidCatalogFile, err)
+		}
+
+		var entry CatalogEntry
+		for i, value := range record {
+			value = strings.TrimSpace(value)
+			if value == "" {
+				continue
+			}
+			switch column := header[i]; column {
+			case "title":
+				entry.Title = value
+			case "artist":
+				entry.Artist = value
+			case "price":
+				entry.Price, err = strconv.ParseFloat(value, 64)
+			case "duration_seconds":
+				entry.DurationSeconds, err = strconv.Atoi(value)
+			case "isrc":
+				entry.ISRC = value
+			case "album":
+				entry.Album = value
+			case "release_year":
+				entry.ReleaseYear, err = strconv.Atoi(value)
+			case "tags":
+				entry.Tags = strings.Split(value, "|")
+			default:
+				if entry.ExternalIDs == nil {
+					entry.ExternalIDs = make(map[string]string)
+				}
+				entry.ExternalIDs[strings.TrimPrefix(column, "id:")] = value
+			}
+			if err != nil {
+				return nil, fmt.Errorf("%w: row %d: invalid %s %q", InvalidCatalogFile, row, header[i], value)
+			}
+		}
+		entries = append(entries, entry)
+	}
+}
+
+// catalogFormat picks the format from an explicit name, falling back to
+// the file extension or content type.
+func catalogFormat(format, hint string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.Contains(strings.ToLower(hint), CatalogFormatCSV) {
+		return CatalogFormatCSV
+	}
+	return CatalogFormatJSON
+}
+
+// runImportCatalog implements the import-catalog command, which prints the
+// import report as JSON.
+func runImportCatalog(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("import-catalog", flag.ContinueOnError)
+	db := flags.String("db", dbPath, "SQLite database `path`")
+	format := flags.String("format", "", "csv or json; taken from the file extension when empty")
+	dryRun := flags.Bool("dry-run", false, "report the changes without applying them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: import-catalog [flags] FILE")
+	}
+
+	path := flags.Arg(0)
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	entries, err := parseCatalog(f, catalogFormat(*format, filepath.Ext(path)))
+	if err != nil {
+		return err
+	}
+
+	repo, err := NewSQLiteRepository(*db)
+	if err != nil {
+		return err
+	}
+	report, err := NewService(repo).ImportCatalog(entries, *dryRun)
+	if report != nil {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(report); err != nil {
+			return err
+		}
+	}
+	return err
+}
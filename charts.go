@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+	"time"
+)
+
+const (
+	MovementUp      = "up"
+	MovementDown    = "down"
+	MovementSame    = "same"
+	MovementNew     = "new"
+	MovementReEntry = "re-entry"
+)
+
+// chartAnchor aligns chart periods so weekly charts run Monday to Monday UTC.
+var chartAnchor = time.Date(1970, 1, 5, 0, 0, 0, 0, time.UTC)
+
+// chartPeriodStart returns the start of the chart period containing t.
+func chartPeriodStart(t time.Time, period time.Duration) time.Time {
+	elapsed := t.Sub(chartAnchor)
+	return chartAnchor.Add(elapsed / period * period)
+}
+
+// buildChart ranks tracks by plays, then revenue, and annotates each entry
+// against the latest previous entry of the same track. history holds that
+// entry per track; previousID is the snapshot the new chart follows.
+func buildChart(totals []TrackStat, history []ChartEntry, previousID, size int) []ChartEntry {
+	var ranked []TrackStat
+	for _, total := range totals {
+		if total.Plays > 0 {
+			ranked = append(ranked, total)
+		}
+	}
+	sort.Slice(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		if a.Plays != b.Plays {
+			return a.Plays > b.Plays
+		}
+		if a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		return a.TrackID < b.TrackID
+	})
+	if len(ranked) > size {
+		ranked = ranked[:size]
+	}
+
+	last := make(map[int]ChartEntry)
+	for _, entry := range history {
+		last[entry.TrackID] = entry
+	}
+
+	entries := make([]ChartEntry, 0, len(ranked))
+	for i, track := range ranked {
+		entry := ChartEntry{
+			Position:     i + 1,
+			TrackID:      track.TrackID,
+			Title:        track.Title,
+			Plays:        track.Plays,
+			Revenue:      track.Revenue,
+			Movement:     MovementNew,
+			WeeksOnChart: 1,
+			PeakPosition: i + 1,
+		}
+		if prev, ok := last[track.TrackID]; ok {
+			entry.WeeksOnChart = prev.WeeksOnChart + 1
+			entry.PeakPosition = min(prev.PeakPosition, entry.Position)
+			switch {
+			case prev.SnapshotID != previousID:
+				entry.Movement = MovementReEntry
+			case prev.Position > entry.Position:
+				entry.PreviousPosition = prev.Position
+				entry.Movement = MovementUp
+			case prev.Position < entry.Position:
+				entry.PreviousPosition = prev.Position
+				entry.Movement = MovementDown
+			default:
+				entry.PreviousPosition = prev.Position
+				entry.Movement = MovementSame
+			}
+		}
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// ChartJob persists a chart snapshot for every period that has ended since
+// the latest snapshot.
+type ChartJob struct {
+	repo   IRepository
+	period time.Duration
+	size   int
+}
+
+func NewChartJob(repo IRepository, period time.Duration, size int) *ChartJob {
+	return &ChartJob{repo: repo, period: period, size: size}
+}
+
+func (j *ChartJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(time.Now()); err != nil {
+			slog.Error("chart job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce snapshots the periods that ended by now and returns how many
+// snapshots were saved. Without any snapshot yet, only the last full period
+// is charted.
+func (j *ChartJob) RunOnce(now time.Time) (int, error) {
+	snapshots, err := j.repo.GetChartSnapshots()
+	if err != nil {
+		return 0, err
+	}
+
+	start := chartPeriodStart(now, j.period).Add(-j.period)
+	previousID := 0
+	if len(snapshots) > 0 {
+		start = snapshots[0].PeriodEnd
+		previousID = snapshots[0].ID
+	}
+
+	saved := 0
+	for ; !start.Add(j.period).After(now); start = start.Add(j.period) {
+		end := start.Add(j.period)
+		totals, err := j.repo.GetTrackTotals(start, end)
+		if err != nil {
+			return saved, err
+		}
+		history, err := j.repo.GetChartHistory()
+		if err != nil {
+			return saved, err
+		}
+
+		snapshot, err := j.repo.SaveChartSnapshot(ChartSnapshot{
+			PeriodStart: start,
+			PeriodEnd:   end,
+			CreatedAt:   now,
+			Entries:     buildChart(totals, history, previousID, j.size),
+		})
+		if err != nil {
+			return saved, err
+		}
+		slog.Info("chart snapshot saved", "snapshot_id", snapshot.ID, "period_start", start, "entries", len(snapshot.Entries))
+		previousID = snapshot.ID
+		saved++
+	}
+	return saved, nil
+}
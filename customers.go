@@ -0,0 +1,23 @@
+package main
+
+const (
+	WalletTopUp   = "top_up"
+	WalletDebit   = "debit"
+	WalletLoyalty = "loyalty"
+	// WalletRefund returns the credit paid for a play that was voided.
+	WalletRefund = "refund"
+	// WalletLoyaltyReversal takes back the reward of a play that was voided.
+	WalletLoyaltyReversal = "loyalty_reversal"
+
+	loyaltySetting = "loyalty_free_every"
+)
+
+// loyaltyReward returns the credit earned by a customer's nth play. Every
+// freeEvery-th play is free: its list price is credited back to the wallet,
+// which offsets the debit when the play itself was paid with credit.
+func loyaltyReward(n, freeEvery int, listPrice float64) float64 {
+	if freeEvery <= 0 || n%freeEvery != 0 {
+		return 0
+	}
+	return listPrice
+}
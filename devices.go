@@ -0,0 +1,100 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+const (
+	AlertDeviceOffline = "device_offline"
+
+	deviceOfflineAfter = 5 * time.Minute
+)
+
+// OpenAt reports whether the venue is open at the given time. A venue
+// without opening hours is always open.
+func (v Venue) OpenAt(at time.Time) bool {
+	if v.OpensAt == "" {
+		return true
+	}
+	return inTimeRange(v.TimeZone, v.OpensAt, v.ClosesAt, v.Weekdays, at)
+}
+
+// DeviceMonitor raises an alert, and a device.offline webhook event, when a
+// device misses its heartbeats while its venue is open.
+type DeviceMonitor struct {
+	repo IRepository
+}
+
+func NewDeviceMonitor(repo IRepository) *DeviceMonitor {
+	return &DeviceMonitor{repo: repo}
+}
+
+func (m *DeviceMonitor) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := m.RunOnce(time.Now()); err != nil {
+			slog.Error("device monitor failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce checks every registered device and returns how many offline
+// alerts were raised.
+func (m *DeviceMonitor) RunOnce(now time.Time) (int, error) {
+	devices, err := m.repo.GetDevices()
+	if err != nil {
+		return 0, err
+	}
+
+	raised := 0
+	for _, device := range devices {
+		if now.Sub(device.LastSeenAt) < deviceOfflineAfter {
+			continue
+		}
+		if device.VenueID != "" {
+			venue, err := m.repo.GetVenueByID(device.VenueID)
+			if err == nil && !venue.OpenAt(now) {
+				continue
+			}
+		}
+
+		open, err := m.repo.GetOpenAlert(device.DeviceID, AlertDeviceOffline)
+		if err != nil {
+			return raised, err
+		}
+		if open != nil {
+			continue
+		}
+
+		alert, err := m.repo.CreateOfflineAlert(Alert{
+			DeviceID:    device.DeviceID,
+			Kind:        AlertDeviceOffline,
+			Message:     fmt.Sprintf("no heartbeat since %s", device.LastSeenAt.UTC().Format(time.RFC3339)),
+			Count:       1,
+			WindowStart: device.LastSeenAt,
+			WindowEnd:   now,
+			CreatedAt:   now,
+		}, DeviceOfflineEvent{
+			DeviceID:   device.DeviceID,
+			VenueID:    device.VenueID,
+			LastSeenAt: device.LastSeenAt,
+		})
+		if err != nil {
+			return raised, err
+		}
+		slog.Warn("device offline", "device_id", device.DeviceID, "venue_id", device.VenueID, "alert_id", alert.ID)
+		raised++
+	}
+	return raised, nil
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+var (
+	bracketedText = regexp.MustCompile(`\([^)]*\)|\[[^\]]*\]`)
+	versionSuffix = regexp.MustCompile(`\s-\s.*\b(remaster(ed)?|version|edit|mix|mono|stereo|live|demo)\b.*$`)
+	nonWordChars  = regexp.MustCompile(`[^\p{L}\p{N}]+`)
+)
+
+// normalizeTitle reduces a title to the words that identify the song, so
+// "Comfortably Numb (Remastered)" and "Comfortably Numb - 2011 Remaster"
+// both become "comfortably numb".
+func normalizeTitle(title string) string {
+	title = strings.ToLower(title)
+	title = bracketedText.ReplaceAllString(title, " ")
+	title = versionSuffix.ReplaceAllString(title, " ")
+	return strings.Join(strings.Fields(nonWordChars.ReplaceAllString(title, " ")), " ")
+}
+
+// normalizeArtistName ignores case, punctuation and a leading "The".
+func normalizeArtistName(artist string) string {
+	artist = strings.Join(strings.Fields(nonWordChars.ReplaceAllString(strings.ToLower(artist), " ")), " ")
+	return strings.TrimPrefix(artist, "the ")
+}
+
+// similarity is 1 minus the edit distance between a and b relative to the
+// longer of the two, so equal strings score 1.
+func similarity(a, b string) float64 {
+	if a == b {
+		return 1
+	}
+	ra, rb := []rune(a), []rune(b)
+	return 1 - float64(editDistance(ra, rb))/float64(max(len(ra), len(rb)))
+}
+
+func editDistance(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+// findDuplicates pairs tracks by the same artist whose normalized titles
+// are at least minScore similar. The track with more plays, or else the
+// older one, is suggested as canonical.
+func findDuplicates(tracks []Track, plays map[int]int, minScore float64) []DuplicateTrack {
+	byArtist := make(map[string][]Track)
+	for _, track := range tracks {
+		artist := normalizeArtistName(track.Artist)
+		byArtist[artist] = append(byArtist[artist], track)
+	}
+
+	var duplicates []DuplicateTrack
+	for _, group := range byArtist {
+		titles := make([]string, len(group))
+		for i, track := range group {
+			titles[i] = normalizeTitle(track.Title)
+		}
+		for i := range group {
+			for j := i + 1; j < len(group); j++ {
+				if titles[i] == "" || titles[j] == "" {
+					continue
+				}
+				score := similarity(titles[i], titles[j])
+				if score < minScore {
+					continue
+				}
+				canonical, duplicate := group[i], group[j]
+				if plays[duplicate.ID] > plays[canonical.ID] ||
+					plays[duplicate.ID] == plays[canonical.ID] && duplicate.ID < canonical.ID {
+					canonical, duplicate = duplicate, canonical
+				}
+				duplicates = append(duplicates, DuplicateTrack{Canonical: canonical, Duplicate: duplicate, Score: roundScore(score)})
+			}
+		}
+	}
+
+	sort.Slice(duplicates, func(i, j int) bool {
+		if duplicates[i].Score != duplicates[j].Score {
+			return duplicates[i].Score > duplicates[j].Score
+		}
+		if duplicates[i].Canonical.ID != duplicates[j].Canonical.ID {
+			return duplicates[i].Canonical.ID < duplicates[j].Canonical.ID
+		}
+		return duplicates[i].Duplicate.ID < duplicates[j].Duplicate.ID
+	})
+	return duplicates
+}
+
+func roundScore(score float64) float64 {
+	return math.Round(score*1000) / 1000
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"errors"
 	"log/slog"
+	"time"
 
 	pb "jukebox-analytic/proto"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 type GRPCServer struct {
@@ -18,7 +21,24 @@ func NewGRPCServer(s *Service) *GRPCServer {
 }
 
 func (s *GRPCServer) LogPlayback(ctx context.Context, req *pb.LogPlaybackRequest) (*pb.Empty, error) {
-	err := s.service.CreateLog(int(req.TrackId), req.AmountPaid)
+	trackID := int(req.TrackId)
+	if req.ExternalId != "" {
+		track, err := s.service.LookupTrack(req.ExternalSource, req.ExternalId)
+		if err != nil {
+			slog.Error("grpc: failed to look up track", "error", err, "source", req.ExternalSource, "external_id", req.ExternalId)
+			return nil, err
+		}
+		trackID = track.ID
+	}
+
+	err := s.service.CreateLog(PlaybackLog{
+		TrackID:        trackID,
+		AmountPaid:     req.AmountPaid,
+		DeviceID:       req.DeviceId,
+		SessionID:      req.SessionId,
+		CustomerID:     req.CustomerId,
+		PaidWithCredit: req.PayWithCredit,
+	})
 	if err != nil {
 		slog.Error("grpc: failed to log playback", "error", err)
 		return nil, err
@@ -32,7 +52,10 @@ func (s *GRPCServer) GetTopTracks(ctx context.Context, req *pb.Empty) (*pb.TopTr
 		slog.Error("grpc: failed to get top tracks", "error", err)
 		return nil, err
 	}
+	return topTracksResponse(stats), nil
+}
 
+func topTracksResponse(stats []TopTrackStat) *pb.TopTracksResponse {
 	var pbStats []*pb.TopTrack
 	for _, stat := range stats {
 		pbStats = append(pbStats, &pb.TopTrack{
@@ -41,7 +64,7 @@ func (s *GRPCServer) GetTopTracks(ctx context.Context, req *pb.Empty) (*pb.TopTr
 		})
 	}
 
-	return &pb.TopTracksResponse{Tracks: pbStats}, nil
+	return &pb.TopTracksResponse{Tracks: pbStats}
 }
 
 func (s *GRPCServer) UpdatePrice(ctx context.Context, req *pb.UpdatePriceRequest) (*pb.Empty, error) {
@@ -54,3 +77,463 @@ func (s *GRPCServer) UpdatePrice(ctx context.Context, req *pb.UpdatePriceRequest
 	}
 	return &pb.Empty{}, nil
 }
+
+func (s *GRPCServer) GetTrendingTracks(ctx context.Context, req *pb.Empty) (*pb.TrendingTracksResponse, error) {
+	stats, err := s.service.GetTrendingTracks()
+	if err != nil {
+		slog.Error("grpc: failed to get trending tracks", "error", err)
+		return nil, err
+	}
+
+	var pbStats []*pb.TrendingTrack
+	for _, stat := range stats {
+		pbStats = append(pbStats, &pb.TrendingTrack{
+			TrackId:       int32(stat.TrackID),
+			Title:         stat.Title,
+			RecentCount:   int32(stat.RecentCount),
+			BaselineCount: int32(stat.BaselineCount),
+			Score:         stat.Score,
+		})
+	}
+
+	return &pb.TrendingTracksResponse{Tracks: pbStats}, nil
+}
+
+func (s *GRPCServer) GetRelatedTracks(ctx context.Context, req *pb.RelatedTracksRequest) (*pb.RelatedTracksResponse, error) {
+	related, err := s.service.GetRelatedTracks(int(req.TrackId))
+	if err != nil {
+		if errors.Is(err, TrackNotFoundError) {
+			slog.Warn("grpc: track not found", "track_id", req.TrackId)
+		} else {
+			slog.Error("grpc: failed to get related tracks", "error", err)
+		}
+		return nil, err
+	}
+
+	var pbTracks []*pb.RelatedTrack
+	for _, rt := range related {
+		pbTracks = append(pbTracks, &pb.RelatedTrack{
+			TrackId: int32(rt.TrackID),
+			Title:   rt.Title,
+			Artist:  rt.Artist,
+			Count:   int32(rt.Count),
+		})
+	}
+
+	return &pb.RelatedTracksResponse{Tracks: pbTracks}, nil
+}
+
+func toPBScheduledPrice(sp *ScheduledPrice) *pb.ScheduledPrice {
+	msg := &pb.ScheduledPrice{
+		Id:       int32(sp.ID),
+		TrackId:  int32(sp.TrackID),
+		Price:    sp.Price,
+		StartsAt: timestamppb.New(sp.StartsAt),
+		Status:   sp.Status,
+	}
+	if sp.EndsAt != nil {
+		msg.EndsAt = timestamppb.New(*sp.EndsAt)
+	}
+	return msg
+}
+
+func (s *GRPCServer) SchedulePrice(ctx context.Context, req *pb.SchedulePriceRequest) (*pb.ScheduledPrice, error) {
+	var endsAt *time.Time
+	if req.EndsAt != nil {
+		t := req.EndsAt.AsTime()
+		endsAt = &t
+	}
+
+	var startsAt time.Time
+	if req.StartsAt != nil {
+		startsAt = req.StartsAt.AsTime()
+	}
+
+	sp, err := s.service.SchedulePrice(int(req.TrackId), req.Price, startsAt, endsAt)
+	if err != nil {
+		if errors.Is(err, TrackNotFoundError) {
+			slog.Warn("grpc: track not found", "track_id", req.TrackId)
+		} else {
+			slog.Error("grpc: failed to schedule price", "error", err)
+		}
+		return nil, err
+	}
+	return toPBScheduledPrice(sp), nil
+}
+
+func (s *GRPCServer) ListScheduledPrices(ctx context.Context, req *pb.ListScheduledPricesRequest) (*pb.ScheduledPricesResponse, error) {
+	prices, err := s.service.GetScheduledPrices(req.Status)
+	if err != nil {
+		slog.Error("grpc: failed to list scheduled prices", "error", err)
+		return nil, err
+	}
+
+	var pbPrices []*pb.ScheduledPrice
+	for _, sp := range prices {
+		pbPrices = append(pbPrices, toPBScheduledPrice(&sp))
+	}
+	return &pb.ScheduledPricesResponse{Prices: pbPrices}, nil
+}
+
+func (s *GRPCServer) CancelScheduledPrice(ctx context.Context, req *pb.CancelScheduledPriceRequest) (*pb.Empty, error) {
+	if err := s.service.CancelScheduledPrice(int(req.Id)); err != nil {
+		slog.Error("grpc: failed to cancel scheduled price", "error", err, "schedule_id", req.Id)
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *GRPCServer) VoidPlayback(ctx context.Context, req *pb.VoidPlaybackRequest) (*pb.VoidPlaybackResponse, error) {
+	log, err := s.service.VoidLog(int(req.LogId), req.Reason, req.RefundedAmount)
+	if err != nil {
+		slog.Error("grpc: failed to void playback", "error", err, "log_id", req.LogId)
+		return nil, err
+	}
+
+	return &pb.VoidPlaybackResponse{
+		LogId:          int32(log.ID),
+		TrackId:        int32(log.TrackID),
+		AmountPaid:     log.AmountPaid,
+		RefundedAmount: log.RefundedAmount,
+		Reason:         log.VoidReason,
+		VoidedAt:       timestamppb.New(*log.VoidedAt),
+	}, nil
+}
+
+func toPBQueueItem(item *QueueItem) *pb.QueueItem {
+	msg := &pb.QueueItem{
+		Id:         int32(item.ID),
+		DeviceId:   item.DeviceID,
+		TrackId:    int32(item.TrackID),
+		AmountPaid: item.AmountPaid,
+		Status:     item.Status,
+		LogId:      int32(item.LogID),
+		EnqueuedAt: timestamppb.New(item.EnqueuedAt),
+	}
+	if item.StartedAt != nil {
+		msg.StartedAt = timestamppb.New(*item.StartedAt)
+	}
+	if item.FinishedAt != nil {
+		msg.FinishedAt = timestamppb.New(*item.FinishedAt)
+	}
+	return msg
+}
+
+func (s *GRPCServer) EnqueueTrack(ctx context.Context, req *pb.EnqueueTrackRequest) (*pb.QueueItem, error) {
+	item, err := s.service.EnqueueTrack(req.DeviceId, req.SessionId, int(req.TrackId), req.AmountPaid)
+	if err != nil {
+		slog.Error("grpc: failed to enqueue track", "error", err, "device_id", req.DeviceId, "track_id", req.TrackId)
+		return nil, err
+	}
+	return toPBQueueItem(item), nil
+}
+
+func (s *GRPCServer) ListQueue(ctx context.Context, req *pb.ListQueueRequest) (*pb.QueueResponse, error) {
+	queue, err := s.service.GetQueue(req.DeviceId)
+	if err != nil {
+		slog.Error("grpc: failed to list queue", "error", err, "device_id", req.DeviceId)
+		return nil, err
+	}
+
+	var items []*pb.QueueItem
+	for _, item := range queue {
+		items = append(items, toPBQueueItem(&item))
+	}
+	return &pb.QueueResponse{Items: items}, nil
+}
+
+func (s *GRPCServer) StartQueueItem(ctx context.Context, req *pb.QueueItemRequest) (*pb.QueueItem, error) {
+	item, err := s.service.StartQueueItem(int(req.Id))
+	if err != nil {
+		slog.Error("grpc: failed to start queue item", "error", err, "queue_item_id", req.Id)
+		return nil, err
+	}
+	return toPBQueueItem(item), nil
+}
+
+func (s *GRPCServer) FinishQueueItem(ctx context.Context, req *pb.QueueItemRequest) (*pb.QueueItem, error) {
+	item, err := s.service.FinishQueueItem(int(req.Id))
+	if err != nil {
+		slog.Error("grpc: failed to finish queue item", "error", err, "queue_item_id", req.Id)
+		return nil, err
+	}
+	return toPBQueueItem(item), nil
+}
+
+func (s *GRPCServer) SkipQueueItem(ctx context.Context, req *pb.QueueItemRequest) (*pb.QueueItem, error) {
+	item, err := s.service.SkipQueueItem(int(req.Id))
+	if err != nil {
+		slog.Error("grpc: failed to skip queue item", "error", err, "queue_item_id", req.Id)
+		return nil, err
+	}
+	return toPBQueueItem(item), nil
+}
+
+func (s *GRPCServer) CompletePlayback(ctx context.Context, req *pb.CompletePlaybackRequest) (*pb.Empty, error) {
+	if _, err := s.service.CompleteLog(int(req.LogId), int(req.ListenedSeconds), req.Skipped); err != nil {
+		slog.Error("grpc: failed to complete playback", "error", err, "log_id", req.LogId)
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func (s *GRPCServer) GetSkipStats(ctx context.Context, req *pb.SkipStatsRequest) (*pb.SkipStatsResponse, error) {
+	to := time.Now()
+	if req.To != nil {
+		to = req.To.AsTime()
+	}
+	from := to.Add(-skipStatsWindow)
+	if req.From != nil {
+		from = req.From.AsTime()
+	}
+
+	stats, err := s.service.GetSkipStats(from, to)
+	if err != nil {
+		slog.Error("grpc: failed to get skip stats", "error", err)
+		return nil, err
+	}
+
+	var tracks []*pb.SkipStat
+	for _, st := range stats {
+		tracks = append(tracks, &pb.SkipStat{
+			TrackId:         int32(st.TrackID),
+			Title:           st.Title,
+			DurationSeconds: int32(st.DurationSeconds),
+			Plays:           int32(st.Plays),
+			Skips:           int32(st.Skips),
+			SkipRate:        st.SkipRate,
+			AvgCompletion:   st.AvgCompletion,
+		})
+	}
+	return &pb.SkipStatsResponse{Tracks: tracks}, nil
+}
+
+func (s *GRPCServer) Heartbeat(ctx context.Context, req *pb.HeartbeatRequest) (*pb.Empty, error) {
+	if _, err := s.service.Heartbeat(req.DeviceId, req.FirmwareVersion, req.VenueId); err != nil {
+		slog.Error("grpc: failed to record heartbeat", "error", err, "device_id", req.DeviceId)
+		return nil, err
+	}
+	return &pb.Empty{}, nil
+}
+
+func toPBTrack(track *Track) *pb.Track {
+	return &pb.Track{
+		Id:              int32(track.ID),
+		Title:           track.Title,
+		Artist:          track.Artist,
+		Price:           track.Price,
+		DurationSeconds: int32(track.DurationSeconds),
+		Isrc:            track.ISRC,
+		Album:           track.Album,
+		ReleaseYear:     int32(track.ReleaseYear),
+		Tags:            track.Tags,
+	}
+}
+
+func (s *GRPCServer) SearchTracks(ctx context.Context, req *pb.SearchTracksRequest) (*pb.SearchTracksResponse, error) {
+	result, err := s.service.SearchTracks(req.Query, int(req.Limit), int(req.Offset))
+	if err != nil {
+		slog.Error("grpc: failed to search tracks", "error", err, "query", req.Query)
+		return nil, err
+	}
+
+	var tracks []*pb.Track
+	for _, track := range result.Tracks {
+		tracks = append(tracks, toPBTrack(&track))
+	}
+	return &pb.SearchTracksResponse{Tracks: tracks, Total: int32(result.Total)}, nil
+}
+
+// timeRange converts optional request bounds; a missing lower bound means
+// all time and a missing upper bound means now.
+func timeRange(from, to *timestamppb.Timestamp) (time.Time, time.Time) {
+	end := time.Now()
+	if to != nil {
+		end = to.AsTime()
+	}
+	var start time.Time
+	if from != nil {
+		start = from.AsTime()
+	}
+	return start, end
+}
+
+func (s *GRPCServer) GetTopArtists(ctx context.Context, req *pb.TopArtistsRequest) (*pb.TopArtistsResponse, error) {
+	from, to := timeRange(req.From, req.To)
+	stats, err := s.service.GetTopArtists(req.Sort, int(req.Limit), from, to)
+	if err != nil {
+		slog.Error("grpc: failed to get top artists", "error", err)
+		return nil, err
+	}
+
+	var artists []*pb.ArtistStat
+	for _, st := range stats {
+		artists = append(artists, &pb.ArtistStat{
+			ArtistId: int32(st.ArtistID),
+			Name:     st.Name,
+			Plays:    int32(st.Plays),
+			Revenue:  st.Revenue,
+		})
+	}
+	return &pb.TopArtistsResponse{Artists: artists}, nil
+}
+
+func (s *GRPCServer) GetArtistTracks(ctx context.Context, req *pb.ArtistTracksRequest) (*pb.ArtistTracksResponse, error) {
+	from, to := timeRange(req.From, req.To)
+	breakdown, err := s.service.GetArtistBreakdown(int(req.ArtistId), from, to)
+	if err != nil {
+		slog.Error("grpc: failed to get artist tracks", "error", err, "artist_id", req.ArtistId)
+		return nil, err
+	}
+
+	resp := &pb.ArtistTracksResponse{
+		ArtistId: int32(breakdown.Artist.ID),
+		Name:     breakdown.Artist.Name,
+		Plays:    int32(breakdown.Plays),
+		Revenue:  breakdown.Revenue,
+	}
+	for _, track := range breakdown.Tracks {
+		resp.Tracks = append(resp.Tracks, &pb.TrackStat{
+			TrackId: int32(track.TrackID),
+			Title:   track.Title,
+			Plays:   int32(track.Plays),
+			Revenue: track.Revenue,
+		})
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetHeatmap(ctx context.Context, req *pb.HeatmapRequest) (*pb.HeatmapResponse, error) {
+	from, to := timeRange(req.From, req.To)
+	filter := HeatmapFilter{
+		VenueID:  req.VenueId,
+		TrackID:  int(req.TrackId),
+		ArtistID: int(req.ArtistId),
+		Tag:      req.Tag,
+		From:     from,
+		To:       to,
+	}
+	heatmap, err := s.service.GetHeatmap(filter, req.TimeZone)
+	if err != nil {
+		slog.Error("grpc: failed to get heatmap", "error", err, "venue_id", req.VenueId)
+		return nil, err
+	}
+
+	resp := &pb.HeatmapResponse{TimeZone: heatmap.TimeZone}
+	for weekday, hours := range heatmap.Cells {
+		for hour, cell := range hours {
+			resp.Cells = append(resp.Cells, &pb.HeatmapCell{
+				Weekday: int32(weekday),
+				Hour:    int32(hour),
+				Plays:   int32(cell.Plays),
+				Revenue: cell.Revenue,
+			})
+		}
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetChart(ctx context.Context, req *pb.GetChartRequest) (*pb.Chart, error) {
+	chart, err := s.service.GetChart(int(req.Id))
+	if err != nil {
+		slog.Error("grpc: failed to get chart", "error", err, "chart_id", req.Id)
+		return nil, err
+	}
+
+	resp := &pb.Chart{
+		Id:          int32(chart.ID),
+		PeriodStart: timestamppb.New(chart.PeriodStart),
+		PeriodEnd:   timestamppb.New(chart.PeriodEnd),
+	}
+	for _, entry := range chart.Entries {
+		resp.Entries = append(resp.Entries, &pb.ChartEntry{
+			Position:         int32(entry.Position),
+			TrackId:          int32(entry.TrackID),
+			Title:            entry.Title,
+			Artist:           entry.Artist,
+			Plays:            int32(entry.Plays),
+			Revenue:          entry.Revenue,
+			PreviousPosition: int32(entry.PreviousPosition),
+			Movement:         entry.Movement,
+			WeeksOnChart:     int32(entry.WeeksOnChart),
+			PeakPosition:     int32(entry.PeakPosition),
+		})
+	}
+	return resp, nil
+}
+
+func toPBCustomer(customer *Customer) *pb.Customer {
+	return &pb.Customer{
+		CustomerId: customer.ID,
+		Balance:    customer.Balance,
+		Plays:      int32(customer.Plays),
+	}
+}
+
+func (s *GRPCServer) TopUpCredit(ctx context.Context, req *pb.TopUpCreditRequest) (*pb.Customer, error) {
+	customer, err := s.service.TopUpCredit(req.CustomerId, req.Amount)
+	if err != nil {
+		slog.Error("grpc: failed to top up credit", "error", err, "customer_id", req.CustomerId)
+		return nil, err
+	}
+	return toPBCustomer(customer), nil
+}
+
+func (s *GRPCServer) GetCustomer(ctx context.Context, req *pb.GetCustomerRequest) (*pb.Customer, error) {
+	customer, err := s.service.GetCustomer(req.CustomerId)
+	if err != nil {
+		slog.Error("grpc: failed to get customer", "error", err, "customer_id", req.CustomerId)
+		return nil, err
+	}
+	return toPBCustomer(customer), nil
+}
+
+func (s *GRPCServer) LookupTrack(ctx context.Context, req *pb.LookupTrackRequest) (*pb.Track, error) {
+	track, err := s.service.LookupTrack(req.Source, req.ExternalId)
+	if err != nil {
+		slog.Error("grpc: failed to look up track", "error", err, "source", req.Source, "external_id", req.ExternalId)
+		return nil, err
+	}
+	return toPBTrack(track), nil
+}
+
+// GetTrackStats reports rank 0 for a track that was never played.
+func (s *GRPCServer) GetTrackStats(ctx context.Context, req *pb.TrackStatsRequest) (*pb.TrackStatsResponse, error) {
+	stats, err := s.service.GetTrackStats(int(req.TrackId), req.TimeZone)
+	if err != nil {
+		slog.Error("grpc: failed to get track stats", "error", err, "track_id", req.TrackId)
+		return nil, err
+	}
+
+	resp := &pb.TrackStatsResponse{
+		Track:       toPBTrack(&stats.Track),
+		TimeZone:    stats.TimeZone,
+		Plays:       int32(stats.Plays),
+		Revenue:     stats.Revenue,
+		AveragePaid: stats.AveragePaid,
+	}
+	if stats.FirstPlayedAt != nil {
+		resp.FirstPlayedAt = timestamppb.New(*stats.FirstPlayedAt)
+		resp.LastPlayedAt = timestamppb.New(*stats.LastPlayedAt)
+	}
+	if stats.Rank != nil {
+		resp.Rank = int32(*stats.Rank)
+	}
+	for _, day := range stats.Days {
+		resp.Days = append(resp.Days, &pb.DailyPlays{
+			Date:    day.Date,
+			Plays:   int32(day.Plays),
+			Revenue: day.Revenue,
+		})
+	}
+	return resp, nil
+}
+
+func (s *GRPCServer) GetTagTopTracks(ctx context.Context, req *pb.TagTopTracksRequest) (*pb.TopTracksResponse, error) {
+	stats, err := s.service.GetTagTopTracks(req.Tag)
+	if err != nil {
+		slog.Error("grpc: failed to get top tracks", "error", err, "tag", req.Tag)
+		return nil, err
+	}
+	return topTracksResponse(stats), nil
+}
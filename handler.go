@@ -3,20 +3,116 @@ package main
 import (
 	"encoding/json"
 	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type CreateLogRequest struct {
-	TrackID    int     `json:"track_id"`
-	AmountPaid float64 `json:"amount_paid"`
+	TrackID       int     `json:"track_id"`
+	AmountPaid    float64 `json:"amount_paid"`
+	DeviceID      string  `json:"device_id"`
+	SessionID     string  `json:"session_id"`
+	CustomerID    string  `json:"customer_id"`
+	PayWithCredit bool    `json:"pay_with_credit"`
+
+	// ExternalSource and ExternalID identify the track instead of TrackID,
+	// e.g. "isrc" and "USRC17607839".
+	ExternalSource string `json:"external_source"`
+	ExternalID     string `json:"external_id"`
 }
 
 type UpdatePriceRequest struct {
 	NewPrice float64 `json:"new_price"`
 }
 
+type CreateWebhookRequest struct {
+	URL        string   `json:"url"`
+	EventTypes []string `json:"event_types"`
+	Secret     string   `json:"secret"`
+}
+
+type PriceBoundsRequest struct {
+	Floor   float64 `json:"floor"`
+	Ceiling float64 `json:"ceiling"`
+}
+
+type SchedulePriceRequest struct {
+	Price    float64    `json:"price"`
+	StartsAt time.Time  `json:"starts_at"`
+	EndsAt   *time.Time `json:"ends_at"`
+}
+
+type PaymentPolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+type VoidLogRequest struct {
+	Reason         string   `json:"reason"`
+	RefundedAmount *float64 `json:"refunded_amount"`
+}
+
+type VoidLogResponse struct {
+	LogID          int       `json:"log_id"`
+	TrackID        int       `json:"track_id"`
+	AmountPaid     float64   `json:"amount_paid"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	Reason         string    `json:"reason"`
+	VoidedAt       time.Time `json:"voided_at"`
+}
+
+type EnqueueTrackRequest struct {
+	TrackID    int     `json:"track_id"`
+	AmountPaid float64 `json:"amount_paid"`
+	SessionID  string  `json:"session_id"`
+}
+
+type CompleteLogRequest struct {
+	ListenedSeconds int  `json:"listened_seconds"`
+	Skipped         bool `json:"skipped"`
+}
+
+type TrackDurationRequest struct {
+	DurationSeconds int `json:"duration_seconds"`
+}
+
+type ExternalIDRequest struct {
+	ExternalID string `json:"external_id"`
+}
+
+type MergeTracksRequest struct {
+	DuplicateID int `json:"duplicate_id"`
+}
+
+type TagRequest struct {
+	Name string `json:"name"`
+}
+
+type ResolveAlertRequest struct {
+	Release bool `json:"release"`
+}
+
+type AnomalyQuarantineRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type TopUpRequest struct {
+	Amount float64 `json:"amount"`
+}
+
+type CloseStatementRequest struct {
+	ArtistID    int       `json:"artist_id"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd   time.Time `json:"period_end"`
+}
+
+type HeartbeatRequest struct {
+	FirmwareVersion string `json:"firmware_version"`
+	VenueID         string `json:"venue_id"`
+}
+
 type AnalyticsHandler struct {
 	s *Service
 }
@@ -30,6 +126,43 @@ func respondWithError(w http.ResponseWriter, r *http.Request, status int, messag
 	http.Error(w, message, status)
 }
 
+// parseTimeRange reads the optional "from" and "to" query parameters as
+// RFC 3339 timestamps or dates. Missing bounds default to the span ending now.
+func parseTimeRange(r *http.Request, span time.Duration) (time.Time, time.Time, error) {
+	parse := func(name string, fallback time.Time) (time.Time, error) {
+		value := r.URL.Query().Get(name)
+		if value == "" {
+			return fallback, nil
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, nil
+		}
+		return time.Parse(time.DateOnly, value)
+	}
+
+	to, err := parse("to", time.Now())
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	from, err := parse("from", to.Add(-span))
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	return from, to, nil
+}
+
+// parseAllTimeRange is parseTimeRange without a default lower bound.
+func parseAllTimeRange(r *http.Request) (time.Time, time.Time, error) {
+	from, to, err := parseTimeRange(r, 0)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if r.URL.Query().Get("from") == "" {
+		from = time.Time{}
+	}
+	return from, to, nil
+}
+
 func (h *AnalyticsHandler) HandleLogPlayback(w http.ResponseWriter, r *http.Request) {
 	var req CreateLogRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
@@ -37,10 +170,41 @@ func (h *AnalyticsHandler) HandleLogPlayback(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	err := h.s.CreateLog(req.TrackID, req.AmountPaid)
+	if req.ExternalID != "" {
+		track, err := h.s.LookupTrack(req.ExternalSource, req.ExternalID)
+		if err != nil {
+			details := slog.Group("details", slog.String("source", req.ExternalSource), slog.String("external_id", req.ExternalID))
+			if errors.Is(err, TrackNotFoundError) {
+				respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+			} else if errors.Is(err, InvalidExternalID) {
+				respondWithError(w, r, http.StatusBadRequest, "External source and id are required", err, details)
+			} else {
+				respondWithError(w, r, http.StatusInternalServerError, "Failed to look up track", err, details)
+			}
+			return
+		}
+		req.TrackID = track.ID
+	}
+
+	err := h.s.CreateLog(PlaybackLog{
+		TrackID:        req.TrackID,
+		AmountPaid:     req.AmountPaid,
+		DeviceID:       req.DeviceID,
+		SessionID:      req.SessionID,
+		CustomerID:     req.CustomerID,
+		PaidWithCredit: req.PayWithCredit,
+	})
 	if err != nil {
 		if errors.Is(err, TrackNotFoundError) {
 			respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.Int("track_id", req.TrackID))
+		} else if errors.Is(err, AmountBelowPrice) {
+			respondWithError(w, r, http.StatusBadRequest, "Amount paid is below the current price", err, slog.Int("track_id", req.TrackID))
+		} else if errors.Is(err, NegativeAmount) {
+			respondWithError(w, r, http.StatusBadRequest, "Amount paid must not be negative", err, slog.Int("track_id", req.TrackID))
+		} else if errors.Is(err, MissingCustomerID) {
+			respondWithError(w, r, http.StatusBadRequest, "Customer ID is required to pay with credit", err, slog.Int("track_id", req.TrackID))
+		} else if errors.Is(err, InsufficientCredit) {
+			respondWithError(w, r, http.StatusPaymentRequired, "Insufficient credit", err, slog.String("customer_id", req.CustomerID))
 		} else {
 			respondWithError(w, r, http.StatusInternalServerError, "Failed to create log", err, slog.Int("track_id", req.TrackID))
 		}
@@ -83,12 +247,1764 @@ func (h *AnalyticsHandler) HandleUpdatePrice(w http.ResponseWriter, r *http.Requ
 }
 
 func (h *AnalyticsHandler) HandleGetTopTracks(w http.ResponseWriter, r *http.Request) {
-	top3, err := h.s.GetTopTracks()
+	tag := r.URL.Query().Get("tag")
+	top3, err := h.s.GetTagTopTracks(tag)
 	if err != nil {
-		respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.String("details", "no details"))
+		if errors.Is(err, TagNotFoundError) || errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusNotFound, "Tag not found", err, slog.String("tag", tag))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.String("details", "no details"))
+		}
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(top3)
 }
+
+func (h *AnalyticsHandler) HandleGetRelatedTracks(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	related, err := h.s.GetRelatedTracks(trackID)
+	if err != nil {
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.Int("track_id", trackID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get related tracks", err, slog.Int("track_id", trackID))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(related)
+}
+
+func (h *AnalyticsHandler) HandleGetTrendingTracks(w http.ResponseWriter, r *http.Request) {
+	trending, err := h.s.GetTrendingTracks()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trending)
+}
+
+func (h *AnalyticsHandler) HandleGetRetentionReport(w http.ResponseWriter, r *http.Request) {
+	report, err := h.s.GetRetentionReport()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get retention report", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *AnalyticsHandler) HandleCreateWebhook(w http.ResponseWriter, r *http.Request) {
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	sub, err := h.s.CreateWebhook(req.URL, req.EventTypes, req.Secret)
+	if err != nil {
+		details := slog.Group("details", slog.String("url", req.URL), slog.Any("event_types", req.EventTypes))
+		if errors.Is(err, InvalidWebhookURL) || errors.Is(err, UnknownEventType) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to create webhook", err, details)
+		}
+		return
+	}
+
+	slog.Info("webhook created successfully", "webhook_id", sub.ID, "url", sub.URL)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sub)
+}
+
+func (h *AnalyticsHandler) HandleGetWebhooks(w http.ResponseWriter, r *http.Request) {
+	subs, err := h.s.GetWebhooks()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get webhooks", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(subs)
+}
+
+func (h *AnalyticsHandler) HandleDeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	webhookID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid webhook ID", err, slog.String("webhook_id_str", idStr))
+		return
+	}
+
+	if err := h.s.DeleteWebhook(webhookID); err != nil {
+		if errors.Is(err, WebhookNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Webhook not found", err, slog.Int("webhook_id", webhookID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete webhook", err, slog.Int("webhook_id", webhookID))
+		}
+		return
+	}
+
+	slog.Info("webhook deleted successfully", "webhook_id", webhookID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleGetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	webhookID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid webhook ID", err, slog.String("webhook_id_str", idStr))
+		return
+	}
+
+	deliveries, err := h.s.GetWebhookDeliveries(webhookID)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get webhook deliveries", err, slog.Int("webhook_id", webhookID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(deliveries)
+}
+
+func (h *AnalyticsHandler) HandleCreatePricingRule(w http.ResponseWriter, r *http.Request) {
+	var req PricingRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	rule, err := h.s.CreatePricingRule(req)
+	if err != nil {
+		if errors.Is(err, InvalidPricingRule) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.Any("rule", req))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to create pricing rule", err, slog.Any("rule", req))
+		}
+		return
+	}
+
+	slog.Info("pricing rule created successfully", "rule_id", rule.ID, "name", rule.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *AnalyticsHandler) HandleGetPricingRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.s.GetPricingRules()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get pricing rules", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (h *AnalyticsHandler) HandleDeletePricingRule(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	ruleID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid pricing rule ID", err, slog.String("rule_id_str", idStr))
+		return
+	}
+
+	if err := h.s.DeletePricingRule(ruleID); err != nil {
+		if errors.Is(err, PricingRuleNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Pricing rule not found", err, slog.Int("rule_id", ruleID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete pricing rule", err, slog.Int("rule_id", ruleID))
+		}
+		return
+	}
+
+	slog.Info("pricing rule deleted successfully", "rule_id", ruleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleSetPriceBounds(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	var req PriceBoundsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	err = h.s.SetPriceBounds(PriceBounds{TrackID: trackID, Floor: req.Floor, Ceiling: req.Ceiling})
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.Float64("floor", req.Floor), slog.Float64("ceiling", req.Ceiling))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidPriceBounds) {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid price bounds", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to set price bounds", err, details)
+		}
+		return
+	}
+
+	slog.Info("price bounds updated successfully", "track_id", trackID, "floor", req.Floor, "ceiling", req.Ceiling)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AnalyticsHandler) HandlePreviewPricing(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.s.PreviewPricing(time.Now())
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to preview pricing", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func (h *AnalyticsHandler) HandleGetPriceChanges(w http.ResponseWriter, r *http.Request) {
+	changes, err := h.s.GetPriceChanges()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get price changes", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(changes)
+}
+
+func (h *AnalyticsHandler) HandleSchedulePrice(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	var req SchedulePriceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	sp, err := h.s.SchedulePrice(trackID, req.Price, req.StartsAt, req.EndsAt)
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.Float64("price", req.Price), slog.Time("starts_at", req.StartsAt))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, PriceMustBeGreater) {
+			respondWithError(w, r, http.StatusBadRequest, "Price must be greater than 0", err, details)
+		} else if errors.Is(err, InvalidSchedule) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to schedule price", err, details)
+		}
+		return
+	}
+
+	slog.Info("price scheduled successfully", "schedule_id", sp.ID, "track_id", trackID, "price", req.Price)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(sp)
+}
+
+func (h *AnalyticsHandler) HandleGetScheduledPrices(w http.ResponseWriter, r *http.Request) {
+	prices, err := h.s.GetScheduledPrices(r.URL.Query().Get("status"))
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get scheduled prices", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(prices)
+}
+
+func (h *AnalyticsHandler) HandleCancelScheduledPrice(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	scheduleID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid scheduled price ID", err, slog.String("schedule_id_str", idStr))
+		return
+	}
+
+	if err := h.s.CancelScheduledPrice(scheduleID); err != nil {
+		if errors.Is(err, ScheduledPriceNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Scheduled price not found", err, slog.Int("schedule_id", scheduleID))
+		} else if errors.Is(err, ScheduledPriceNotCancellable) {
+			respondWithError(w, r, http.StatusConflict, "Scheduled price is already finished", err, slog.Int("schedule_id", scheduleID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to cancel scheduled price", err, slog.Int("schedule_id", scheduleID))
+		}
+		return
+	}
+
+	slog.Info("scheduled price cancelled successfully", "schedule_id", scheduleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleCreateTimePriceRule(w http.ResponseWriter, r *http.Request) {
+	var req TimePriceRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	rule, err := h.s.CreateTimePriceRule(req)
+	if err != nil {
+		if errors.Is(err, InvalidTimePriceRule) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.Any("rule", req))
+		} else if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.Any("rule", req))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to create time price rule", err, slog.Any("rule", req))
+		}
+		return
+	}
+
+	slog.Info("time price rule created successfully", "rule_id", rule.ID, "name", rule.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *AnalyticsHandler) HandleGetTimePriceRules(w http.ResponseWriter, r *http.Request) {
+	rules, err := h.s.GetTimePriceRules()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get time price rules", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rules)
+}
+
+func (h *AnalyticsHandler) HandleDeleteTimePriceRule(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	ruleID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time price rule ID", err, slog.String("rule_id_str", idStr))
+		return
+	}
+
+	if err := h.s.DeleteTimePriceRule(ruleID); err != nil {
+		if errors.Is(err, TimePriceRuleNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Time price rule not found", err, slog.Int("rule_id", ruleID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete time price rule", err, slog.Int("rule_id", ruleID))
+		}
+		return
+	}
+
+	slog.Info("time price rule deleted successfully", "rule_id", ruleID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleGetEffectivePrice(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	at := time.Now()
+	if atStr := r.URL.Query().Get("at"); atStr != "" {
+		at, err = time.Parse(time.RFC3339, atStr)
+		if err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid time, expected RFC 3339", err, slog.String("at", atStr))
+			return
+		}
+	}
+
+	price, err := h.s.GetEffectivePrice(trackID, at, r.URL.Query().Get("device_id"))
+	if err != nil {
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.Int("track_id", trackID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get effective price", err, slog.Int("track_id", trackID))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(price)
+}
+
+func (h *AnalyticsHandler) HandleGetPaymentPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, err := h.s.GetPaymentPolicy()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get payment policy", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PaymentPolicyRequest{Policy: policy})
+}
+
+func (h *AnalyticsHandler) HandleSetPaymentPolicy(w http.ResponseWriter, r *http.Request) {
+	var req PaymentPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	if err := h.s.SetPaymentPolicy(req.Policy); err != nil {
+		if errors.Is(err, InvalidPaymentPolicy) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("policy", req.Policy))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to set payment policy", err, slog.String("policy", req.Policy))
+		}
+		return
+	}
+
+	slog.Info("payment policy updated successfully", "policy", req.Policy)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AnalyticsHandler) HandleGetReconciliationReport(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseTimeRange(r, 7*24*time.Hour)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	report, err := h.s.GetReconciliationReport(from, to)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get reconciliation report", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *AnalyticsHandler) HandleVoidLog(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	logID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid log ID", err, slog.String("log_id_str", idStr))
+		return
+	}
+
+	var req VoidLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	log, err := h.s.VoidLog(logID, req.Reason, req.RefundedAmount)
+	if err != nil {
+		details := slog.Group("details", slog.Int("log_id", logID), slog.String("reason", req.Reason))
+		if errors.Is(err, PlaybackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Playback log not found", err, details)
+		} else if errors.Is(err, PlaybackAlreadyVoided) {
+			respondWithError(w, r, http.StatusConflict, "Playback log is already voided", err, details)
+		} else if errors.Is(err, InvalidRefund) {
+			respondWithError(w, r, http.StatusBadRequest, "Refunded amount must be between 0 and the amount paid", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to void playback", err, details)
+		}
+		return
+	}
+
+	slog.Info("playback voided successfully", "log_id", logID, "refunded_amount", log.RefundedAmount, "reason", req.Reason)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(VoidLogResponse{
+		LogID:          log.ID,
+		TrackID:        log.TrackID,
+		AmountPaid:     log.AmountPaid,
+		RefundedAmount: log.RefundedAmount,
+		Reason:         log.VoidReason,
+		VoidedAt:       *log.VoidedAt,
+	})
+}
+
+func (h *AnalyticsHandler) HandleEnqueueTrack(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("device")
+
+	var req EnqueueTrackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	item, err := h.s.EnqueueTrack(deviceID, req.SessionID, req.TrackID, req.AmountPaid)
+	if err != nil {
+		details := slog.Group("details", slog.String("device_id", deviceID), slog.Int("track_id", req.TrackID))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, AmountBelowPrice) {
+			respondWithError(w, r, http.StatusBadRequest, "Amount paid is below the current price", err, details)
+		} else if errors.Is(err, NegativeAmount) {
+			respondWithError(w, r, http.StatusBadRequest, "Amount paid must not be negative", err, details)
+		} else if errors.Is(err, MissingDeviceID) {
+			respondWithError(w, r, http.StatusBadRequest, "Device ID is required", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to enqueue track", err, details)
+		}
+		return
+	}
+
+	slog.Info("track enqueued successfully", "queue_item_id", item.ID, "device_id", deviceID, "track_id", req.TrackID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *AnalyticsHandler) HandleGetQueue(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("device")
+
+	queue, err := h.s.GetQueue(deviceID)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get queue", err, slog.String("device_id", deviceID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(queue)
+}
+
+func (h *AnalyticsHandler) HandleStartQueueItem(w http.ResponseWriter, r *http.Request) {
+	h.handleQueueTransition(w, r, "start", h.s.StartQueueItem)
+}
+
+func (h *AnalyticsHandler) HandleFinishQueueItem(w http.ResponseWriter, r *http.Request) {
+	h.handleQueueTransition(w, r, "finish", h.s.FinishQueueItem)
+}
+
+func (h *AnalyticsHandler) HandleSkipQueueItem(w http.ResponseWriter, r *http.Request) {
+	h.handleQueueTransition(w, r, "skip", h.s.SkipQueueItem)
+}
+
+func (h *AnalyticsHandler) handleQueueTransition(w http.ResponseWriter, r *http.Request, action string, transition func(int) (*QueueItem, error)) {
+	idStr := r.PathValue("id")
+	itemID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid queue item ID", err, slog.String("queue_item_id_str", idStr))
+		return
+	}
+
+	item, err := transition(itemID)
+	if err != nil {
+		details := slog.Group("details", slog.Int("queue_item_id", itemID), slog.String("action", action))
+		if errors.Is(err, QueueItemNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Queue item not found", err, details)
+		} else if errors.Is(err, InvalidQueueTransition) {
+			respondWithError(w, r, http.StatusConflict, "Invalid queue transition", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to update queue item", err, details)
+		}
+		return
+	}
+
+	slog.Info("queue item updated successfully", "queue_item_id", item.ID, "status", item.Status, "log_id", item.LogID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+func (h *AnalyticsHandler) HandleCompleteLog(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	logID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid log ID", err, slog.String("log_id_str", idStr))
+		return
+	}
+
+	var req CompleteLogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	log, err := h.s.CompleteLog(logID, req.ListenedSeconds, req.Skipped)
+	if err != nil {
+		details := slog.Group("details", slog.Int("log_id", logID), slog.Int("listened_seconds", req.ListenedSeconds))
+		if errors.Is(err, PlaybackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Playback log not found", err, details)
+		} else if errors.Is(err, PlaybackAlreadyCompleted) {
+			respondWithError(w, r, http.StatusConflict, "Playback log is already completed", err, details)
+		} else if errors.Is(err, InvalidDuration) {
+			respondWithError(w, r, http.StatusBadRequest, "Listened seconds must not be negative", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to complete playback", err, details)
+		}
+		return
+	}
+
+	slog.Info("playback completed successfully", "log_id", logID, "listened_seconds", log.ListenedSeconds, "skipped", log.Skipped)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(log)
+}
+
+func (h *AnalyticsHandler) HandleSetTrackDuration(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	var req TrackDurationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	if err := h.s.SetTrackDuration(trackID, req.DurationSeconds); err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.Int("duration_seconds", req.DurationSeconds))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidDuration) {
+			respondWithError(w, r, http.StatusBadRequest, "Duration must not be negative", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to set track duration", err, details)
+		}
+		return
+	}
+
+	slog.Info("track duration updated successfully", "track_id", trackID, "duration_seconds", req.DurationSeconds)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleUpdateTrackMetadata(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	var req TrackMetadata
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	track, err := h.s.UpdateTrackMetadata(trackID, req)
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidISRC) {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid ISRC", err, details)
+		} else if errors.Is(err, InvalidReleaseYear) {
+			respondWithError(w, r, http.StatusBadRequest, "Release year must not be negative or in the future", err, details)
+		} else if errors.Is(err, InvalidDuration) {
+			respondWithError(w, r, http.StatusBadRequest, "Duration must not be negative", err, details)
+		} else if errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid tag", err, details)
+		} else if errors.Is(err, ExternalIDInUse) {
+			respondWithError(w, r, http.StatusConflict, "ISRC is already assigned to another track", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to update track metadata", err, details)
+		}
+		return
+	}
+
+	slog.Info("track metadata updated successfully", "track_id", trackID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(track)
+}
+
+func (h *AnalyticsHandler) HandleLookupTrack(w http.ResponseWriter, r *http.Request) {
+	source := r.URL.Query().Get("source")
+	externalID := r.URL.Query().Get("id")
+
+	track, err := h.s.LookupTrack(source, externalID)
+	if err != nil {
+		details := slog.Group("details", slog.String("source", source), slog.String("external_id", externalID))
+		if errors.Is(err, InvalidExternalID) {
+			respondWithError(w, r, http.StatusBadRequest, "source and id query parameters are required", err, details)
+		} else if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to look up track", err, details)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(track)
+}
+
+func (h *AnalyticsHandler) HandleGetTrackStats(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+	timeZone := r.URL.Query().Get("tz")
+
+	stats, err := h.s.GetTrackStats(trackID, timeZone)
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.String("tz", timeZone))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidTimeZone) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get track stats", err, details)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *AnalyticsHandler) HandleGetDuplicateTracks(w http.ResponseWriter, r *http.Request) {
+	minScore := 0.0
+	if v := r.URL.Query().Get("min_score"); v != "" {
+		var err error
+		if minScore, err = strconv.ParseFloat(v, 64); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid min_score", err, slog.String("min_score", v))
+			return
+		}
+	}
+
+	duplicates, err := h.s.FindDuplicateTracks(minScore)
+	if err != nil {
+		if errors.Is(err, InvalidDuplicateScore) {
+			respondWithError(w, r, http.StatusBadRequest, "min_score must be greater than 0 and at most 1", err, slog.Float64("min_score", minScore))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to find duplicate tracks", err, slog.Float64("min_score", minScore))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(duplicates)
+}
+
+func (h *AnalyticsHandler) HandleMergeTracks(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	canonicalID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	var req MergeTracksRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	track, err := h.s.MergeTracks(canonicalID, req.DuplicateID)
+	if err != nil {
+		details := slog.Group("details", slog.Int("canonical_id", canonicalID), slog.Int("duplicate_id", req.DuplicateID))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidMerge) {
+			respondWithError(w, r, http.StatusBadRequest, "A track can only be merged into a different track that has not been merged", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to merge tracks", err, details)
+		}
+		return
+	}
+
+	slog.Info("tracks merged successfully", "canonical_id", track.ID, "duplicate_id", req.DuplicateID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(track)
+}
+
+// HandleImportCatalog imports a CSV or JSON catalog from the request body.
+// Invalid rows are reported with status 422 and nothing is written.
+func (h *AnalyticsHandler) HandleImportCatalog(w http.ResponseWriter, r *http.Request) {
+	format := catalogFormat(r.URL.Query().Get("format"), r.Header.Get("Content-Type"))
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "dry_run must be true or false", err, slog.String("dry_run", v))
+			return
+		}
+	}
+
+	entries, err := parseCatalog(http.MaxBytesReader(w, r.Body, maxCatalogUpload), format)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid catalog file", err, slog.String("format", format))
+		return
+	}
+
+	report, err := h.s.ImportCatalog(entries, dryRun)
+	if err != nil {
+		if errors.Is(err, InvalidCatalog) {
+			slog.Error("catalog has invalid rows", "error", err, "method", r.Method, "path", r.URL.Path, "invalid_rows", len(report.Errors))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(report)
+		} else if errors.Is(err, CatalogChanged) {
+			respondWithError(w, r, http.StatusConflict, "Catalog changed during the import, try again", err, slog.Int("entries", len(entries)))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to import catalog", err, slog.Int("entries", len(entries)))
+		}
+		return
+	}
+
+	slog.Info("catalog imported successfully", "dry_run", dryRun, "created", report.Created, "updated", report.Updated, "unchanged", report.Unchanged)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+func (h *AnalyticsHandler) HandleGetExternalIDs(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+
+	ids, err := h.s.GetExternalIDs(trackID)
+	if err != nil {
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.Int("track_id", trackID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get external ids", err, slog.Int("track_id", trackID))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(ids)
+}
+
+func (h *AnalyticsHandler) HandleSetExternalID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+	source := r.PathValue("source")
+
+	var req ExternalIDRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	id, err := h.s.SetExternalID(trackID, source, req.ExternalID)
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.String("source", source), slog.String("external_id", req.ExternalID))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidExternalID) {
+			respondWithError(w, r, http.StatusBadRequest, "External id is required and ISRCs are set through track metadata", err, details)
+		} else if errors.Is(err, ExternalIDInUse) {
+			respondWithError(w, r, http.StatusConflict, "External id is already assigned to another track", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to set external id", err, details)
+		}
+		return
+	}
+
+	slog.Info("external id set successfully", "track_id", trackID, "source", id.Source, "external_id", id.ExternalID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(id)
+}
+
+func (h *AnalyticsHandler) HandleDeleteExternalID(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+	source := r.PathValue("source")
+
+	if err := h.s.DeleteExternalID(trackID, source); err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.String("source", source))
+		if errors.Is(err, ExternalIDNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "External id not found", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete external id", err, details)
+		}
+		return
+	}
+
+	slog.Info("external id deleted successfully", "track_id", trackID, "source", source)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleGetSkipStats(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseTimeRange(r, skipStatsWindow)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	stats, err := h.s.GetSkipStats(from, to)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *AnalyticsHandler) HandleGetAlerts(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.URL.Query().Get("device")
+	status := r.URL.Query().Get("status")
+	if status != "" && status != "open" && status != "all" {
+		respondWithError(w, r, http.StatusBadRequest, "Status must be \"open\" or \"all\"", nil, slog.String("status", status))
+		return
+	}
+
+	alerts, err := h.s.GetAlerts(deviceID, status == "all")
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get alerts", err, slog.String("device_id", deviceID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alerts)
+}
+
+func (h *AnalyticsHandler) HandleResolveAlert(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	alertID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid alert ID", err, slog.String("alert_id_str", idStr))
+		return
+	}
+
+	var req ResolveAlertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	alert, err := h.s.ResolveAlert(alertID, req.Release)
+	if err != nil {
+		if errors.Is(err, AlertNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Alert not found", err, slog.Int("alert_id", alertID))
+		} else if errors.Is(err, AlertAlreadyResolved) {
+			respondWithError(w, r, http.StatusConflict, "Alert is already resolved", err, slog.Int("alert_id", alertID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to resolve alert", err, slog.Int("alert_id", alertID))
+		}
+		return
+	}
+
+	slog.Info("alert resolved successfully", "alert_id", alertID, "release", req.Release)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(alert)
+}
+
+func (h *AnalyticsHandler) HandleGetAnomalyQuarantine(w http.ResponseWriter, r *http.Request) {
+	enabled, err := h.s.GetAnomalyQuarantine()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get anomaly quarantine setting", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(AnomalyQuarantineRequest{Enabled: enabled})
+}
+
+func (h *AnalyticsHandler) HandleSetAnomalyQuarantine(w http.ResponseWriter, r *http.Request) {
+	var req AnomalyQuarantineRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	if err := h.s.SetAnomalyQuarantine(req.Enabled); err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to set anomaly quarantine setting", err, slog.Bool("enabled", req.Enabled))
+		return
+	}
+
+	slog.Info("anomaly quarantine updated successfully", "enabled", req.Enabled)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AnalyticsHandler) HandleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	var req HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	device, err := h.s.Heartbeat(deviceID, req.FirmwareVersion, req.VenueID)
+	if err != nil {
+		details := slog.Group("details", slog.String("device_id", deviceID), slog.String("venue_id", req.VenueID))
+		if errors.Is(err, VenueNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Venue not found", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to record heartbeat", err, details)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+func (h *AnalyticsHandler) HandleGetDevices(w http.ResponseWriter, r *http.Request) {
+	devices, err := h.s.GetDevices()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get devices", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(devices)
+}
+
+func (h *AnalyticsHandler) HandleGetDevice(w http.ResponseWriter, r *http.Request) {
+	deviceID := r.PathValue("id")
+
+	device, err := h.s.GetDevice(deviceID)
+	if err != nil {
+		respondWithError(w, r, http.StatusNotFound, "Device not found", err, slog.String("device_id", deviceID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(device)
+}
+
+func (h *AnalyticsHandler) HandleSaveVenue(w http.ResponseWriter, r *http.Request) {
+	var venue Venue
+	if err := json.NewDecoder(r.Body).Decode(&venue); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+	venue.ID = r.PathValue("id")
+
+	saved, err := h.s.SaveVenue(venue)
+	if err != nil {
+		if errors.Is(err, InvalidVenue) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("venue_id", venue.ID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to save venue", err, slog.String("venue_id", venue.ID))
+		}
+		return
+	}
+
+	slog.Info("venue saved successfully", "venue_id", saved.ID, "time_zone", saved.TimeZone)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(saved)
+}
+
+func (h *AnalyticsHandler) HandleGetVenues(w http.ResponseWriter, r *http.Request) {
+	venues, err := h.s.GetVenues()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get venues", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(venues)
+}
+
+// parsePagination reads the optional limit and offset query parameters.
+func parsePagination(r *http.Request) (limit, offset int, err error) {
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			return 0, 0, err
+		}
+	}
+	if value := r.URL.Query().Get("offset"); value != "" {
+		if offset, err = strconv.Atoi(value); err != nil {
+			return 0, 0, err
+		}
+	}
+	return limit, offset, nil
+}
+
+func (h *AnalyticsHandler) HandleSearchTracks(w http.ResponseWriter, r *http.Request) {
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid pagination", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	result, err := h.s.SearchTracks(r.URL.Query().Get("q"), limit, offset)
+	if err != nil {
+		if errors.Is(err, InvalidPagination) {
+			respondWithError(w, r, http.StatusBadRequest, "Limit must be between 1 and 100 and offset must not be negative", err, slog.String("query", r.URL.RawQuery))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to search tracks", err, slog.String("query", r.URL.RawQuery))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func (h *AnalyticsHandler) HandleGetArtists(w http.ResponseWriter, r *http.Request) {
+	artists, err := h.s.GetArtists()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get artists", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(artists)
+}
+
+func (h *AnalyticsHandler) HandleGetTopArtists(w http.ResponseWriter, r *http.Request) {
+	from, to, err := parseAllTimeRange(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		if limit, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid limit", err, slog.String("limit", value))
+			return
+		}
+	}
+
+	stats, err := h.s.GetTopArtists(r.URL.Query().Get("sort"), limit, from, to)
+	if err != nil {
+		if errors.Is(err, InvalidSortOrder) || errors.Is(err, InvalidPagination) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.String("query", r.URL.RawQuery))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *AnalyticsHandler) HandleGetArtistBreakdown(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	artistID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid artist ID", err, slog.String("artist_id_str", idStr))
+		return
+	}
+	from, to, err := parseAllTimeRange(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	breakdown, err := h.s.GetArtistBreakdown(artistID, from, to)
+	if err != nil {
+		if errors.Is(err, ArtistNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Artist not found", err, slog.Int("artist_id", artistID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get stats", err, slog.Int("artist_id", artistID))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(breakdown)
+}
+
+// parseHeatmapFilter reads the venue, track_id, artist_id, tag, from and to
+// query parameters, responding with 400 when one is invalid.
+func parseHeatmapFilter(w http.ResponseWriter, r *http.Request) (HeatmapFilter, bool) {
+	from, to, err := parseAllTimeRange(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return HeatmapFilter{}, false
+	}
+	filter := HeatmapFilter{VenueID: r.URL.Query().Get("venue"), Tag: r.URL.Query().Get("tag"), From: from, To: to}
+	if value := r.URL.Query().Get("track_id"); value != "" {
+		if filter.TrackID, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id", value))
+			return filter, false
+		}
+	}
+	if value := r.URL.Query().Get("artist_id"); value != "" {
+		if filter.ArtistID, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid artist ID", err, slog.String("artist_id", value))
+			return filter, false
+		}
+	}
+	return filter, true
+}
+
+// respondWithHeatmapError maps the errors of heatmap style reports.
+func respondWithHeatmapError(w http.ResponseWriter, r *http.Request, err error, msg string) {
+	switch {
+	case errors.Is(err, InvalidTimeZone), errors.Is(err, InvalidWeekdayOrHour):
+		respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+	case errors.Is(err, VenueNotFoundError):
+		respondWithError(w, r, http.StatusNotFound, "Venue not found", err, slog.String("query", r.URL.RawQuery))
+	case errors.Is(err, TrackNotFoundError):
+		respondWithError(w, r, http.StatusNotFound, "Track not found", err, slog.String("query", r.URL.RawQuery))
+	case errors.Is(err, ArtistNotFoundError):
+		respondWithError(w, r, http.StatusNotFound, "Artist not found", err, slog.String("query", r.URL.RawQuery))
+	case errors.Is(err, TagNotFoundError), errors.Is(err, InvalidTag):
+		respondWithError(w, r, http.StatusNotFound, "Tag not found", err, slog.String("query", r.URL.RawQuery))
+	default:
+		respondWithError(w, r, http.StatusInternalServerError, msg, err, slog.String("query", r.URL.RawQuery))
+	}
+}
+
+func (h *AnalyticsHandler) HandleGetHeatmap(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseHeatmapFilter(w, r)
+	if !ok {
+		return
+	}
+
+	heatmap, err := h.s.GetHeatmap(filter, r.URL.Query().Get("tz"))
+	if err != nil {
+		respondWithHeatmapError(w, r, err, "Failed to get heatmap")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(heatmap)
+}
+
+// HandleGetTagStats serves /api/v1/stats/tags, which takes the heatmap's
+// filters plus an optional local weekday (0 = Sunday) and hour.
+func (h *AnalyticsHandler) HandleGetTagStats(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseHeatmapFilter(w, r)
+	if !ok {
+		return
+	}
+	weekday, hour := -1, -1
+	var err error
+	if value := r.URL.Query().Get("weekday"); value != "" {
+		if weekday, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid weekday", err, slog.String("weekday", value))
+			return
+		}
+	}
+	if value := r.URL.Query().Get("hour"); value != "" {
+		if hour, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid hour", err, slog.String("hour", value))
+			return
+		}
+	}
+
+	stats, err := h.s.GetTagStats(filter, r.URL.Query().Get("tz"), weekday, hour)
+	if err != nil {
+		respondWithHeatmapError(w, r, err, "Failed to get tag stats")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *AnalyticsHandler) HandleGetTags(w http.ResponseWriter, r *http.Request) {
+	tags, err := h.s.GetTags()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get tags", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tags)
+}
+
+func (h *AnalyticsHandler) HandleCreateTag(w http.ResponseWriter, r *http.Request) {
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	tag, err := h.s.CreateTag(req.Name)
+	if err != nil {
+		if errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("name", req.Name))
+		} else if errors.Is(err, TagExists) {
+			respondWithError(w, r, http.StatusConflict, "A tag with this name already exists", err, slog.String("name", req.Name))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to create tag", err, slog.String("name", req.Name))
+		}
+		return
+	}
+
+	slog.Info("tag created successfully", "tag_id", tag.ID, "name", tag.Name)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *AnalyticsHandler) HandleRenameTag(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	tagID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid tag ID", err, slog.String("tag_id_str", idStr))
+		return
+	}
+
+	var req TagRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	tag, err := h.s.RenameTag(tagID, req.Name)
+	if err != nil {
+		details := slog.Group("details", slog.Int("tag_id", tagID), slog.String("name", req.Name))
+		if errors.Is(err, TagNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Tag not found", err, details)
+		} else if errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else if errors.Is(err, TagExists) {
+			respondWithError(w, r, http.StatusConflict, "A tag with this name already exists", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to rename tag", err, details)
+		}
+		return
+	}
+
+	slog.Info("tag renamed successfully", "tag_id", tag.ID, "name", tag.Name)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tag)
+}
+
+func (h *AnalyticsHandler) HandleDeleteTag(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	tagID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid tag ID", err, slog.String("tag_id_str", idStr))
+		return
+	}
+
+	if err := h.s.DeleteTag(tagID); err != nil {
+		if errors.Is(err, TagNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Tag not found", err, slog.Int("tag_id", tagID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete tag", err, slog.Int("tag_id", tagID))
+		}
+		return
+	}
+
+	slog.Info("tag deleted successfully", "tag_id", tagID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleTagTrack(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+	name := r.PathValue("tag")
+
+	track, err := h.s.TagTrack(trackID, name)
+	if err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.String("tag", name))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to tag track", err, details)
+		}
+		return
+	}
+
+	slog.Info("track tagged successfully", "track_id", track.ID, "tag", name)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(track)
+}
+
+func (h *AnalyticsHandler) HandleUntagTrack(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	trackID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid track ID", err, slog.String("track_id_str", idStr))
+		return
+	}
+	name := r.PathValue("tag")
+
+	if err := h.s.UntagTrack(trackID, name); err != nil {
+		details := slog.Group("details", slog.Int("track_id", trackID), slog.String("tag", name))
+		if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, TagNotFoundError) || errors.Is(err, InvalidTag) {
+			respondWithError(w, r, http.StatusNotFound, "Track does not have this tag", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to untag track", err, details)
+		}
+		return
+	}
+
+	slog.Info("track untagged successfully", "track_id", trackID, "tag", name)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *AnalyticsHandler) HandleGetCharts(w http.ResponseWriter, r *http.Request) {
+	charts, err := h.s.GetCharts()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get charts", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(charts)
+}
+
+// HandleGetChart serves /api/v1/charts/{id}, where id may be "latest".
+func (h *AnalyticsHandler) HandleGetChart(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	chartID := 0
+	if idStr != "latest" {
+		var err error
+		if chartID, err = strconv.Atoi(idStr); err != nil || chartID < 1 {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid chart ID", err, slog.String("chart_id_str", idStr))
+			return
+		}
+	}
+
+	chart, err := h.s.GetChart(chartID)
+	if err != nil {
+		if errors.Is(err, ChartNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Chart not found", err, slog.String("chart_id", idStr))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get chart", err, slog.String("chart_id", idStr))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(chart)
+}
+
+func (h *AnalyticsHandler) HandleGetCustomer(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("id")
+	customer, err := h.s.GetCustomer(customerID)
+	if err != nil {
+		if errors.Is(err, CustomerNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Customer not found", err, slog.String("customer_id", customerID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get customer", err, slog.String("customer_id", customerID))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (h *AnalyticsHandler) HandleTopUpCredit(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("id")
+	var req TopUpRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	customer, err := h.s.TopUpCredit(customerID, req.Amount)
+	if err != nil {
+		if errors.Is(err, InvalidTopUp) {
+			respondWithError(w, r, http.StatusBadRequest, "Top-up amount must be positive", err, slog.Float64("amount", req.Amount))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to top up credit", err, slog.String("customer_id", customerID))
+		}
+		return
+	}
+
+	slog.Info("credit topped up successfully", "customer_id", customerID, "amount", req.Amount, "balance", customer.Balance)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(customer)
+}
+
+func (h *AnalyticsHandler) HandleGetCustomerLogs(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("id")
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid pagination", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	logs, err := h.s.GetCustomerLogs(customerID, limit, offset)
+	if err != nil {
+		respondWithCustomerError(w, r, customerID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(logs)
+}
+
+func (h *AnalyticsHandler) HandleGetWalletTransactions(w http.ResponseWriter, r *http.Request) {
+	customerID := r.PathValue("id")
+	limit, offset, err := parsePagination(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid pagination", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	transactions, err := h.s.GetWalletTransactions(customerID, limit, offset)
+	if err != nil {
+		respondWithCustomerError(w, r, customerID, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(transactions)
+}
+
+func respondWithCustomerError(w http.ResponseWriter, r *http.Request, customerID string, err error) {
+	if errors.Is(err, CustomerNotFoundError) {
+		respondWithError(w, r, http.StatusNotFound, "Customer not found", err, slog.String("customer_id", customerID))
+	} else if errors.Is(err, InvalidPagination) {
+		respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+	} else {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get customer history", err, slog.String("customer_id", customerID))
+	}
+}
+
+func (h *AnalyticsHandler) HandleGetLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	rule, err := h.s.GetLoyaltyRule()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get loyalty rule", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rule)
+}
+
+func (h *AnalyticsHandler) HandleSetLoyaltyRule(w http.ResponseWriter, r *http.Request) {
+	var req LoyaltyRule
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	if err := h.s.SetLoyaltyRule(req); err != nil {
+		if errors.Is(err, InvalidLoyaltyRule) {
+			respondWithError(w, r, http.StatusBadRequest, "free_every must not be negative", err, slog.Int("free_every", req.FreeEvery))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to set loyalty rule", err, slog.Int("free_every", req.FreeEvery))
+		}
+		return
+	}
+
+	slog.Info("loyalty rule updated successfully", "free_every", req.FreeEvery)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (h *AnalyticsHandler) HandleSaveRoyaltyRate(w http.ResponseWriter, r *http.Request) {
+	var req RoyaltyRate
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	rate, err := h.s.SaveRoyaltyRate(req)
+	if err != nil {
+		details := slog.Group("details", slog.Int("artist_id", req.ArtistID), slog.Int("track_id", req.TrackID), slog.Float64("rate", req.Rate))
+		if errors.Is(err, InvalidRoyaltyRate) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else if errors.Is(err, TrackNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Track not found", err, details)
+		} else if errors.Is(err, ArtistNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Artist not found", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to save royalty rate", err, details)
+		}
+		return
+	}
+
+	slog.Info("royalty rate saved successfully", "rate_id", rate.ID, "artist_id", rate.ArtistID, "track_id", rate.TrackID, "rate", rate.Rate)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rate)
+}
+
+func (h *AnalyticsHandler) HandleGetRoyaltyRates(w http.ResponseWriter, r *http.Request) {
+	rates, err := h.s.GetRoyaltyRates()
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get royalty rates", err, slog.String("details", "no details"))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(rates)
+}
+
+func (h *AnalyticsHandler) HandleDeleteRoyaltyRate(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	rateID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid royalty rate ID", err, slog.String("rate_id_str", idStr))
+		return
+	}
+
+	if err := h.s.DeleteRoyaltyRate(rateID); err != nil {
+		if errors.Is(err, RoyaltyRateNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Royalty rate not found", err, slog.Int("rate_id", rateID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to delete royalty rate", err, slog.Int("rate_id", rateID))
+		}
+		return
+	}
+
+	slog.Info("royalty rate deleted successfully", "rate_id", rateID)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// respondWithStatement writes a statement as JSON, or as CSV with ?format=csv.
+func respondWithStatement(w http.ResponseWriter, r *http.Request, statement *RoyaltyStatement) {
+	if r.URL.Query().Get("format") != "csv" {
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(statement)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	if statement.ID != 0 {
+		w.Header().Set("Content-Disposition", "attachment; filename=royalty-statement-"+strconv.Itoa(statement.ID)+".csv")
+	}
+	if err := writeStatementCSV(w, statement); err != nil {
+		slog.Error("failed to write royalty statement", "error", err, "statement_id", statement.ID)
+	}
+}
+
+func (h *AnalyticsHandler) HandlePreviewRoyaltyStatement(w http.ResponseWriter, r *http.Request) {
+	value := r.URL.Query().Get("artist_id")
+	artistID, err := strconv.Atoi(value)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid artist ID", err, slog.String("artist_id", value))
+		return
+	}
+	from, to, err := parseAllTimeRange(r)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid time range", err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	statement, err := h.s.PreviewRoyaltyStatement(artistID, from, to)
+	if err != nil {
+		if errors.Is(err, InvalidStatementPeriod) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+		} else if errors.Is(err, ArtistNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Artist not found", err, slog.Int("artist_id", artistID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to preview royalty statement", err, slog.Int("artist_id", artistID))
+		}
+		return
+	}
+
+	respondWithStatement(w, r, statement)
+}
+
+func (h *AnalyticsHandler) HandleCloseRoyaltyStatement(w http.ResponseWriter, r *http.Request) {
+	var req CloseStatementRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid request body", err, slog.Any("request_body", r.Body))
+		return
+	}
+
+	statement, err := h.s.CloseRoyaltyStatement(req.ArtistID, req.PeriodStart, req.PeriodEnd)
+	if err != nil {
+		details := slog.Group("details", slog.Int("artist_id", req.ArtistID), slog.Time("period_start", req.PeriodStart), slog.Time("period_end", req.PeriodEnd))
+		if errors.Is(err, InvalidStatementPeriod) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, details)
+		} else if errors.Is(err, ArtistNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Artist not found", err, details)
+		} else if errors.Is(err, StatementOverlap) {
+			respondWithError(w, r, http.StatusConflict, "A closed statement already covers part of this period", err, details)
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to close royalty statement", err, details)
+		}
+		return
+	}
+
+	slog.Info("royalty statement closed successfully", "statement_id", statement.ID, "artist_id", statement.ArtistID, "owed", statement.Owed)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(statement)
+}
+
+func (h *AnalyticsHandler) HandleGetRoyaltyStatements(w http.ResponseWriter, r *http.Request) {
+	artistID := 0
+	if value := r.URL.Query().Get("artist_id"); value != "" {
+		var err error
+		if artistID, err = strconv.Atoi(value); err != nil {
+			respondWithError(w, r, http.StatusBadRequest, "Invalid artist ID", err, slog.String("artist_id", value))
+			return
+		}
+	}
+
+	statements, err := h.s.GetRoyaltyStatements(artistID)
+	if err != nil {
+		respondWithError(w, r, http.StatusInternalServerError, "Failed to get royalty statements", err, slog.Int("artist_id", artistID))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(statements)
+}
+
+func (h *AnalyticsHandler) HandleGetRoyaltyStatement(w http.ResponseWriter, r *http.Request) {
+	idStr := r.PathValue("id")
+	statementID, err := strconv.Atoi(idStr)
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, "Invalid statement ID", err, slog.String("statement_id_str", idStr))
+		return
+	}
+
+	statement, err := h.s.GetRoyaltyStatement(statementID)
+	if err != nil {
+		if errors.Is(err, StatementNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Royalty statement not found", err, slog.Int("statement_id", statementID))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get royalty statement", err, slog.Int("statement_id", statementID))
+		}
+		return
+	}
+
+	respondWithStatement(w, r, statement)
+}
+
+// HandleGetUsageReport serves a venue's performance-rights usage file.
+func (h *AnalyticsHandler) HandleGetUsageReport(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	format, err := parseUsageFormat(query.Get("columns"), query.Get("delimiter"))
+	if err != nil {
+		respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+		return
+	}
+
+	report, err := h.s.GetUsageReport(query.Get("venue"), query.Get("from"), query.Get("to"))
+	if err != nil {
+		if errors.Is(err, MissingVenueID) || errors.Is(err, InvalidUsagePeriod) {
+			respondWithError(w, r, http.StatusBadRequest, err.Error(), err, slog.String("query", r.URL.RawQuery))
+		} else if errors.Is(err, VenueNotFoundError) {
+			respondWithError(w, r, http.StatusNotFound, "Venue not found", err, slog.String("venue_id", query.Get("venue")))
+		} else if errors.Is(err, UsagePeriodPurged) {
+			respondWithError(w, r, http.StatusUnprocessableEntity, err.Error(), err, slog.String("query", r.URL.RawQuery))
+		} else {
+			respondWithError(w, r, http.StatusInternalServerError, "Failed to get usage report", err, slog.String("query", r.URL.RawQuery))
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", "attachment; filename="+usageFileName(report))
+	if err := writeUsageCSV(w, report, format); err != nil {
+		slog.Error("failed to write usage report", "error", err, "venue_id", report.VenueID)
+	}
+}
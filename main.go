@@ -1,10 +1,12 @@
 package main
 
 import (
+	"context"
 	"log/slog"
 	"net"
 	"net/http"
 	"os"
+	"time"
 
 	pb "jukebox-analytic/proto"
 
@@ -15,12 +17,39 @@ const (
 	httpPort = ":8080"
 	grpcPort = ":50051"
 	dbPath   = "./jukebox.db"
+
+	archiveDir            = "./archive"
+	retentionInterval     = 24 * time.Hour
+	webhookInterval       = 5 * time.Second
+	cooccurrenceInterval  = time.Minute
+	pricingInterval       = time.Hour
+	schedulerInterval     = time.Minute
+	anomalyInterval       = 10 * time.Second
+	deviceMonitorInterval = time.Minute
+	chartInterval         = time.Hour
 )
 
 func main() {
 	logger := slog.New(slog.NewJSONHandler(os.Stdout, nil))
 	slog.SetDefault(logger)
 
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "usage-report":
+			if err := runUsageReport(os.Args[2:]); err != nil {
+				slog.Error("usage report failed", "error", err)
+				os.Exit(1)
+			}
+			return
+		case "import-catalog":
+			if err := runImportCatalog(os.Args[2:], os.Stdout); err != nil {
+				slog.Error("catalog import failed", "error", err)
+				os.Exit(1)
+			}
+			return
+		}
+	}
+
 	repo, err := NewSQLiteRepository(dbPath)
 	if err != nil {
 		slog.Error("failed to initialize database", "error", err)
@@ -29,6 +58,20 @@ func main() {
 
 	service := NewService(repo)
 
+	archiver, err := NewFileArchiver(archiveDir)
+	if err != nil {
+		slog.Error("failed to initialize archive", "error", err)
+		os.Exit(1)
+	}
+	go NewRetentionJob(repo, archiver, logRetention).Run(context.Background(), retentionInterval)
+	go NewWebhookDispatcher(repo).Run(context.Background(), webhookInterval)
+	go NewCooccurrenceJob(repo).Run(context.Background(), cooccurrenceInterval)
+	go NewPricingJob(service).Run(context.Background(), pricingInterval)
+	go NewPriceScheduler(service).Run(context.Background(), schedulerInterval)
+	go NewAnomalyDetector(repo).Run(context.Background(), anomalyInterval)
+	go NewDeviceMonitor(repo).Run(context.Background(), deviceMonitorInterval)
+	go NewChartJob(repo, chartPeriod, chartSize).Run(context.Background(), chartInterval)
+
 	go func() {
 		lis, err := net.Listen("tcp", grpcPort)
 		if err != nil {
@@ -48,8 +91,86 @@ func main() {
 	handler := NewHandler(service)
 	mux := http.NewServeMux()
 	mux.HandleFunc("POST /api/v1/logs", handler.HandleLogPlayback)
+	mux.HandleFunc("POST /api/v1/logs/{id}/void", handler.HandleVoidLog)
+	mux.HandleFunc("POST /api/v1/logs/{id}/complete", handler.HandleCompleteLog)
+	mux.HandleFunc("POST /api/v1/jukeboxes/{device}/queue", handler.HandleEnqueueTrack)
+	mux.HandleFunc("GET /api/v1/jukeboxes/{device}/queue", handler.HandleGetQueue)
+	mux.HandleFunc("POST /api/v1/queue/{id}/start", handler.HandleStartQueueItem)
+	mux.HandleFunc("POST /api/v1/queue/{id}/finish", handler.HandleFinishQueueItem)
+	mux.HandleFunc("POST /api/v1/queue/{id}/skip", handler.HandleSkipQueueItem)
 	mux.HandleFunc("GET /api/v1/stats/top", handler.HandleGetTopTracks)
+	mux.HandleFunc("GET /api/v1/stats/trending", handler.HandleGetTrendingTracks)
+	mux.HandleFunc("GET /api/v1/stats/skips", handler.HandleGetSkipStats)
+	mux.HandleFunc("GET /api/v1/stats/artists", handler.HandleGetTopArtists)
+	mux.HandleFunc("GET /api/v1/stats/heatmap", handler.HandleGetHeatmap)
+	mux.HandleFunc("GET /api/v1/stats/tags", handler.HandleGetTagStats)
+	mux.HandleFunc("GET /api/v1/tags", handler.HandleGetTags)
+	mux.HandleFunc("POST /api/v1/tags", handler.HandleCreateTag)
+	mux.HandleFunc("PUT /api/v1/tags/{id}", handler.HandleRenameTag)
+	mux.HandleFunc("DELETE /api/v1/tags/{id}", handler.HandleDeleteTag)
+	mux.HandleFunc("GET /api/v1/charts", handler.HandleGetCharts)
+	mux.HandleFunc("GET /api/v1/charts/{id}", handler.HandleGetChart)
+	mux.HandleFunc("GET /api/v1/tracks", handler.HandleSearchTracks)
+	mux.HandleFunc("GET /api/v1/artists", handler.HandleGetArtists)
+	mux.HandleFunc("GET /api/v1/artists/{id}/tracks", handler.HandleGetArtistBreakdown)
 	mux.HandleFunc("PATCH /api/v1/tracks/{id}/price", handler.HandleUpdatePrice)
+	mux.HandleFunc("GET /api/v1/tracks/{id}/related", handler.HandleGetRelatedTracks)
+	mux.HandleFunc("GET /api/v1/tracks/{id}/stats", handler.HandleGetTrackStats)
+	mux.HandleFunc("GET /api/v1/tracks/{id}/price", handler.HandleGetEffectivePrice)
+	mux.HandleFunc("PUT /api/v1/tracks/{id}/duration", handler.HandleSetTrackDuration)
+	mux.HandleFunc("PATCH /api/v1/tracks/{id}/metadata", handler.HandleUpdateTrackMetadata)
+	mux.HandleFunc("GET /api/v1/tracks/lookup", handler.HandleLookupTrack)
+	mux.HandleFunc("GET /api/v1/tracks/{id}/external-ids", handler.HandleGetExternalIDs)
+	mux.HandleFunc("PUT /api/v1/tracks/{id}/external-ids/{source}", handler.HandleSetExternalID)
+	mux.HandleFunc("DELETE /api/v1/tracks/{id}/external-ids/{source}", handler.HandleDeleteExternalID)
+	mux.HandleFunc("POST /api/v1/admin/catalog/import", handler.HandleImportCatalog)
+	mux.HandleFunc("GET /api/v1/tracks/duplicates", handler.HandleGetDuplicateTracks)
+	mux.HandleFunc("POST /api/v1/tracks/{id}/merge", handler.HandleMergeTracks)
+	mux.HandleFunc("PUT /api/v1/tracks/{id}/tags/{tag}", handler.HandleTagTrack)
+	mux.HandleFunc("DELETE /api/v1/tracks/{id}/tags/{tag}", handler.HandleUntagTrack)
+	mux.HandleFunc("PUT /api/v1/tracks/{id}/price-bounds", handler.HandleSetPriceBounds)
+	mux.HandleFunc("POST /api/v1/tracks/{id}/scheduled-prices", handler.HandleSchedulePrice)
+	mux.HandleFunc("GET /api/v1/scheduled-prices", handler.HandleGetScheduledPrices)
+	mux.HandleFunc("DELETE /api/v1/scheduled-prices/{id}", handler.HandleCancelScheduledPrice)
+	mux.HandleFunc("POST /api/v1/devices/{id}/heartbeat", handler.HandleHeartbeat)
+	mux.HandleFunc("GET /api/v1/devices", handler.HandleGetDevices)
+	mux.HandleFunc("GET /api/v1/customers/{id}", handler.HandleGetCustomer)
+	mux.HandleFunc("POST /api/v1/customers/{id}/top-ups", handler.HandleTopUpCredit)
+	mux.HandleFunc("GET /api/v1/customers/{id}/plays", handler.HandleGetCustomerLogs)
+	mux.HandleFunc("GET /api/v1/customers/{id}/wallet", handler.HandleGetWalletTransactions)
+	mux.HandleFunc("GET /api/v1/devices/{id}", handler.HandleGetDevice)
+	mux.HandleFunc("PUT /api/v1/venues/{id}", handler.HandleSaveVenue)
+	mux.HandleFunc("GET /api/v1/venues", handler.HandleGetVenues)
+	mux.HandleFunc("GET /api/v1/alerts", handler.HandleGetAlerts)
+	mux.HandleFunc("POST /api/v1/alerts/{id}/resolve", handler.HandleResolveAlert)
+	mux.HandleFunc("GET /api/v1/settings/anomaly-quarantine", handler.HandleGetAnomalyQuarantine)
+	mux.HandleFunc("PUT /api/v1/settings/anomaly-quarantine", handler.HandleSetAnomalyQuarantine)
+	mux.HandleFunc("GET /api/v1/settings/loyalty", handler.HandleGetLoyaltyRule)
+	mux.HandleFunc("PUT /api/v1/settings/loyalty", handler.HandleSetLoyaltyRule)
+	mux.HandleFunc("PUT /api/v1/royalties/rates", handler.HandleSaveRoyaltyRate)
+	mux.HandleFunc("GET /api/v1/royalties/rates", handler.HandleGetRoyaltyRates)
+	mux.HandleFunc("DELETE /api/v1/royalties/rates/{id}", handler.HandleDeleteRoyaltyRate)
+	mux.HandleFunc("GET /api/v1/royalties/preview", handler.HandlePreviewRoyaltyStatement)
+	mux.HandleFunc("POST /api/v1/royalties/statements", handler.HandleCloseRoyaltyStatement)
+	mux.HandleFunc("GET /api/v1/royalties/statements", handler.HandleGetRoyaltyStatements)
+	mux.HandleFunc("GET /api/v1/royalties/statements/{id}", handler.HandleGetRoyaltyStatement)
+	mux.HandleFunc("GET /api/v1/retention/report", handler.HandleGetRetentionReport)
+	mux.HandleFunc("GET /api/v1/reports/reconciliation", handler.HandleGetReconciliationReport)
+	mux.HandleFunc("GET /api/v1/reports/usage", handler.HandleGetUsageReport)
+	mux.HandleFunc("GET /api/v1/settings/payment-policy", handler.HandleGetPaymentPolicy)
+	mux.HandleFunc("PUT /api/v1/settings/payment-policy", handler.HandleSetPaymentPolicy)
+	mux.HandleFunc("POST /api/v1/webhooks", handler.HandleCreateWebhook)
+	mux.HandleFunc("GET /api/v1/webhooks", handler.HandleGetWebhooks)
+	mux.HandleFunc("DELETE /api/v1/webhooks/{id}", handler.HandleDeleteWebhook)
+	mux.HandleFunc("GET /api/v1/webhooks/{id}/deliveries", handler.HandleGetWebhookDeliveries)
+	mux.HandleFunc("POST /api/v1/pricing/rules", handler.HandleCreatePricingRule)
+	mux.HandleFunc("GET /api/v1/pricing/rules", handler.HandleGetPricingRules)
+	mux.HandleFunc("DELETE /api/v1/pricing/rules/{id}", handler.HandleDeletePricingRule)
+	mux.HandleFunc("GET /api/v1/pricing/preview", handler.HandlePreviewPricing)
+	mux.HandleFunc("GET /api/v1/pricing/changes", handler.HandleGetPriceChanges)
+	mux.HandleFunc("POST /api/v1/pricing/time-rules", handler.HandleCreateTimePriceRule)
+	mux.HandleFunc("GET /api/v1/pricing/time-rules", handler.HandleGetTimePriceRules)
+	mux.HandleFunc("DELETE /api/v1/pricing/time-rules/{id}", handler.HandleDeleteTimePriceRule)
 
 	slog.Info("HTTP server starting", "address", httpPort)
 	if err := http.ListenAndServe(httpPort, mux); err != nil {
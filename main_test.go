@@ -2,15 +2,26 @@ package main
 
 import (
 	"bytes"
+	"compress/gzip"
+	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 type mockRepository struct {
+	IRepository
+
 	tracks                 map[int]*Track
 	logs                   []PlaybackLog
 	createLogCalled        bool
@@ -42,10 +53,18 @@ func (m *mockRepository) UpdateTrackPrice(id int, newPrice float64) error {
 	return nil
 }
 
-func (m *mockRepository) CreateLog(log PlaybackLog) error {
+func (m *mockRepository) CreateLog(log PlaybackLog) (*PlaybackLog, error) {
 	m.createLogCalled = true
 	m.logs = append(m.logs, log)
-	return nil
+	return &log, nil
+}
+
+func (m *mockRepository) GetTimePriceRules() ([]TimePriceRule, error) {
+	return nil, nil
+}
+
+func (m *mockRepository) GetSetting(key string) (string, error) {
+	return "", nil
 }
 
 func (m *mockRepository) GetAllLogs() []PlaybackLog {
@@ -175,3 +194,2081 @@ func TestHandleGetTopTracks(t *testing.T) {
 		t.Errorf("Result mismatch.\nExpected: %+v\nGot:      %+v", expected, result)
 	}
 }
+
+func TestRetentionJob(t *testing.T) {
+	testRetentionJob(t, NewInMemoryRepository())
+}
+
+func testRetentionJob(t *testing.T, repo IRepository) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-100 * 24 * time.Hour)
+
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: old, AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: old.Add(time.Minute), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: old.Add(2 * time.Minute), AmountPaid: 1.25, DeviceID: "jb-9"})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now, AmountPaid: 1.50})
+	alert := Alert{DeviceID: "jb-9", Kind: AlertZeroAmount, WindowStart: old, WindowEnd: old.Add(time.Hour), Quarantined: true, CreatedAt: old}
+	if err := repo.SaveAnomalyBatch(nil, []Alert{alert}, 0); err != nil {
+		t.Fatalf("SaveAnomalyBatch failed: %v", err)
+	}
+
+	dir := t.TempDir()
+	archiver, err := NewFileArchiver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(now)
+	if err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if purged != 3 {
+		t.Errorf("Expected 3 purged logs, got %d", purged)
+	}
+	if logs := repo.GetAllLogs(); len(logs) != 1 {
+		t.Errorf("Expected 1 remaining log, got %d", len(logs))
+	}
+
+	archived := filepath.Join(dir, "playback_logs_"+old.Format("2006-01")+".v2.csv.gz")
+	f, err := os.Open(archived)
+	if err != nil {
+		t.Fatalf("Expected archive file %s: %v", archived, err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(zr).ReadAll()
+	if err != nil {
+		t.Fatalf("Expected every archived row to match the header: %v", err)
+	}
+	if len(records) != 4 || !reflect.DeepEqual(records[0], archiveColumns) || records[1][14] != "false" || records[3][14] != "true" {
+		t.Errorf("Expected a header and 3 rows with the quarantined flag, got %v", records)
+	}
+
+	stats, _ := repo.GetTopTracks(topTracks)
+	expected := []TopTrackStat{
+		{Title: "Dirty Diana", Count: 2},
+		{Title: "Comfortably Numb", Count: 1},
+	}
+	if !reflect.DeepEqual(stats, expected) {
+		t.Errorf("Rollups not preserved.\nExpected: %+v\nGot:      %+v", expected, stats)
+	}
+}
+
+// failingPurgeRepository fails every PurgeExpiredLogs after the logs were
+// archived, as a database error would.
+type failingPurgeRepository struct {
+	IRepository
+}
+
+func (f failingPurgeRepository) PurgeExpiredLogs(cutoff time.Time, maxID int) (int, error) {
+	return 0, errors.New("database is locked")
+}
+
+func TestRetentionRetryAfterFailedPurge(t *testing.T) {
+	repo := NewInMemoryRepository()
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-100 * 24 * time.Hour)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: old, AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: old.Add(time.Minute), AmountPaid: 1.50})
+
+	dir := t.TempDir()
+	archiver, err := NewFileArchiver(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRetentionJob(failingPurgeRepository{repo}, archiver, logRetention).RunOnce(now); err == nil {
+		t.Fatal("Expected the failed purge to fail the run")
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(now); err != nil || purged != 2 {
+		t.Fatalf("Expected the retry to purge 2 logs, got %d, %v", purged, err)
+	}
+
+	f, err := os.Open(filepath.Join(dir, "playback_logs_"+old.Format("2006-01")+".v2.csv.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	records, err := csv.NewReader(zr).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 3 || records[1][0] != "1" || records[2][0] != "2" {
+		t.Errorf("Expected a header and each log archived once, got %v", records)
+	}
+}
+
+func TestRollupAtRangeBoundary(t *testing.T) {
+	testRollupAtRangeBoundary(t, NewInMemoryRepository())
+}
+
+// testRollupAtRangeBoundary checks that a rolled up hour belongs to the range
+// that starts on it and not to the one that ends on it.
+func testRollupAtRangeBoundary(t *testing.T, repo IRepository) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day, AmountPaid: 1.25})
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(day.Add(2 * logRetention)); err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged log, got %d, %v", purged, err)
+	}
+
+	if ok, err := repo.HasRollups(day, day.Add(24*time.Hour)); err != nil || !ok {
+		t.Errorf("Expected a rollup in the day starting on it, got %v, %v", ok, err)
+	}
+	if ok, _ := repo.HasRollups(day.Add(-24*time.Hour), day); ok {
+		t.Error("Expected no rollup in the day ending on it")
+	}
+	totals, _ := repo.GetTrackTotals(day, day.Add(24*time.Hour))
+	if len(totals) != 1 || totals[0].Plays != 1 || totals[0].Revenue != 1.25 {
+		t.Errorf("Expected the rolled up play in the day's totals, got %+v", totals)
+	}
+	if totals, _ := repo.GetTrackTotals(day.Add(-24*time.Hour), day); len(totals) != 0 {
+		t.Errorf("Expected no totals in the previous day, got %+v", totals)
+	}
+	artists, _ := repo.GetArtistStats(day, day.Add(24*time.Hour))
+	if len(artists) != 1 || artists[0].Plays != 1 {
+		t.Errorf("Expected the rolled up play in the day's artist stats, got %+v", artists)
+	}
+}
+
+func TestQuarantinedRevenue(t *testing.T) {
+	testQuarantinedRevenue(t, NewInMemoryRepository())
+}
+
+// testQuarantinedRevenue checks that quarantined plays count toward neither
+// plays nor revenue, before and after their hour is rolled up.
+func testQuarantinedRevenue(t *testing.T, repo IRepository) {
+	day := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day, AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day.Add(time.Minute), AmountPaid: 1.25, DeviceID: "jb-9"})
+	alert := Alert{DeviceID: "jb-9", Kind: AlertZeroAmount, WindowStart: day, WindowEnd: day.Add(time.Hour), Quarantined: true, CreatedAt: day}
+	if err := repo.SaveAnomalyBatch(nil, []Alert{alert}, 0); err != nil {
+		t.Fatalf("SaveAnomalyBatch failed: %v", err)
+	}
+
+	check := func(when string) {
+		totals, _ := repo.GetTrackTotals(day, day.Add(24*time.Hour))
+		if len(totals) != 1 || totals[0].Plays != 1 || totals[0].Revenue != 1.25 {
+			t.Errorf("Expected only the clean play in the totals %s, got %+v", when, totals)
+		}
+	}
+	check("before the purge")
+	buckets, err := repo.GetPlayBuckets(HeatmapFilter{From: day, To: day.Add(24 * time.Hour)})
+	if err != nil || len(buckets) != 1 || buckets[0].Plays != 1 || buckets[0].Revenue != 1.25 {
+		t.Errorf("Expected only the clean play in the heatmap, got %+v, %v", buckets, err)
+	}
+
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(day.Add(2 * logRetention)); err != nil || purged != 2 {
+		t.Fatalf("Expected 2 purged logs, got %d, %v", purged, err)
+	}
+	check("after the purge")
+}
+
+func TestHandleGetRetentionReport(t *testing.T) {
+	testHandleGetRetentionReport(t, NewInMemoryRepository())
+}
+
+func testHandleGetRetentionReport(t *testing.T, repo IRepository) {
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: time.Now().Add(-100 * 24 * time.Hour), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: time.Now(), AmountPaid: 1.50})
+	handler := NewHandler(NewService(repo))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/retention/report", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGetRetentionReport(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 OK, got %d", resp.StatusCode)
+	}
+
+	var report RetentionReport
+	json.NewDecoder(resp.Body).Decode(&report)
+	if report.TotalPlays != 1 {
+		t.Errorf("Expected 1 play in dry-run report, got %d", report.TotalPlays)
+	}
+	if len(repo.GetAllLogs()) != 2 {
+		t.Error("Dry-run report must not remove logs")
+	}
+}
+
+func TestWebhookDispatcher(t *testing.T) {
+	testWebhookDispatcher(t, NewInMemoryRepository())
+}
+
+func testWebhookDispatcher(t *testing.T, repo IRepository) {
+	var received []string
+	fail := true
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		timestamp, _ := strconv.ParseInt(r.Header.Get(TimestampHeader), 10, 64)
+		if r.Header.Get(SignatureHeader) != SignWebhook("s3cret", timestamp, body) {
+			t.Errorf("Invalid signature for body %s", body)
+		}
+		if fail {
+			fail = false
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		received = append(received, r.Header.Get("X-Jukebox-Event"))
+	}))
+	defer receiver.Close()
+
+	service := NewService(repo)
+	sub, err := service.CreateWebhook(receiver.URL, []string{EventPlaybackLogged}, "s3cret")
+	if err != nil {
+		t.Fatalf("CreateWebhook failed: %v", err)
+	}
+	service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: 1.25})
+	service.UpdatePrice(1, 2.00)
+
+	dispatcher := NewWebhookDispatcher(repo)
+	now := time.Now()
+	if err := dispatcher.RunOnce(t.Context(), now); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	deliveries, _ := service.GetWebhookDeliveries(sub.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliveryPending || deliveries[0].Attempts != 1 {
+		t.Fatalf("Expected one pending delivery after failed attempt, got %+v", deliveries)
+	}
+
+	dispatcher.RunOnce(t.Context(), now.Add(time.Second))
+	if len(received) != 0 {
+		t.Error("Delivery retried before backoff elapsed")
+	}
+
+	dispatcher.RunOnce(t.Context(), now.Add(webhookInitialBackoff))
+	if !reflect.DeepEqual(received, []string{EventPlaybackLogged}) {
+		t.Errorf("Expected one playback event, got %v", received)
+	}
+
+	deliveries, _ = service.GetWebhookDeliveries(sub.ID)
+	if deliveries[0].Status != DeliveryDelivered || deliveries[0].Attempts != 2 {
+		t.Errorf("Expected delivered after 2 attempts, got %+v", deliveries[0])
+	}
+}
+
+func TestWebhookHistoryRetention(t *testing.T) {
+	testWebhookHistoryRetention(t, NewInMemoryRepository())
+}
+
+// testWebhookHistoryRetention checks that events nobody subscribes to are not
+// queued and that the retention job drops finished deliveries but keeps
+// pending ones.
+func testWebhookHistoryRetention(t *testing.T, repo IRepository) {
+	ok := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer ok.Close()
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+
+	service := NewService(repo)
+	delivered, _ := service.CreateWebhook(ok.URL, []string{EventPriceUpdated}, "s3cret")
+	service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: 1.25})
+	service.UpdatePrice(1, 2.00)
+	if n, err := repo.DispatchOutbox(webhookBatchSize); err != nil || n != 1 {
+		t.Fatalf("Expected only the subscribed event in the outbox, got %d, %v", n, err)
+	}
+
+	retrying, _ := service.CreateWebhook(down.URL, []string{EventPriceUpdated}, "s3cret")
+	service.UpdatePrice(1, 2.50)
+	now := time.Now()
+	if err := NewWebhookDispatcher(repo).RunOnce(t.Context(), now); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(now.Add(2 * logRetention)); err != nil {
+		t.Fatalf("Retention RunOnce failed: %v", err)
+	}
+	if deliveries, _ := service.GetWebhookDeliveries(delivered.ID); len(deliveries) != 0 {
+		t.Errorf("Expected finished deliveries to be purged, got %+v", deliveries)
+	}
+	deliveries, _ := service.GetWebhookDeliveries(retrying.ID)
+	if len(deliveries) != 1 || deliveries[0].Status != DeliveryPending {
+		t.Fatalf("Expected the pending delivery to be kept, got %+v", deliveries)
+	}
+	if due, _ := repo.GetDueDeliveries(now.Add(webhookMaxBackoff), webhookBatchSize); len(due) != 1 || due[0].Event.Type != EventPriceUpdated {
+		t.Errorf("Expected the pending delivery to keep its event, got %+v", due)
+	}
+}
+
+func TestHandleCreateWebhook(t *testing.T) {
+	handler := NewHandler(NewService(NewInMemoryRepository()))
+
+	reqBody := []byte(`{"url": "ftp://example.com", "event_types": ["playback.logged"]}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(reqBody))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreateWebhook(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 Bad Request, got %d", w.Result().StatusCode)
+	}
+
+	reqBody = []byte(`{"url": "https://example.com/hook", "event_types": ["price.updated"]}`)
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/webhooks", bytes.NewBuffer(reqBody))
+	w = httptest.NewRecorder()
+
+	handler.HandleCreateWebhook(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusCreated {
+		t.Errorf("Expected status 201 Created, got %d", resp.StatusCode)
+	}
+	var sub WebhookSubscription
+	json.NewDecoder(resp.Body).Decode(&sub)
+	if sub.Secret == "" {
+		t.Error("Expected generated secret in create response")
+	}
+}
+
+func TestHandleGetTrendingTracks(t *testing.T) {
+	testHandleGetTrendingTracks(t, NewInMemoryRepository())
+}
+
+func testHandleGetTrendingTracks(t *testing.T, repo IRepository) {
+	now := time.Now()
+	for i := 0; i < 14; i++ {
+		repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-48 * time.Hour)})
+	}
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-time.Hour)})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: now.Add(-time.Hour)})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: now.Add(-time.Minute)})
+	handler := NewHandler(NewService(repo))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/trending", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGetTrendingTracks(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 OK, got %d", resp.StatusCode)
+	}
+
+	var result []TrendingTrackStat
+	json.NewDecoder(resp.Body).Decode(&result)
+	if len(result) != 2 || result[0].Title != "Space Oddity" || result[1].BaselineCount != 14 {
+		t.Errorf("Unexpected trending order: %+v", result)
+	}
+}
+
+func TestHandleGetRelatedTracks(t *testing.T) {
+	testHandleGetRelatedTracks(t, NewInMemoryRepository())
+}
+
+func testHandleGetRelatedTracks(t *testing.T, repo IRepository) {
+	now := time.Now()
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-3 * time.Minute), DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(-2 * time.Minute), DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: now.Add(-2 * time.Minute), DeviceID: "jb-2"})
+	if _, err := NewCooccurrenceJob(repo).RunOnce(); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: now.Add(-time.Minute), DeviceID: "jb-1"})
+	// Neither a voided nor a quarantined play pairs with its neighbors.
+	voided, _ := repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(-time.Minute), DeviceID: "jb-2"})
+	if err := repo.VoidLog(voided.ID, "refund", 0, now); err != nil {
+		t.Fatalf("VoidLog failed: %v", err)
+	}
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-2 * time.Minute), DeviceID: "jb-9"})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(-time.Minute), DeviceID: "jb-9"})
+	alert := Alert{DeviceID: "jb-9", Kind: AlertZeroAmount, WindowStart: now.Add(-time.Hour), WindowEnd: now, Quarantined: true, CreatedAt: now}
+	if err := repo.SaveAnomalyBatch(nil, []Alert{alert}, 0); err != nil {
+		t.Fatalf("SaveAnomalyBatch failed: %v", err)
+	}
+	if _, err := NewCooccurrenceJob(repo).RunOnce(); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	handler := NewHandler(NewService(repo))
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tracks/2/related", nil)
+	req.SetPathValue("id", "2")
+	w := httptest.NewRecorder()
+
+	handler.HandleGetRelatedTracks(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Expected status 200 OK, got %d", resp.StatusCode)
+	}
+
+	var result []RelatedTrack
+	json.NewDecoder(resp.Body).Decode(&result)
+	expected := []RelatedTrack{
+		{TrackID: 1, Title: "Dirty Diana", Artist: "Michael Jackson", Count: 1},
+		{TrackID: 3, Title: "Space Oddity", Artist: "David Bowie", Count: 1},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("Result mismatch.\nExpected: %+v\nGot:      %+v", expected, result)
+	}
+}
+
+func TestPricingRules(t *testing.T) {
+	testPricingRules(t, NewInMemoryRepository())
+}
+
+func testPricingRules(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(-time.Hour)})
+	}
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-time.Hour)})
+
+	_, err := service.CreatePricingRule(PricingRule{
+		Name: "surge", Target: PricingTargetTop, TrackCount: 1, AdjustPercent: 20,
+		WindowHours: 24, CooldownHours: 6, Enabled: true,
+	})
+	if err != nil {
+		t.Fatalf("CreatePricingRule failed: %v", err)
+	}
+	service.SetPriceBounds(PriceBounds{TrackID: 2, Ceiling: 1.70})
+
+	preview, _ := service.PreviewPricing(now)
+	if len(preview) != 1 || preview[0].TrackID != 2 || preview[0].NewPrice != 1.70 {
+		t.Fatalf("Unexpected preview: %+v", preview)
+	}
+	if track, _ := repo.GetTrackByID(2); track.Price != 1.50 {
+		t.Error("Preview must not change prices")
+	}
+
+	applied, err := service.ApplyPricing(now)
+	if err != nil || len(applied) != 1 {
+		t.Fatalf("ApplyPricing failed: %v, %+v", err, applied)
+	}
+	if track, _ := repo.GetTrackByID(2); track.Price != 1.70 {
+		t.Errorf("Expected price 1.70, got %.2f", track.Price)
+	}
+
+	if again, _ := service.PreviewPricing(now.Add(time.Hour)); len(again) != 0 {
+		t.Errorf("Expected no changes within cooldown, got %+v", again)
+	}
+
+	audit, _ := service.GetPriceChanges()
+	if len(audit) != 1 || audit[0].OldPrice != 1.50 {
+		t.Errorf("Unexpected audit log: %+v", audit)
+	}
+}
+
+func TestHandleCreatePricingRule(t *testing.T) {
+	handler := NewHandler(NewService(NewInMemoryRepository()))
+
+	reqBody := []byte(`{"name": "bad", "target": "middle", "track_count": 1, "adjust_percent": 10, "window_hours": 24}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/pricing/rules", bytes.NewBuffer(reqBody))
+	w := httptest.NewRecorder()
+
+	handler.HandleCreatePricingRule(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 Bad Request, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestScheduledPrices(t *testing.T) {
+	testScheduledPrices(t, NewInMemoryRepository())
+}
+
+func testScheduledPrices(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	start := time.Date(2026, 7, 1, 18, 0, 0, 0, time.UTC)
+	end := start.Add(2 * time.Hour)
+
+	sp, err := service.SchedulePrice(3, 0.50, start, &end)
+	if err != nil {
+		t.Fatalf("SchedulePrice failed: %v", err)
+	}
+
+	steps := []struct {
+		at     time.Time
+		price  float64
+		status string
+	}{
+		{start.Add(-time.Minute), 1.00, ScheduleStatusPending},
+		{start, 0.50, ScheduleStatusActive},
+		{end.Add(time.Minute), 1.00, ScheduleStatusCompleted},
+	}
+	for _, step := range steps {
+		if _, err := service.ApplyScheduledPrices(step.at); err != nil {
+			t.Fatalf("ApplyScheduledPrices failed: %v", err)
+		}
+		track, _ := repo.GetTrackByID(3)
+		current, _ := repo.GetScheduledPriceByID(sp.ID)
+		if track.Price != step.price || current.Status != step.status {
+			t.Errorf("At %s expected price %.2f (%s), got %.2f (%s)",
+				step.at, step.price, step.status, track.Price, current.Status)
+		}
+	}
+
+	if err := service.CancelScheduledPrice(sp.ID); !errors.Is(err, ScheduledPriceNotCancellable) {
+		t.Errorf("Expected ScheduledPriceNotCancellable, got %v", err)
+	}
+}
+
+func TestHandleSchedulePrice(t *testing.T) {
+	handler := NewHandler(NewService(NewInMemoryRepository()))
+
+	reqBody := []byte(`{"price": 2.00, "starts_at": "2026-07-01T20:00:00Z", "ends_at": "2026-07-01T19:00:00Z"}`)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tracks/1/scheduled-prices", bytes.NewBuffer(reqBody))
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+
+	handler.HandleSchedulePrice(w, req)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 Bad Request, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestEffectivePrice(t *testing.T) {
+	testEffectivePrice(t, NewInMemoryRepository())
+}
+
+func testEffectivePrice(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+
+	rules := []TimePriceRule{
+		{Name: "happy hour", StartTime: "16:00", EndTime: "20:00", AdjustPercent: -20, TimeZone: "Europe/Kyiv"},
+		{Name: "late night", Weekdays: []int{5}, StartTime: "23:00", EndTime: "03:00", Price: 2.00, TimeZone: "Europe/Kyiv"},
+		{Name: "floyd", Artist: "pink floyd", StartTime: "16:00", EndTime: "18:00", Price: 1.00, TimeZone: "Europe/Kyiv"},
+	}
+	for _, rule := range rules {
+		if _, err := service.CreateTimePriceRule(rule); err != nil {
+			t.Fatalf("CreateTimePriceRule failed: %v", err)
+		}
+	}
+
+	kyiv, _ := time.LoadLocation("Europe/Kyiv")
+	tests := []struct {
+		name    string
+		trackID int
+		at      time.Time
+		price   float64
+	}{
+		{"outside rules", 1, time.Date(2026, 7, 3, 12, 0, 0, 0, kyiv), 1.25},
+		{"happy hour", 1, time.Date(2026, 7, 3, 17, 0, 0, 0, kyiv), 1.00},
+		{"artist beats catalog", 2, time.Date(2026, 7, 3, 17, 0, 0, 0, kyiv), 1.00},
+		{"friday night", 1, time.Date(2026, 7, 3, 23, 30, 0, 0, kyiv), 2.00},
+		{"friday night after midnight", 1, time.Date(2026, 7, 4, 1, 0, 0, 0, kyiv), 2.00},
+		{"saturday night", 1, time.Date(2026, 7, 4, 23, 30, 0, 0, kyiv), 1.25},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			price, err := service.GetEffectivePrice(tt.trackID, tt.at, "")
+			if err != nil {
+				t.Fatalf("GetEffectivePrice failed: %v", err)
+			}
+			if price.Price != tt.price {
+				t.Errorf("Expected %.2f, got %.2f (rule %q)", tt.price, price.Price, price.RuleName)
+			}
+		})
+	}
+}
+
+func TestVenueTimePricing(t *testing.T) {
+	testVenueTimePricing(t, NewInMemoryRepository())
+}
+
+// testVenueTimePricing checks that time-of-day rules follow the playing
+// device's venue and fall back to the rule's time zone without one.
+func testVenueTimePricing(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	if _, err := service.CreateTimePriceRule(TimePriceRule{Name: "happy hour", StartTime: "16:00", EndTime: "20:00", AdjustPercent: -20, TimeZone: "UTC"}); err != nil {
+		t.Fatalf("CreateTimePriceRule failed: %v", err)
+	}
+	if _, err := service.SaveVenue(Venue{ID: "tokyo", TimeZone: "Asia/Tokyo"}); err != nil {
+		t.Fatalf("SaveVenue failed: %v", err)
+	}
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "tokyo", LastSeenAt: time.Now()})
+	repo.RecordHeartbeat(Device{DeviceID: "jb-2", LastSeenAt: time.Now()})
+
+	tokyo, _ := time.LoadLocation("Asia/Tokyo")
+	at := time.Date(2026, 7, 3, 17, 0, 0, 0, tokyo)
+	for _, tt := range []struct {
+		deviceID string
+		price    float64
+	}{
+		{"jb-1", 1.00},
+		{"jb-2", 1.25},
+		{"", 1.25},
+	} {
+		price, err := service.GetEffectivePrice(1, at, tt.deviceID)
+		if err != nil || price.Price != tt.price {
+			t.Errorf("Expected %.2f on device %q, got %+v, %v", tt.price, tt.deviceID, price, err)
+		}
+	}
+
+	log, err := service.preparePlayback(PlaybackLog{TrackID: 1, DeviceID: "jb-1", AmountPaid: 1.00}, at)
+	if err != nil || log.ListPrice != 1.00 || log.PaymentStatus != PaymentOK {
+		t.Errorf("Expected the play priced at the venue's happy hour, got %+v, %v", log, err)
+	}
+}
+
+func TestHandleLogPlaybackBelowPrice(t *testing.T) {
+	testHandleLogPlaybackBelowPrice(t, NewInMemoryRepository())
+}
+
+func testHandleLogPlaybackBelowPrice(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	logPlayback := func() int {
+		reqBody := []byte(`{"track_id": 2, "amount_paid": 1.00}`)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs", bytes.NewBuffer(reqBody))
+		w := httptest.NewRecorder()
+		handler.HandleLogPlayback(w, req)
+		return w.Result().StatusCode
+	}
+
+	if status := logPlayback(); status != http.StatusCreated {
+		t.Errorf("Expected the default policy to accept an underpayment, got %d", status)
+	}
+	if logs := repo.GetAllLogs(); len(logs) != 1 || logs[0].PaymentStatus != PaymentUnderpaid {
+		t.Errorf("Expected the underpayment to be flagged, got %+v", logs)
+	}
+
+	service.SetPaymentPolicy(PaymentPolicyReject)
+	if status := logPlayback(); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 Bad Request, got %d", status)
+	}
+}
+
+func TestReconciliationReport(t *testing.T) {
+	testReconciliationReport(t, NewInMemoryRepository())
+}
+
+func testReconciliationReport(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	if err := service.SetPaymentPolicy(PaymentPolicyFlag); err != nil {
+		t.Fatalf("SetPaymentPolicy failed: %v", err)
+	}
+
+	plays := []PlaybackLog{
+		{TrackID: 1, AmountPaid: 1.25, DeviceID: "jb-1"},
+		{TrackID: 1, AmountPaid: 0.25, DeviceID: "jb-1"},
+		{TrackID: 2, AmountPaid: 2.00, DeviceID: "jb-1"},
+		{TrackID: 3, AmountPaid: 1.00, DeviceID: "jb-2"},
+	}
+	for _, play := range plays {
+		if err := service.CreateLog(play); err != nil {
+			t.Fatalf("CreateLog failed: %v", err)
+		}
+	}
+	if err := service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: -1}); !errors.Is(err, NegativeAmount) {
+		t.Errorf("Expected NegativeAmount, got %v", err)
+	}
+
+	handler := NewHandler(service)
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/reconciliation", nil)
+	w := httptest.NewRecorder()
+
+	handler.HandleGetReconciliationReport(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 OK, got %d", resp.StatusCode)
+	}
+
+	var report ReconciliationReport
+	json.NewDecoder(resp.Body).Decode(&report)
+	if len(report.Rows) != 2 || len(report.Discrepancies) != 2 {
+		t.Fatalf("Unexpected report: %+v", report)
+	}
+	row := report.Rows[0]
+	if row.DeviceID != "jb-1" || row.Plays != 3 || row.Underpaid != 1 || row.Overpaid != 1 || row.Difference != -0.50 {
+		t.Errorf("Unexpected row for jb-1: %+v", row)
+	}
+}
+
+func TestReconciliationVenueDays(t *testing.T) {
+	testReconciliationVenueDays(t, NewInMemoryRepository())
+}
+
+// testReconciliationVenueDays checks that plays are reconciled per day of
+// the device's venue rather than per UTC day.
+func testReconciliationVenueDays(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	if _, err := service.SaveVenue(Venue{ID: "tokyo", TimeZone: "Asia/Tokyo"}); err != nil {
+		t.Fatalf("SaveVenue failed: %v", err)
+	}
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "tokyo", LastSeenAt: time.Now()})
+
+	// 22:30 UTC on July 3rd is 07:30 on July 4th in Tokyo.
+	evening := time.Date(2026, 7, 3, 22, 30, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: evening.Add(-12 * time.Hour), AmountPaid: 1.25, ListPrice: 1.25, PaymentStatus: PaymentOK, DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: evening, AmountPaid: 1.25, ListPrice: 1.25, PaymentStatus: PaymentOK, DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: evening, AmountPaid: 1.25, ListPrice: 1.25, PaymentStatus: PaymentOK, DeviceID: "jb-2"})
+
+	report, err := service.GetReconciliationReport(evening.Add(-24*time.Hour), evening.Add(24*time.Hour))
+	if err != nil {
+		t.Fatalf("GetReconciliationReport failed: %v", err)
+	}
+	var got []string
+	for _, row := range report.Rows {
+		got = append(got, fmt.Sprintf("%s %s %s %d", row.Day, row.TimeZone, row.DeviceID, row.Plays))
+	}
+	expected := []string{
+		"2026-07-03 Asia/Tokyo jb-1 1",
+		"2026-07-03 UTC jb-2 1",
+		"2026-07-04 Asia/Tokyo jb-1 1",
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Rows mismatch.\nExpected: %v\nGot:      %v", expected, got)
+	}
+}
+
+func TestHandleVoidLog(t *testing.T) {
+	testHandleVoidLog(t, NewInMemoryRepository())
+}
+
+func testHandleVoidLog(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: 1.25})
+	service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: 1.25})
+	service.CreateLog(PlaybackLog{TrackID: 2, AmountPaid: 1.50})
+	handler := NewHandler(service)
+
+	void := func(id string, body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs/"+id+"/void", bytes.NewBufferString(body))
+		req.SetPathValue("id", id)
+		w := httptest.NewRecorder()
+		handler.HandleVoidLog(w, req)
+		return w.Result().StatusCode
+	}
+
+	if status := void("1", `{"reason": "song failed to play", "refunded_amount": 2.00}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for refund above amount paid, got %d", status)
+	}
+	if status := void("1", `{"reason": "song failed to play"}`); status != http.StatusOK {
+		t.Errorf("Expected status 200 OK, got %d", status)
+	}
+	if status := void("1", `{"reason": "again"}`); status != http.StatusConflict {
+		t.Errorf("Expected status 409 Conflict, got %d", status)
+	}
+	if status := void("99", `{"reason": "missing"}`); status != http.StatusNotFound {
+		t.Errorf("Expected status 404 Not Found, got %d", status)
+	}
+
+	log, _ := repo.GetLogByID(1)
+	if log.RefundedAmount != 1.25 || log.VoidReason != "song failed to play" {
+		t.Errorf("Unexpected voided log: %+v", log)
+	}
+
+	stats, _ := service.GetTopTracks()
+	if len(stats) != 2 || stats[0].Count != 1 || stats[1].Count != 1 {
+		t.Errorf("Voided play not excluded from top tracks, got %+v", stats)
+	}
+
+	handler = NewHandler(NewService(failingVoidRepository{repo}))
+	if status := void("2", `{"reason": "song failed to play"}`); status != http.StatusInternalServerError {
+		t.Errorf("Expected status 500 when the void cannot be stored, got %d", status)
+	}
+}
+
+// failingVoidRepository fails every VoidLog as a database error would.
+type failingVoidRepository struct {
+	IRepository
+}
+
+func (f failingVoidRepository) VoidLog(id int, reason string, refundedAmount float64, voidedAt time.Time) error {
+	return errors.New("database is locked")
+}
+
+func TestPlayQueue(t *testing.T) {
+	testPlayQueue(t, NewInMemoryRepository())
+}
+
+func testPlayQueue(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+
+	first, err := service.EnqueueTrack("jb-1", "", 1, 1.25)
+	if err != nil {
+		t.Fatalf("EnqueueTrack failed: %v", err)
+	}
+	second, _ := service.EnqueueTrack("jb-1", "", 2, 1.50)
+	third, _ := service.EnqueueTrack("jb-1", "", 3, 1.00)
+	if _, err := service.EnqueueTrack("jb-1", "", 99, 1.00); !errors.Is(err, TrackNotFoundError) {
+		t.Errorf("Expected TrackNotFoundError, got %v", err)
+	}
+
+	if _, err := service.FinishQueueItem(first.ID); !errors.Is(err, InvalidQueueTransition) {
+		t.Errorf("Expected InvalidQueueTransition finishing a queued item, got %v", err)
+	}
+	started, err := service.StartQueueItem(first.ID)
+	if err != nil {
+		t.Fatalf("StartQueueItem failed: %v", err)
+	}
+	if started.LogID == 0 {
+		t.Error("Starting a queue item did not create a playback log")
+	}
+	if _, err := service.StartQueueItem(second.ID); !errors.Is(err, InvalidQueueTransition) {
+		t.Errorf("Expected InvalidQueueTransition while another item plays, got %v", err)
+	}
+	if _, err := service.FinishQueueItem(first.ID); err != nil {
+		t.Fatalf("FinishQueueItem failed: %v", err)
+	}
+	if _, err := service.SkipQueueItem(second.ID); err != nil {
+		t.Fatalf("SkipQueueItem failed: %v", err)
+	}
+
+	queue, _ := service.GetQueue("jb-1")
+	if len(queue) != 1 || queue[0].ID != third.ID {
+		t.Errorf("Expected only the third item to remain queued, got %+v", queue)
+	}
+	logs := repo.GetAllLogs()
+	if len(logs) != 2 || logs[0].TrackID != 1 || logs[0].DeviceID != "jb-1" || logs[0].VoidedAt != nil {
+		t.Fatalf("Expected a playback log for the started item and one for the skipped item, got %+v", logs)
+	}
+	if skipped := logs[1]; skipped.TrackID != 2 || skipped.VoidedAt == nil || skipped.RefundedAmount != 1.50 {
+		t.Errorf("Expected the skipped paid item to be logged as voided and refunded, got %+v", skipped)
+	}
+}
+
+func TestSkipStats(t *testing.T) {
+	testSkipStats(t, NewInMemoryRepository())
+}
+
+func testSkipStats(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SetTrackDuration(1, 281)
+	service.SetTrackDuration(2, 383)
+	for range 4 {
+		service.CreateLog(PlaybackLog{TrackID: 2, AmountPaid: 1.50})
+	}
+	service.CreateLog(PlaybackLog{TrackID: 1, AmountPaid: 1.25})
+
+	service.CompleteLog(1, 383, false)
+	service.CompleteLog(2, 10000, false)
+	service.CompleteLog(3, 38, true)
+	service.CompleteLog(4, 0, true)
+	service.CompleteLog(5, 281, false)
+	if _, err := service.CompleteLog(1, 100, true); !errors.Is(err, PlaybackAlreadyCompleted) {
+		t.Errorf("Expected PlaybackAlreadyCompleted, got %v", err)
+	}
+
+	stats, err := service.GetSkipStats(time.Now().Add(-time.Hour), time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("GetSkipStats failed: %v", err)
+	}
+	if len(stats) != 2 || stats[0].TrackID != 2 {
+		t.Fatalf("Expected track 2 first, got %+v", stats)
+	}
+	if stats[0].Plays != 4 || stats[0].Skips != 2 || stats[0].SkipRate != 0.5 {
+		t.Errorf("Unexpected skip stats for track 2: %+v", stats[0])
+	}
+	if math.Abs(stats[0].AvgCompletion-0.525) > 0.001 {
+		t.Errorf("Expected average completion 0.525, got %f", stats[0].AvgCompletion)
+	}
+	if stats[1].SkipRate != 0 || stats[1].AvgCompletion != 1 {
+		t.Errorf("Unexpected skip stats for track 1: %+v", stats[1])
+	}
+}
+
+func TestAnomalyDetector(t *testing.T) {
+	testAnomalyDetector(t, NewInMemoryRepository())
+}
+
+func testAnomalyDetector(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SetAnomalyQuarantine(true)
+
+	now := time.Now()
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-time.Hour), AmountPaid: 1.25, DeviceID: "jb-2"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-30 * time.Minute), AmountPaid: 1.25, DeviceID: "jb-2"})
+	for i := range 12 {
+		repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(time.Duration(i) * time.Second), AmountPaid: 0, DeviceID: "jb-1"})
+	}
+
+	detector := NewAnomalyDetector(repo)
+	if _, err := detector.RunOnce(); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+
+	alerts, _ := service.GetAlerts("", false)
+	kinds := make(map[string]Alert)
+	for _, alert := range alerts {
+		if alert.DeviceID != "jb-1" {
+			t.Errorf("Unexpected alert for device %s: %+v", alert.DeviceID, alert)
+		}
+		kinds[alert.Kind] = alert
+	}
+	if len(alerts) != 2 || kinds[AlertHighRate].Count != 3 || kinds[AlertZeroAmount].Count != 8 {
+		t.Fatalf("Expected one extended high_rate and zero_amount alert each, got %+v", alerts)
+	}
+
+	stats, _ := service.GetTopTracks()
+	if len(stats) != 1 || stats[0].Title != "Dirty Diana" {
+		t.Errorf("Quarantined plays not excluded from top tracks, got %+v", stats)
+	}
+
+	if _, err := service.ResolveAlert(kinds[AlertZeroAmount].ID, true); err != nil {
+		t.Fatalf("ResolveAlert failed: %v", err)
+	}
+	if _, err := service.ResolveAlert(kinds[AlertZeroAmount].ID, true); !errors.Is(err, AlertAlreadyResolved) {
+		t.Errorf("Expected AlertAlreadyResolved, got %v", err)
+	}
+	stats, _ = service.GetTopTracks()
+	if len(stats) != 1 {
+		t.Errorf("Plays still covered by the high_rate alert were released, got %+v", stats)
+	}
+	service.ResolveAlert(kinds[AlertHighRate].ID, true)
+	stats, _ = service.GetTopTracks()
+	if len(stats) != 2 || stats[0].Title != "Comfortably Numb" || stats[0].Count != 12 {
+		t.Errorf("Released plays not counted again, got %+v", stats)
+	}
+}
+
+func TestDeviceMonitor(t *testing.T) {
+	testDeviceMonitor(t, NewInMemoryRepository())
+}
+
+func testDeviceMonitor(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.CreateWebhook("https://example.com/hook", []string{EventDeviceOffline}, "secret")
+
+	if _, err := service.SaveVenue(Venue{ID: "bar", TimeZone: "Europe/Kyiv", OpensAt: "18:00", ClosesAt: "02:00"}); err != nil {
+		t.Fatalf("SaveVenue failed: %v", err)
+	}
+	if _, err := service.Heartbeat("jb-1", "1.4.2", "unknown"); !errors.Is(err, VenueNotFoundError) {
+		t.Errorf("Expected VenueNotFoundError, got %v", err)
+	}
+
+	kyiv, _ := time.LoadLocation("Europe/Kyiv")
+	lastSeen := time.Date(2026, 7, 3, 12, 0, 0, 0, kyiv)
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "bar", FirmwareVersion: "1.4.2", LastSeenAt: lastSeen})
+
+	monitor := NewDeviceMonitor(repo)
+	if raised, _ := monitor.RunOnce(lastSeen.Add(4 * time.Hour)); raised != 0 {
+		t.Errorf("Expected no alert while the venue is closed, got %d", raised)
+	}
+	if raised, _ := monitor.RunOnce(lastSeen.Add(13 * time.Hour)); raised != 1 {
+		t.Errorf("Expected an alert after midnight while the venue is open, got %d", raised)
+	}
+	if raised, _ := monitor.RunOnce(lastSeen.Add(13*time.Hour + time.Minute)); raised != 0 {
+		t.Errorf("Expected the open alert not to be raised again, got %d", raised)
+	}
+
+	repo.DispatchOutbox(10)
+	deliveries, _ := repo.GetDueDeliveries(time.Now(), 10)
+	if len(deliveries) != 1 || deliveries[0].EventType != EventDeviceOffline {
+		t.Errorf("Expected one device.offline delivery, got %+v", deliveries)
+	}
+
+	device, err := service.Heartbeat("jb-1", "1.5.0", "")
+	if err != nil {
+		t.Fatalf("Heartbeat failed: %v", err)
+	}
+	if device.VenueID != "bar" || device.FirmwareVersion != "1.5.0" || !device.Online {
+		t.Errorf("Unexpected device after heartbeat: %+v", device)
+	}
+	if alerts, _ := service.GetAlerts("jb-1", false); len(alerts) != 0 {
+		t.Errorf("Expected heartbeat to resolve the offline alert, got %+v", alerts)
+	}
+}
+
+func TestSearchDiacritics(t *testing.T) {
+	testSearchDiacritics(t, NewInMemoryRepository())
+}
+
+// testSearchDiacritics checks that search ignores diacritics both ways.
+func testSearchDiacritics(t *testing.T, repo IRepository) {
+	ids, err := repo.ImportCatalog([]CatalogUpsert{{Row: 1, Track: Track{Title: "Halo", Artist: "Beyoncé", Price: 1.25}}}, time.Now())
+	if err != nil {
+		t.Fatalf("ImportCatalog failed: %v", err)
+	}
+	for _, query := range []string{"beyonce", "BEYONCÉ", "halo Beyoncè"} {
+		tracks, total, err := repo.SearchTracks(query, 10, 0)
+		if err != nil || total != 1 || len(tracks) != 1 || tracks[0].ID != ids[0] {
+			t.Errorf("Expected %q to find Beyoncé, got %d %+v, %v", query, total, tracks, err)
+		}
+	}
+}
+
+func TestHandleSearchTracks(t *testing.T) {
+	handler := NewHandler(NewService(NewInMemoryRepository()))
+
+	search := func(query string) (int, TrackSearchResult) {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/tracks?"+query, nil)
+		w := httptest.NewRecorder()
+		handler.HandleSearchTracks(w, req)
+		var result TrackSearchResult
+		json.NewDecoder(w.Body).Decode(&result)
+		return w.Result().StatusCode, result
+	}
+
+	if status, result := search("q=PINK+numb"); status != http.StatusOK || result.Total != 1 || result.Tracks[0].ID != 2 {
+		t.Errorf("Expected Comfortably Numb, got %d %+v", status, result)
+	}
+	if _, result := search("q=d&limit=2&offset=1"); result.Total != 3 || len(result.Tracks) != 2 || result.Tracks[0].ID != 2 {
+		t.Errorf("Unexpected page, got %+v", result)
+	}
+	if _, result := search("q=beatles"); result.Total != 0 || result.Tracks == nil {
+		t.Errorf("Expected an empty result, got %+v", result)
+	}
+	if status, _ := search("limit=500"); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a limit above the maximum, got %d", status)
+	}
+}
+
+func TestArtistLeaderboards(t *testing.T) {
+	testArtistLeaderboards(t, NewInMemoryRepository())
+}
+
+func testArtistLeaderboards(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	now := time.Now()
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-time.Hour), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: now.Add(-2 * time.Hour), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: now.Add(-time.Hour), AmountPaid: 5.00})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: now.AddDate(-2, 0, 0), AmountPaid: 1.00})
+
+	byPlays, err := service.GetTopArtists("", 0, time.Time{}, now)
+	if err != nil {
+		t.Fatalf("GetTopArtists failed: %v", err)
+	}
+	if len(byPlays) != 3 || byPlays[0].Name != "Michael Jackson" || byPlays[0].Plays != 2 {
+		t.Errorf("Expected Michael Jackson to lead by plays, got %+v", byPlays)
+	}
+	byRevenue, _ := service.GetTopArtists("revenue", 1, now.AddDate(0, 0, -7), now)
+	if len(byRevenue) != 1 || byRevenue[0].Name != "Pink Floyd" || byRevenue[0].Revenue != 5.00 {
+		t.Errorf("Expected Pink Floyd to lead by revenue, got %+v", byRevenue)
+	}
+	if _, err := service.GetTopArtists("title", 0, time.Time{}, now); !errors.Is(err, InvalidSortOrder) {
+		t.Errorf("Expected InvalidSortOrder, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/artists/3/tracks", nil)
+	req.SetPathValue("id", "3")
+	w := httptest.NewRecorder()
+	handler.HandleGetArtistBreakdown(w, req)
+	var breakdown ArtistBreakdown
+	json.NewDecoder(w.Body).Decode(&breakdown)
+	if breakdown.Plays != 1 || len(breakdown.Tracks) != 1 || breakdown.Tracks[0].TrackID != 3 {
+		t.Errorf("Expected the all-time breakdown to include the old play, got %+v", breakdown)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/artists/99/tracks", nil)
+	req.SetPathValue("id", "99")
+	w = httptest.NewRecorder()
+	handler.HandleGetArtistBreakdown(w, req)
+	if w.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown artist, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	testHeatmap(t, NewInMemoryRepository())
+}
+
+func testHeatmap(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	service.SaveVenue(Venue{ID: "bar", Name: "The Bar", TimeZone: "Europe/Kyiv"})
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "bar", LastSeenAt: time.Now()})
+
+	// Friday 22:30 UTC is Saturday 01:30 in Kyiv.
+	playedAt := time.Date(2026, 7, 3, 22, 30, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: playedAt, AmountPaid: 1.25, DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: playedAt.Add(10 * time.Minute), AmountPaid: 1.50, DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: playedAt, AmountPaid: 1.25, DeviceID: "jb-2"})
+
+	heatmap, err := service.GetHeatmap(HeatmapFilter{VenueID: "bar", To: time.Now()}, "")
+	if err != nil {
+		t.Fatalf("GetHeatmap failed: %v", err)
+	}
+	if cell := heatmap.Cells[time.Saturday][1]; heatmap.TimeZone != "Europe/Kyiv" || cell.Plays != 2 || cell.Revenue != 2.75 {
+		t.Errorf("Expected two plays on Saturday at 01:00 Kyiv time, got %s %+v", heatmap.TimeZone, cell)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/heatmap?artist_id=1", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetHeatmap(w, req)
+	var byArtist Heatmap
+	json.NewDecoder(w.Body).Decode(&byArtist)
+	if cell := byArtist.Cells[time.Friday][22]; byArtist.TimeZone != "UTC" || cell.Plays != 2 {
+		t.Errorf("Expected both Michael Jackson plays on Friday at 22:00 UTC, got %s %+v", byArtist.TimeZone, cell)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/stats/heatmap?tz=Mars/Olympus", nil)
+	w = httptest.NewRecorder()
+	handler.HandleGetHeatmap(w, req)
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown time zone, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestChartSnapshotAcrossPurgedBoundary(t *testing.T) {
+	testChartSnapshotAcrossPurgedBoundary(t, NewInMemoryRepository())
+}
+
+// testChartSnapshotAcrossPurgedBoundary checks that rolled up plays from a
+// week's first hour are charted in that week.
+func testChartSnapshotAcrossPurgedBoundary(t *testing.T, repo IRepository) {
+	week := time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC) // a Monday
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: week.Add(-10 * time.Minute), AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: week.Add(10 * time.Minute), AmountPaid: 1})
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(week.Add(2 * logRetention)); err != nil || purged != 2 {
+		t.Fatalf("Expected 2 purged logs, got %d, %v", purged, err)
+	}
+
+	if saved, err := NewChartJob(repo, chartPeriod, chartSize).RunOnce(week.AddDate(0, 0, 8)); err != nil || saved != 1 {
+		t.Fatalf("Expected one snapshot, got %d: %v", saved, err)
+	}
+	chart, err := NewService(repo).GetChart(0)
+	if err != nil {
+		t.Fatalf("GetChart failed: %v", err)
+	}
+	if !chart.PeriodStart.Equal(week) || len(chart.Entries) != 1 || chart.Entries[0].TrackID != 1 || chart.Entries[0].Plays != 1 {
+		t.Errorf("Expected only the week's first-hour play on the chart, got %+v", chart)
+	}
+}
+
+func TestChartsAfterMerge(t *testing.T) {
+	testChartsAfterMerge(t, NewInMemoryRepository())
+}
+
+// testChartsAfterMerge checks that charts show a merged duplicate as its
+// canonical track and carry its chart run over.
+func testChartsAfterMerge(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	job := NewChartJob(repo, chartPeriod, chartSize)
+
+	week := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) // a Monday
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: week, AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: week, AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: week, AmountPaid: 1})
+	if _, err := job.RunOnce(week.AddDate(0, 0, 8)); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if _, err := service.MergeTracks(2, 3); err != nil {
+		t.Fatalf("MergeTracks failed: %v", err)
+	}
+
+	chart, err := service.GetChart(0)
+	if err != nil {
+		t.Fatalf("GetChart failed: %v", err)
+	}
+	if len(chart.Entries) != 2 || chart.Entries[0].TrackID != 2 || chart.Entries[0].Title != "Comfortably Numb" || chart.Entries[0].Artist != "Pink Floyd" {
+		t.Errorf("Expected the merged duplicate to chart as Comfortably Numb, got %+v", chart.Entries)
+	}
+
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: week.AddDate(0, 0, 7), AmountPaid: 1})
+	if _, err := job.RunOnce(week.AddDate(0, 0, 15)); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	chart, _ = service.GetChart(0)
+	if len(chart.Entries) != 1 || chart.Entries[0].Movement != MovementSame || chart.Entries[0].PreviousPosition != 1 || chart.Entries[0].WeeksOnChart != 2 {
+		t.Errorf("Expected the canonical track to carry on the duplicate's run at number 1, got %+v", chart.Entries)
+	}
+}
+
+func TestHeatmapHalfHourZone(t *testing.T) {
+	testHeatmapHalfHourZone(t, NewInMemoryRepository())
+}
+
+// testHeatmapHalfHourZone checks that plays land in the local hour they were
+// played in when the zone is not a whole number of hours from UTC.
+func testHeatmapHalfHourZone(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	// Monday 18:45 UTC is Tuesday 00:15 in Kolkata; 18:20 UTC is still Monday.
+	monday := time.Date(2026, 7, 6, 18, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: monday.Add(45 * time.Minute), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: monday.Add(20 * time.Minute), AmountPaid: 1.50})
+
+	heatmap, err := service.GetHeatmap(HeatmapFilter{To: time.Now()}, "Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("GetHeatmap failed: %v", err)
+	}
+	if cell := heatmap.Cells[time.Tuesday][0]; cell.Plays != 1 || cell.Revenue != 1.25 {
+		t.Errorf("Expected one play on Tuesday at 00:00 Kolkata time, got %+v", cell)
+	}
+	if cell := heatmap.Cells[time.Monday][23]; cell.Plays != 1 || cell.Revenue != 1.50 {
+		t.Errorf("Expected one play on Monday at 23:00 Kolkata time, got %+v", cell)
+	}
+}
+
+func TestChartSnapshots(t *testing.T) {
+	testChartSnapshots(t, NewInMemoryRepository())
+}
+
+func testChartSnapshots(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	job := NewChartJob(repo, chartPeriod, chartSize)
+
+	week := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC) // a Monday
+	play := func(weeks, trackID, count int) {
+		for i := 0; i < count; i++ {
+			repo.CreateLog(PlaybackLog{TrackID: trackID, PlayedAt: week.AddDate(0, 0, 7*weeks), AmountPaid: 1})
+		}
+	}
+	play(0, 1, 3)
+	play(0, 2, 2)
+	play(1, 2, 3)
+	play(1, 1, 1)
+	play(1, 3, 1)
+	play(2, 3, 2)
+	play(3, 1, 1)
+
+	if saved, err := job.RunOnce(week.AddDate(0, 0, 8)); err != nil || saved != 1 {
+		t.Fatalf("Expected the first week to be charted, got %d: %v", saved, err)
+	}
+	if saved, err := job.RunOnce(week.AddDate(0, 0, 29)); err != nil || saved != 3 {
+		t.Fatalf("Expected the three missed weeks to be charted, got %d: %v", saved, err)
+	}
+
+	second, _ := service.GetChart(2)
+	want := []ChartEntry{
+		{Position: 1, TrackID: 2, PreviousPosition: 2, Movement: MovementUp, WeeksOnChart: 2, PeakPosition: 1},
+		{Position: 2, TrackID: 1, PreviousPosition: 1, Movement: MovementDown, WeeksOnChart: 2, PeakPosition: 1},
+		{Position: 3, TrackID: 3, Movement: MovementNew, WeeksOnChart: 1, PeakPosition: 3},
+	}
+	if len(second.Entries) != len(want) {
+		t.Fatalf("Expected %d entries, got %+v", len(want), second.Entries)
+	}
+	for i, w := range want {
+		got := second.Entries[i]
+		if got.Position != w.Position || got.TrackID != w.TrackID || got.PreviousPosition != w.PreviousPosition ||
+			got.Movement != w.Movement || got.WeeksOnChart != w.WeeksOnChart || got.PeakPosition != w.PeakPosition {
+			t.Errorf("Entry %d: expected %+v, got %+v", i, w, got)
+		}
+	}
+
+	latest, err := service.GetChart(0)
+	if err != nil {
+		t.Fatalf("GetChart failed: %v", err)
+	}
+	if !latest.PeriodStart.Equal(time.Date(2026, 6, 22, 0, 0, 0, 0, time.UTC)) || len(latest.Entries) != 1 {
+		t.Fatalf("Unexpected latest chart: %+v", latest)
+	}
+	if e := latest.Entries[0]; e.TrackID != 1 || e.Movement != MovementReEntry || e.PreviousPosition != 0 || e.WeeksOnChart != 3 || e.PeakPosition != 1 {
+		t.Errorf("Expected Dirty Diana to re-enter, got %+v", e)
+	}
+
+	if _, err := service.GetChart(99); !errors.Is(err, ChartNotFoundError) {
+		t.Errorf("Expected ChartNotFoundError, got %v", err)
+	}
+}
+
+func TestCustomerWallet(t *testing.T) {
+	testCustomerWallet(t, NewInMemoryRepository())
+}
+
+func testCustomerWallet(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	if err := service.SetLoyaltyRule(LoyaltyRule{FreeEvery: 3}); err != nil {
+		t.Fatalf("SetLoyaltyRule failed: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/customers/card-42/top-ups", bytes.NewBufferString(`{"amount": 3.00}`))
+	req.SetPathValue("id", "card-42")
+	w := httptest.NewRecorder()
+	handler.HandleTopUpCredit(w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200 for the top-up, got %d", w.Result().StatusCode)
+	}
+
+	logPlayback := func(body string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/logs", bytes.NewBufferString(body))
+		w := httptest.NewRecorder()
+		handler.HandleLogPlayback(w, req)
+		return w.Result().StatusCode
+	}
+	if status := logPlayback(`{"track_id": 1, "customer_id": "card-42", "pay_with_credit": true}`); status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	if status := logPlayback(`{"track_id": 2, "customer_id": "card-42", "pay_with_credit": true}`); status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	// The third play is paid in cash and earns its price back as credit.
+	if status := logPlayback(`{"track_id": 3, "amount_paid": 1.00, "customer_id": "card-42"}`); status != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", status)
+	}
+	if status := logPlayback(`{"track_id": 2, "customer_id": "card-42", "pay_with_credit": true}`); status != http.StatusPaymentRequired {
+		t.Errorf("Expected status 402 without enough credit, got %d", status)
+	}
+	if status := logPlayback(`{"track_id": 2, "pay_with_credit": true}`); status != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for credit without a customer, got %d", status)
+	}
+
+	customer, err := service.GetCustomer("card-42")
+	if err != nil {
+		t.Fatalf("GetCustomer failed: %v", err)
+	}
+	if customer.Plays != 3 || math.Abs(customer.Balance-1.25) > 1e-9 {
+		t.Errorf("Expected 3 plays and a balance of 1.25, got %+v", customer)
+	}
+
+	transactions, _ := service.GetWalletTransactions("card-42", 0, 0)
+	var kinds []string
+	for _, tx := range transactions {
+		kinds = append(kinds, tx.Kind)
+	}
+	if want := []string{WalletLoyalty, WalletDebit, WalletDebit, WalletTopUp}; !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected wallet history %v, got %v", want, kinds)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/customers/card-42/plays?limit=2", nil)
+	req.SetPathValue("id", "card-42")
+	w = httptest.NewRecorder()
+	handler.HandleGetCustomerLogs(w, req)
+	var plays []PlaybackLog
+	json.NewDecoder(w.Body).Decode(&plays)
+	if len(plays) != 2 || plays[0].TrackID != 3 || !plays[1].PaidWithCredit || plays[1].AmountPaid != 1.50 {
+		t.Errorf("Unexpected play history: %+v", plays)
+	}
+
+	if _, err := service.GetCustomerLogs("nobody", 0, 0); !errors.Is(err, CustomerNotFoundError) {
+		t.Errorf("Expected CustomerNotFoundError, got %v", err)
+	}
+
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(time.Now().Add(2 * logRetention)); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	if customer, _ := service.GetCustomer("card-42"); customer.Plays != 3 {
+		t.Errorf("Expected archived plays to keep counting towards loyalty, got %+v", customer)
+	}
+}
+
+func TestRoyaltyStatements(t *testing.T) {
+	testRoyaltyStatements(t, NewInMemoryRepository())
+}
+
+func testRoyaltyStatements(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	from := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: from.Add(time.Hour), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: from.Add(2 * time.Hour), AmountPaid: 1.25})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: from.Add(time.Hour), AmountPaid: 1.50})
+
+	if _, err := service.SaveRoyaltyRate(RoyaltyRate{ArtistID: 1, Rate: 0.1}); err != nil {
+		t.Fatalf("SaveRoyaltyRate failed: %v", err)
+	}
+	if _, err := service.SaveRoyaltyRate(RoyaltyRate{ArtistID: 1, TrackID: 1, Rate: 0.1}); !errors.Is(err, InvalidRoyaltyRate) {
+		t.Errorf("Expected InvalidRoyaltyRate for a rate with two targets, got %v", err)
+	}
+
+	statement, err := service.CloseRoyaltyStatement(1, from, to)
+	if err != nil {
+		t.Fatalf("CloseRoyaltyStatement failed: %v", err)
+	}
+	if statement.ID == 0 || statement.ClosedAt == nil || statement.Revenue != 2.50 || statement.Owed != 0.25 {
+		t.Errorf("Unexpected statement: %+v", statement)
+	}
+
+	// Raising the rate later must not change the closed statement.
+	service.SaveRoyaltyRate(RoyaltyRate{TrackID: 1, Rate: 0.5})
+	if _, err := service.CloseRoyaltyStatement(1, from.AddDate(0, 0, 14), to.AddDate(0, 0, 14)); !errors.Is(err, StatementOverlap) {
+		t.Errorf("Expected StatementOverlap, got %v", err)
+	}
+	if _, err := service.CloseRoyaltyStatement(1, to, time.Now().Add(time.Hour)); !errors.Is(err, InvalidStatementPeriod) {
+		t.Errorf("Expected InvalidStatementPeriod for an open period, got %v", err)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/royalties/statements/1?format=csv", nil)
+	req.SetPathValue("id", strconv.Itoa(statement.ID))
+	w := httptest.NewRecorder()
+	handler.HandleGetRoyaltyStatement(w, req)
+	want := "statement_id,artist_id,artist,period_start,period_end,track_id,title,plays,revenue,rate,owed\n" +
+		"1,1,Michael Jackson,2026-06-01T00:00:00Z,2026-07-01T00:00:00Z,1,Dirty Diana,2,2.50,0.1,0.25\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Unexpected CSV export:\n%s", got)
+	}
+
+	preview, _ := service.PreviewRoyaltyStatement(1, from, to)
+	if preview.ID != 0 || preview.Owed != 1.25 {
+		t.Errorf("Expected the preview to use the new track rate, got %+v", preview)
+	}
+}
+
+func TestRoyaltyStatementFromRollup(t *testing.T) {
+	testRoyaltyStatementFromRollup(t, NewInMemoryRepository())
+}
+
+// testRoyaltyStatementFromRollup checks that a statement includes revenue
+// from its first hour once that hour only survives as a rollup.
+func testRoyaltyStatementFromRollup(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SaveRoyaltyRate(RoyaltyRate{ArtistID: 1, Rate: 0.1})
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(0, 1, 0)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: from.Add(10 * time.Minute), AmountPaid: 2})
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(to.Add(logRetention)); err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged log, got %d, %v", purged, err)
+	}
+
+	previous, err := service.CloseRoyaltyStatement(1, from.AddDate(0, -1, 0), from)
+	if err != nil {
+		t.Fatalf("CloseRoyaltyStatement failed: %v", err)
+	}
+	statement, err := service.CloseRoyaltyStatement(1, from, to)
+	if err != nil {
+		t.Fatalf("CloseRoyaltyStatement failed: %v", err)
+	}
+	if previous.Revenue != 0 || statement.Revenue != 2 || statement.Owed != 0.2 {
+		t.Errorf("Expected the first hour's revenue in January only, got %+v and %+v", previous, statement)
+	}
+}
+
+func TestUsageReport(t *testing.T) {
+	testUsageReport(t, NewInMemoryRepository())
+}
+
+func testUsageReport(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	track, _ := repo.GetTrackByID(1)
+	track.ISRC = "QZTST2600001"
+	repo.UpdateTrackMetadata(*track)
+	service.SaveVenue(Venue{ID: "bar", Name: "The Bar", TimeZone: "Europe/Kyiv"})
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "bar", LastSeenAt: time.Now()})
+
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: time.Date(2026, 6, 30, 10, 0, 0, 0, time.UTC), DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: time.Date(2026, 6, 30, 11, 0, 0, 0, time.UTC), DeviceID: "jb-1"})
+	// 22:30 UTC is already July 1st in Kyiv.
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: time.Date(2026, 6, 30, 22, 30, 0, 0, time.UTC), DeviceID: "jb-1"})
+	voided, _ := repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC), DeviceID: "jb-1"})
+	repo.VoidLog(voided.ID, "song failed to play", 0, time.Now())
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC), DeviceID: "jb-2"})
+
+	report, err := service.GetUsageReport("bar", "2026-06-30", "2026-06-30")
+	if err != nil {
+		t.Fatalf("GetUsageReport failed: %v", err)
+	}
+	if len(report.Rows) != 1 || report.Rows[0].TrackID != 1 || report.Rows[0].Plays != 2 {
+		t.Errorf("Expected only Dirty Diana's two plays on June 30th, got %+v", report.Rows)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/reports/usage?venue=bar&from=2026-06-30&to=2026-07-01&columns=date,isrc:ISRC,title:Work%20Title,plays:Performances&delimiter=%3B", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetUsageReport(w, req)
+	want := "date;ISRC;Work Title;Performances\n" +
+		"2026-06-30;QZTST2600001;Dirty Diana;2\n" +
+		"2026-07-01;;Comfortably Numb;1\n"
+	if got := w.Body.String(); got != want {
+		t.Errorf("Unexpected usage file:\n%s", got)
+	}
+	if got := w.Header().Get("Content-Disposition"); got != "attachment; filename=usage_bar_2026-06-30_2026-07-01.csv" {
+		t.Errorf("Unexpected Content-Disposition %q", got)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/reports/usage?venue=bar&from=2026-06-30&to=2026-06-30&columns=isrc,label", nil)
+	w = httptest.NewRecorder()
+	handler.HandleGetUsageReport(w, req)
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown column, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestUsageReportHalfHourZone(t *testing.T) {
+	testUsageReportHalfHourZone(t, NewInMemoryRepository())
+}
+
+// testUsageReportHalfHourZone checks that plays count on their local date in
+// a zone offset by half an hour, and that rolled up periods are refused.
+func testUsageReportHalfHourZone(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SaveVenue(Venue{ID: "bar", Name: "The Bar", TimeZone: "Asia/Kolkata"})
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "bar", LastSeenAt: time.Now()})
+
+	// 18:20 UTC is 23:50 in Kolkata and 18:45 UTC is already the next day.
+	evening := time.Now().UTC().AddDate(0, 0, -7).Truncate(24 * time.Hour).Add(18 * time.Hour)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: evening.Add(20 * time.Minute), DeviceID: "jb-1"})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: evening.Add(45 * time.Minute), DeviceID: "jb-1"})
+
+	kolkata, _ := time.LoadLocation("Asia/Kolkata")
+	day := evening.In(kolkata).Format(time.DateOnly)
+	next := evening.Add(time.Hour).In(kolkata).Format(time.DateOnly)
+	report, err := service.GetUsageReport("bar", day, next)
+	if err != nil {
+		t.Fatalf("GetUsageReport failed: %v", err)
+	}
+	if len(report.Rows) != 2 || report.Rows[0].Date != day || report.Rows[0].Plays != 1 || report.Rows[1].Date != next || report.Rows[1].Plays != 1 {
+		t.Errorf("Expected one play on %s and one on %s, got %+v", day, next, report.Rows)
+	}
+
+	old := time.Now().Add(-2 * logRetention)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: old, DeviceID: "jb-1"})
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(time.Now()); err != nil {
+		t.Fatalf("RunOnce failed: %v", err)
+	}
+	date := old.In(kolkata).Format(time.DateOnly)
+	if _, err := service.GetUsageReport("bar", date, date); !errors.Is(err, UsagePeriodPurged) {
+		t.Errorf("Expected UsagePeriodPurged for a rolled up period, got %v", err)
+	}
+}
+
+func TestUsageReportPurgedFirstHour(t *testing.T) {
+	testUsageReportPurgedFirstHour(t, NewInMemoryRepository())
+}
+
+// testUsageReportPurgedFirstHour checks that a period is refused when only
+// its first hour has been rolled up.
+func testUsageReportPurgedFirstHour(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SaveVenue(Venue{ID: "bar", Name: "The Bar", TimeZone: "UTC"})
+	repo.RecordHeartbeat(Device{DeviceID: "jb-1", VenueID: "bar", LastSeenAt: time.Now()})
+
+	day := time.Now().UTC().Add(-2 * logRetention).Truncate(24 * time.Hour)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day.Add(10 * time.Minute), DeviceID: "jb-1"})
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(time.Now()); err != nil || purged != 1 {
+		t.Fatalf("Expected 1 purged log, got %d, %v", purged, err)
+	}
+
+	date := day.Format(time.DateOnly)
+	if _, err := service.GetUsageReport("bar", date, date); !errors.Is(err, UsagePeriodPurged) {
+		t.Errorf("Expected UsagePeriodPurged when the first hour is rolled up, got %v", err)
+	}
+}
+
+func TestTrackExternalIDs(t *testing.T) {
+	testTrackExternalIDs(t, NewInMemoryRepository())
+}
+
+func testTrackExternalIDs(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+	service.SetTrackDuration(2, 383)
+
+	req := httptest.NewRequest(http.MethodPatch, "/api/v1/tracks/2/metadata",
+		bytes.NewBufferString(`{"isrc": "gb-ajy-79-00001", "album": "The Wall", "release_year": 1979, "tags": ["Rock", "progressive rock", " rock"]}`))
+	req.SetPathValue("id", "2")
+	w := httptest.NewRecorder()
+	handler.HandleUpdateTrackMetadata(w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	track, _ := repo.GetTrackByID(2)
+	if track.ISRC != "GBAJY7900001" || track.Album != "The Wall" || track.ReleaseYear != 1979 || track.DurationSeconds != 383 {
+		t.Errorf("Unexpected metadata %+v", track)
+	}
+	if !reflect.DeepEqual(track.Tags, []string{"progressive rock", "rock"}) {
+		t.Errorf("Expected normalized tags, got %v", track.Tags)
+	}
+
+	isrc := "GBAJY7900001"
+	if _, err := service.UpdateTrackMetadata(1, TrackMetadata{ISRC: &isrc}); !errors.Is(err, ExternalIDInUse) {
+		t.Errorf("Expected ExternalIDInUse for a duplicate ISRC, got %v", err)
+	}
+	isrc = "not-an-isrc"
+	if _, err := service.UpdateTrackMetadata(1, TrackMetadata{ISRC: &isrc}); !errors.Is(err, InvalidISRC) {
+		t.Errorf("Expected InvalidISRC, got %v", err)
+	}
+
+	if _, err := service.SetExternalID(3, "Label", "EMI-1969-042"); err != nil {
+		t.Fatalf("SetExternalID failed: %v", err)
+	}
+	if _, err := service.SetExternalID(1, "label", "EMI-1969-042"); !errors.Is(err, ExternalIDInUse) {
+		t.Errorf("Expected ExternalIDInUse for an id mapped to another track, got %v", err)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/tracks/lookup?source=isrc&id=GB-AJY-79-00001", nil)
+	w = httptest.NewRecorder()
+	handler.HandleLookupTrack(w, req)
+	var found Track
+	json.NewDecoder(w.Body).Decode(&found)
+	if found.ID != 2 {
+		t.Errorf("Expected ISRC lookup to find track 2, got %+v", found)
+	}
+	if track, err := service.LookupTrack("label", "EMI-1969-042"); err != nil || track.ID != 3 {
+		t.Errorf("Expected partner id lookup to find track 3, got %+v, %v", track, err)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/logs",
+		bytes.NewBufferString(`{"external_source": "label", "external_id": "EMI-1969-042", "amount_paid": 1.00}`))
+	w = httptest.NewRecorder()
+	handler.HandleLogPlayback(w, req)
+	if w.Result().StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	if logs := repo.GetAllLogs(); len(logs) != 1 || logs[0].TrackID != 3 {
+		t.Errorf("Expected the play to be logged against track 3, got %+v", logs)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/logs",
+		bytes.NewBufferString(`{"external_source": "label", "external_id": "EMI-0000", "amount_paid": 1.00}`))
+	w = httptest.NewRecorder()
+	handler.HandleLogPlayback(w, req)
+	if w.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown external id, got %d", w.Result().StatusCode)
+	}
+}
+
+func TestImportCatalog(t *testing.T) {
+	testImportCatalog(t, NewInMemoryRepository())
+}
+
+func testImportCatalog(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+	service.SetExternalID(3, "label", "EMI-1969-042")
+
+	catalog := "title,artist,price,isrc,tags,id:label\n" +
+		"COMFORTABLY NUMB,Pink Floyd,1.75,GBAJY7900001,Rock|Prog,\n" +
+		"Space Oddity (2015 Remaster),David Bowie,,,,EMI-1969-042\n" +
+		"Heroes,david bowie,1.10,,rock,\n" +
+		"Dirty Diana,Michael Jackson,,,,\n"
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/catalog/import?format=csv&dry_run=true", bytes.NewBufferString(catalog))
+	w := httptest.NewRecorder()
+	handler.HandleImportCatalog(w, req)
+	var report CatalogImport
+	json.NewDecoder(w.Body).Decode(&report)
+	if report.Created != 1 || report.Updated != 2 || report.Unchanged != 1 || !report.DryRun {
+		t.Fatalf("Unexpected dry-run report %+v", report)
+	}
+	if change := report.Changes[0]; change.TrackID != 2 || change.MatchedBy != matchedByTitleArtist || len(change.Fields) != 3 {
+		t.Errorf("Expected track 2 matched by title and artist with price, isrc and tags changed, got %+v", change)
+	}
+	if change := report.Changes[1]; change.TrackID != 3 || change.MatchedBy != "label" {
+		t.Errorf("Expected track 3 matched by its label id, got %+v", change)
+	}
+	if tracks, _ := repo.GetTracks(); len(tracks) != 3 {
+		t.Fatalf("Expected a dry run to write nothing, got %d tracks", len(tracks))
+	}
+
+	report2, err := service.ImportCatalog(mustParseCatalog(t, catalog), false)
+	if err != nil {
+		t.Fatalf("ImportCatalog failed: %v", err)
+	}
+	if report2.Changes[2].Action != CatalogCreate || report2.Changes[2].TrackID != 4 {
+		t.Errorf("Expected Heroes to be created as track 4, got %+v", report2.Changes[2])
+	}
+	track, _ := repo.GetTrackByID(2)
+	if track.Title != "Comfortably Numb" || track.Price != 1.75 || track.ISRC != "GBAJY7900001" || !reflect.DeepEqual(track.Tags, []string{"prog", "rock"}) {
+		t.Errorf("Unexpected imported track %+v", track)
+	}
+	if heroes, _ := repo.GetTrackByID(4); heroes.Artist != "David Bowie" || heroes.ArtistID != 3 {
+		t.Errorf("Expected Heroes to be credited to the existing artist, got %+v", heroes)
+	}
+	if changes, _ := repo.GetPriceChanges(10); len(changes) != 1 || changes[0].Source != PriceSourceImport {
+		t.Errorf("Expected the price change to be recorded, got %+v", changes)
+	}
+
+	if again, _ := service.ImportCatalog(mustParseCatalog(t, catalog), false); again.Unchanged != 4 {
+		t.Errorf("Expected re-importing the catalog to change nothing, got %+v", again)
+	}
+
+	invalid := `[{"title": "Heroes", "artist": "David Bowie", "isrc": "GBAJY7900001", "external_ids": {"label": "EMI-1969-042"}}, {"title": "New", "artist": "Someone"}]`
+	req = httptest.NewRequest(http.MethodPost, "/api/v1/admin/catalog/import", bytes.NewBufferString(invalid))
+	w = httptest.NewRecorder()
+	handler.HandleImportCatalog(w, req)
+	if w.Result().StatusCode != http.StatusUnprocessableEntity {
+		t.Fatalf("Expected status 422, got %d", w.Result().StatusCode)
+	}
+	json.NewDecoder(w.Body).Decode(&report)
+	if len(report.Errors) != 2 {
+		t.Errorf("Expected both rows to be rejected, got %+v", report.Errors)
+	}
+	if tracks, _ := repo.GetTracks(); len(tracks) != 4 {
+		t.Errorf("Expected an invalid catalog to write nothing, got %d tracks", len(tracks))
+	}
+}
+
+func TestImportCatalogRace(t *testing.T) {
+	testImportCatalogRace(t, NewInMemoryRepository())
+}
+
+// testImportCatalogRace checks that a plan the catalog changed under writes
+// nothing, and that the service plans again.
+func testImportCatalogRace(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	catalog := mustParseCatalog(t, "title,artist,price,isrc\n"+
+		"Dirty Diana,Michael Jackson,2.00,\n"+
+		"Heroes,David Bowie,1.10,USRC17607839\n")
+
+	stale := func(race func()) {
+		t.Helper()
+		_, upserts, err := service.planCatalogImport(catalog, time.Now())
+		if err != nil || len(upserts) != 2 {
+			t.Fatalf("Expected 2 planned upserts, got %+v, %v", upserts, err)
+		}
+		race()
+		tracks, _ := repo.GetTracks()
+		if _, err := repo.ImportCatalog(upserts, time.Now()); !errors.Is(err, CatalogChanged) {
+			t.Errorf("Expected CatalogChanged, got %v", err)
+		}
+		if after, _ := repo.GetTracks(); !reflect.DeepEqual(after, tracks) {
+			t.Errorf("Expected a stale plan to write nothing, got %+v", after)
+		}
+	}
+	stale(func() { service.UpdatePrice(1, 1.75) })
+	stale(func() {
+		repo.ImportCatalog([]CatalogUpsert{{Row: 1, Track: Track{Title: "heroes", Artist: "David Bowie", Price: 1}}}, time.Now())
+	})
+	stale(func() {
+		isrc := "USRC17607839"
+		service.UpdateTrackMetadata(2, TrackMetadata{ISRC: &isrc})
+	})
+
+	report, err := service.ImportCatalog(catalog, false)
+	if err != nil || report.Errors != nil {
+		t.Fatalf("ImportCatalog failed: %+v, %v", report, err)
+	}
+	if track, _ := repo.GetTrackByID(1); track.Price != 2.00 {
+		t.Errorf("Expected the replanned import to reprice track 1, got %+v", track)
+	}
+}
+
+func mustParseCatalog(t *testing.T, catalog string) []CatalogEntry {
+	t.Helper()
+	entries, err := parseCatalog(bytes.NewBufferString(catalog), CatalogFormatCSV)
+	if err != nil {
+		t.Fatalf("parseCatalog failed: %v", err)
+	}
+	return entries
+}
+
+func TestDuplicateTracksMerge(t *testing.T) {
+	testDuplicateTracksMerge(t, NewInMemoryRepository())
+}
+
+func testDuplicateTracksMerge(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	service.ImportCatalog([]CatalogEntry{
+		{Title: "Comfortably Numb (Remastered)", Artist: "Pink Floyd", Price: 2, ISRC: "GBAJY1100001", ExternalIDs: map[string]string{"label": "EMI-1"}},
+		{Title: "Dirty Diana - Single Version", Artist: "michael jackson", Price: 1},
+	}, false)
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: time.Now(), AmountPaid: 1.5})
+	repo.CreateLog(PlaybackLog{TrackID: 4, PlayedAt: time.Now(), AmountPaid: 2})
+	repo.CreateLog(PlaybackLog{TrackID: 4, PlayedAt: time.Now(), AmountPaid: 2})
+	repo.RecordPriceChange(PriceChange{TrackID: 4, Source: PriceSourceSchedule, OldPrice: 2, NewPrice: 2.5, ChangedAt: time.Now()})
+
+	duplicates, err := service.FindDuplicateTracks(0)
+	if err != nil {
+		t.Fatalf("FindDuplicateTracks failed: %v", err)
+	}
+	if len(duplicates) != 2 {
+		t.Fatalf("Expected 2 duplicate pairs, got %+v", duplicates)
+	}
+	if duplicates[0].Canonical.ID != 1 || duplicates[0].Duplicate.ID != 5 || duplicates[0].Score != 1 {
+		t.Errorf("Expected Dirty Diana to be suggested as canonical, got %+v", duplicates[0])
+	}
+	if duplicates[1].Canonical.ID != 4 || duplicates[1].Duplicate.ID != 2 {
+		t.Errorf("Expected the remaster with more plays to be suggested as canonical, got %+v", duplicates[1])
+	}
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/tracks/2/merge", bytes.NewBufferString(`{"duplicate_id": 4}`))
+	req.SetPathValue("id", "2")
+	w := httptest.NewRecorder()
+	handler.HandleMergeTracks(w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+
+	for _, log := range repo.GetAllLogs() {
+		if log.TrackID != 2 {
+			t.Errorf("Expected every play to be reassigned to track 2, got %+v", log)
+		}
+	}
+	if changes, _ := repo.GetPriceChanges(10); len(changes) != 1 || changes[0].TrackID != 2 {
+		t.Errorf("Expected the price history to move to track 2, got %+v", changes)
+	}
+	track, err := repo.GetTrackByID(4)
+	if err != nil || track.ID != 2 || track.ISRC != "GBAJY1100001" {
+		t.Errorf("Expected the old id to resolve to track 2 with the duplicate's ISRC, got %+v, %v", track, err)
+	}
+	if track, err := service.LookupTrack("label", "EMI-1"); err != nil || track.ID != 2 {
+		t.Errorf("Expected the partner id to move to track 2, got %+v, %v", track, err)
+	}
+	if tracks, _ := repo.GetTracks(); len(tracks) != 4 {
+		t.Errorf("Expected the duplicate to be removed, got %d tracks", len(tracks))
+	}
+
+	if err := service.CreateLog(PlaybackLog{TrackID: 4, AmountPaid: 2}); err != nil {
+		t.Fatalf("Expected logging against a merged id to succeed: %v", err)
+	}
+	if logs := repo.GetAllLogs(); logs[len(logs)-1].TrackID != 2 {
+		t.Errorf("Expected the play to be logged against track 2, got %+v", logs[len(logs)-1])
+	}
+
+	isrc := "GBAJY1100001"
+	if track, err := service.UpdateTrackMetadata(4, TrackMetadata{ISRC: &isrc}); err != nil || track.ID != 2 {
+		t.Errorf("Expected a merged id to update track 2 with its own ISRC, got %+v, %v", track, err)
+	}
+	if err := service.UpdatePrice(4, 3.00); err != nil {
+		t.Fatalf("Expected repricing a merged id to succeed: %v", err)
+	}
+	if track, _ := repo.GetTrackByID(2); track.Price != 3.00 {
+		t.Errorf("Expected the price of track 2 to change, got %+v", track)
+	}
+
+	if _, err := service.MergeTracks(4, 2); !errors.Is(err, InvalidMerge) {
+		t.Errorf("Expected InvalidMerge when merging a track into its own old id, got %v", err)
+	}
+}
+
+func TestTagAnalytics(t *testing.T) {
+	testTagAnalytics(t, NewInMemoryRepository())
+}
+
+func testTagAnalytics(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	if _, err := service.TagTrack(1, " Pop "); err != nil {
+		t.Fatalf("TagTrack failed: %v", err)
+	}
+	service.TagTrack(2, "rock")
+	service.TagTrack(3, "rock")
+	if _, err := service.CreateTag("ROCK"); !errors.Is(err, TagExists) {
+		t.Errorf("Expected TagExists for a tag differing only in case, got %v", err)
+	}
+
+	friday := time.Date(2026, 7, 3, 21, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: friday, AmountPaid: 3})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: friday, AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: friday, AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 3, PlayedAt: friday.Add(24 * time.Hour), AmountPaid: 5})
+
+	top, err := service.GetTagTopTracks("Rock")
+	if err != nil {
+		t.Fatalf("GetTagTopTracks failed: %v", err)
+	}
+	if len(top) != 2 || top[0].Title != "Comfortably Numb" || top[0].Count != 2 {
+		t.Errorf("Expected only rock tracks with Comfortably Numb first, got %+v", top)
+	}
+
+	stats, err := service.GetTagStats(HeatmapFilter{To: time.Now()}, "", int(time.Friday), -1)
+	if err != nil {
+		t.Fatalf("GetTagStats failed: %v", err)
+	}
+	if stats.TotalRevenue != 5 || len(stats.Tags) != 2 {
+		t.Fatalf("Expected Friday revenue of 5 split over two tags, got %+v", stats)
+	}
+	if stats.Tags[0].Tag != "pop" || stats.Tags[0].RevenueShare != 0.6 || stats.Tags[1].Tag != "rock" || stats.Tags[1].RevenueShare != 0.4 {
+		t.Errorf("Expected pop to earn 60%% and rock 40%% of Friday revenue, got %+v", stats.Tags)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/stats/heatmap?tag=rock", nil)
+	w := httptest.NewRecorder()
+	handler.HandleGetHeatmap(w, req)
+	var heatmap Heatmap
+	json.NewDecoder(w.Body).Decode(&heatmap)
+	if heatmap.Cells[time.Friday][21].Plays != 2 || heatmap.Cells[time.Saturday][21].Plays != 1 {
+		t.Errorf("Expected the heatmap to only count rock plays, got %+v", heatmap.Cells)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/v1/stats/top?tag=jazz", nil)
+	w = httptest.NewRecorder()
+	handler.HandleGetTopTracks(w, req)
+	if w.Result().StatusCode != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an unknown tag, got %d", w.Result().StatusCode)
+	}
+
+	rock, _ := service.getTag("rock")
+	if _, err := service.RenameTag(rock.ID, "Classic Rock"); err != nil {
+		t.Fatalf("RenameTag failed: %v", err)
+	}
+	if track, _ := repo.GetTrackByID(2); !reflect.DeepEqual(track.Tags, []string{"classic rock"}) {
+		t.Errorf("Expected the rename to apply to tracks, got %v", track.Tags)
+	}
+	if err := service.UntagTrack(1, "pop"); err != nil {
+		t.Fatalf("UntagTrack failed: %v", err)
+	}
+	if err := service.DeleteTag(rock.ID); err != nil {
+		t.Fatalf("DeleteTag failed: %v", err)
+	}
+	tags, _ := service.GetTags()
+	if len(tags) != 1 || tags[0].Name != "pop" || tags[0].Tracks != 0 {
+		t.Errorf("Expected only the unused pop tag to remain, got %+v", tags)
+	}
+	if track, _ := repo.GetTrackByID(3); len(track.Tags) != 0 {
+		t.Errorf("Expected deleting a tag to untag its tracks, got %v", track.Tags)
+	}
+}
+
+func TestTagStatsHalfHourZone(t *testing.T) {
+	testTagStatsHalfHourZone(t, NewInMemoryRepository())
+}
+
+// testTagStatsHalfHourZone checks that a weekday and hour slot only counts
+// plays from that local hour when the zone is offset by half an hour.
+func testTagStatsHalfHourZone(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.TagTrack(1, "pop")
+	service.TagTrack(2, "rock")
+	// Monday 18:45 UTC is Tuesday 00:15 in Kolkata; 18:20 UTC is still Monday.
+	monday := time.Date(2026, 7, 6, 18, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: monday.Add(45 * time.Minute), AmountPaid: 3})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: monday.Add(20 * time.Minute), AmountPaid: 1})
+
+	stats, err := service.GetTagStats(HeatmapFilter{To: time.Now()}, "Asia/Kolkata", int(time.Tuesday), 0)
+	if err != nil {
+		t.Fatalf("GetTagStats failed: %v", err)
+	}
+	if stats.TotalRevenue != 3 || len(stats.Tags) != 1 || stats.Tags[0].Tag != "pop" || stats.Tags[0].RevenueShare != 1 {
+		t.Errorf("Expected only the pop play on Tuesday at 00:00 Kolkata time, got %+v", stats)
+	}
+}
+
+func TestTrackStats(t *testing.T) {
+	testTrackStats(t, NewInMemoryRepository())
+}
+
+func testTrackStats(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	handler := NewHandler(service)
+
+	day := time.Date(2026, 7, 3, 22, 30, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day, AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: day.Add(2 * time.Hour), AmountPaid: 2})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: day.AddDate(0, 0, 2), AmountPaid: 1.5})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: day.AddDate(0, 0, 2), AmountPaid: 1.5})
+	repo.CreateLog(PlaybackLog{TrackID: 2, PlayedAt: day.AddDate(0, 0, 2), AmountPaid: 1.5})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/tracks/1/stats", nil)
+	req.SetPathValue("id", "1")
+	w := httptest.NewRecorder()
+	handler.HandleGetTrackStats(w, req)
+	if w.Result().StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d: %s", w.Result().StatusCode, w.Body.String())
+	}
+	var stats TrackStats
+	json.NewDecoder(w.Body).Decode(&stats)
+	if stats.Plays != 2 || stats.Revenue != 3 || stats.AveragePaid != 1.5 || stats.Rank == nil || *stats.Rank != 2 {
+		t.Errorf("Expected 2 plays earning 3 at rank 2, got %+v", stats)
+	}
+	if !stats.FirstPlayedAt.Equal(day) || !stats.LastPlayedAt.Equal(day.Add(2*time.Hour)) {
+		t.Errorf("Expected plays between %v and %v, got %v and %v", day, day.Add(2*time.Hour), stats.FirstPlayedAt, stats.LastPlayedAt)
+	}
+	if len(stats.Days) != 2 || stats.Days[0] != (DailyPlays{Date: "2026-07-03", Plays: 1, Revenue: 1}) || stats.Days[1].Plays != 1 {
+		t.Errorf("Expected one play on each of two UTC days, got %+v", stats.Days)
+	}
+
+	local, err := service.GetTrackStats(1, "Europe/Kyiv")
+	if err != nil {
+		t.Fatalf("GetTrackStats failed: %v", err)
+	}
+	if len(local.Days) != 1 || local.Days[0].Date != "2026-07-04" || local.Days[0].Plays != 2 {
+		t.Errorf("Expected both plays on the same Kyiv day, got %+v", local.Days)
+	}
+
+	unplayed, err := service.GetTrackStats(3, "")
+	if err != nil {
+		t.Fatalf("GetTrackStats failed: %v", err)
+	}
+	if unplayed.Rank != nil || unplayed.FirstPlayedAt != nil || len(unplayed.Days) != 0 {
+		t.Errorf("Expected no rank or plays for an unplayed track, got %+v", unplayed)
+	}
+
+	if _, err := service.GetTrackStats(99, ""); !errors.Is(err, TrackNotFoundError) {
+		t.Errorf("Expected TrackNotFoundError, got %v", err)
+	}
+}
+
+func TestTrackStatsHalfHourZone(t *testing.T) {
+	testTrackStatsHalfHourZone(t, NewInMemoryRepository())
+}
+
+// testTrackStatsHalfHourZone checks that plays either side of local midnight
+// count on their own day when the zone is offset by half an hour.
+func testTrackStatsHalfHourZone(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	// Monday 18:20 UTC is 23:50 in Kolkata and 18:45 UTC is Tuesday 00:15.
+	monday := time.Date(2026, 7, 6, 18, 0, 0, 0, time.UTC)
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: monday.Add(20 * time.Minute), AmountPaid: 1})
+	repo.CreateLog(PlaybackLog{TrackID: 1, PlayedAt: monday.Add(45 * time.Minute), AmountPaid: 2})
+
+	stats, err := service.GetTrackStats(1, "Asia/Kolkata")
+	if err != nil {
+		t.Fatalf("GetTrackStats failed: %v", err)
+	}
+	expected := []DailyPlays{
+		{Date: "2026-07-06", Plays: 1, Revenue: 1},
+		{Date: "2026-07-07", Plays: 1, Revenue: 2},
+	}
+	if !reflect.DeepEqual(stats.Days, expected) {
+		t.Errorf("Expected one play on each Kolkata day.\nExpected: %+v\nGot:      %+v", expected, stats.Days)
+	}
+}
+
+func TestVoidCreditPlay(t *testing.T) {
+	testVoidCreditPlay(t, NewInMemoryRepository())
+}
+
+// testVoidCreditPlay checks that voiding a customer's play gives back the
+// credit it cost and the loyalty reward it earned.
+func testVoidCreditPlay(t *testing.T, repo IRepository) {
+	service := NewService(repo)
+	service.SetLoyaltyRule(LoyaltyRule{FreeEvery: 2})
+	service.TopUpCredit("card-42", 5)
+	service.CreateLog(PlaybackLog{TrackID: 1, CustomerID: "card-42", PaidWithCredit: true})
+	service.CreateLog(PlaybackLog{TrackID: 2, CustomerID: "card-42", PaidWithCredit: true})
+
+	logs, _ := service.GetCustomerLogs("card-42", 0, 0)
+	if _, err := service.VoidLog(logs[0].ID, "song failed to play", nil); err != nil {
+		t.Fatalf("VoidLog failed: %v", err)
+	}
+	customer, _ := service.GetCustomer("card-42")
+	if customer.Plays != 1 || math.Abs(customer.Balance-3.75) > 1e-9 {
+		t.Errorf("Expected the refund and the reversed reward to leave 1 play and 3.75, got %+v", customer)
+	}
+
+	// Replaying the voided play earns the reward once more, not twice.
+	service.CreateLog(PlaybackLog{TrackID: 2, CustomerID: "card-42", PaidWithCredit: true})
+	customer, _ = service.GetCustomer("card-42")
+	if customer.Plays != 2 || math.Abs(customer.Balance-3.75) > 1e-9 {
+		t.Errorf("Expected 2 plays and a balance of 3.75 after the replay, got %+v", customer)
+	}
+
+	transactions, _ := service.GetWalletTransactions("card-42", 0, 0)
+	var kinds []string
+	for _, tx := range transactions {
+		kinds = append(kinds, tx.Kind)
+	}
+	want := []string{WalletLoyalty, WalletDebit, WalletLoyaltyReversal, WalletRefund, WalletLoyalty, WalletDebit, WalletDebit, WalletTopUp}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Errorf("Expected wallet history %v, got %v", want, kinds)
+	}
+}
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS playback_rollups (
+    track_id INTEGER NOT NULL,
+    period_start DATETIME NOT NULL,
+    play_count INTEGER NOT NULL,
+    revenue REAL NOT NULL,
+    PRIMARY KEY (track_id, period_start),
+    FOREIGN KEY(track_id) REFERENCES tracks(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playback_logs_played_at ON playback_logs(played_at);
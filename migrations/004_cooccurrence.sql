@@ -0,0 +1,19 @@
+ALTER TABLE playback_logs ADD COLUMN device_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE playback_logs ADD COLUMN session_id TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_playback_logs_device ON playback_logs(device_id, id);
+CREATE INDEX IF NOT EXISTS idx_playback_logs_session ON playback_logs(session_id, id);
+
+CREATE TABLE IF NOT EXISTS track_cooccurrences (
+    track_id INTEGER NOT NULL,
+    related_track_id INTEGER NOT NULL,
+    count INTEGER NOT NULL,
+    PRIMARY KEY (track_id, related_track_id),
+    FOREIGN KEY(track_id) REFERENCES tracks(id),
+    FOREIGN KEY(related_track_id) REFERENCES tracks(id)
+);
+
+CREATE TABLE IF NOT EXISTS job_cursors (
+    name TEXT PRIMARY KEY,
+    last_id INTEGER NOT NULL
+);
@@ -0,0 +1,32 @@
+CREATE TABLE IF NOT EXISTS pricing_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    target TEXT NOT NULL,
+    track_count INTEGER NOT NULL,
+    adjust_percent REAL NOT NULL,
+    window_hours INTEGER NOT NULL,
+    cooldown_hours INTEGER NOT NULL,
+    enabled INTEGER NOT NULL DEFAULT 1,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS price_bounds (
+    track_id INTEGER PRIMARY KEY,
+    floor REAL NOT NULL DEFAULT 0,
+    ceiling REAL NOT NULL DEFAULT 0,
+    FOREIGN KEY(track_id) REFERENCES tracks(id)
+);
+
+CREATE TABLE IF NOT EXISTS price_changes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    track_id INTEGER NOT NULL,
+    rule_id INTEGER,
+    source TEXT NOT NULL,
+    old_price REAL NOT NULL,
+    new_price REAL NOT NULL,
+    reason TEXT NOT NULL,
+    changed_at DATETIME NOT NULL,
+    FOREIGN KEY(track_id) REFERENCES tracks(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_changes_track ON price_changes(track_id, changed_at);
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    track_id INTEGER NOT NULL,
+    price REAL NOT NULL,
+    starts_at DATETIME NOT NULL,
+    ends_at DATETIME,
+    status TEXT NOT NULL,
+    previous_price REAL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY(track_id) REFERENCES tracks(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_status ON scheduled_prices(status, starts_at);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS time_price_rules (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    track_id INTEGER,
+    artist TEXT NOT NULL DEFAULT '',
+    weekdays TEXT NOT NULL DEFAULT '',
+    start_time TEXT NOT NULL,
+    end_time TEXT NOT NULL,
+    price REAL NOT NULL DEFAULT 0,
+    adjust_percent REAL NOT NULL DEFAULT 0,
+    time_zone TEXT NOT NULL,
+    created_at DATETIME NOT NULL,
+    FOREIGN KEY(track_id) REFERENCES tracks(id)
+);
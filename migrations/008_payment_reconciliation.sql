@@ -0,0 +1,7 @@
+ALTER TABLE playback_logs ADD COLUMN list_price REAL NOT NULL DEFAULT 0;
+ALTER TABLE playback_logs ADD COLUMN payment_status TEXT NOT NULL DEFAULT '';
+
+CREATE TABLE IF NOT EXISTS settings (
+    key TEXT PRIMARY KEY,
+    value TEXT NOT NULL
+);
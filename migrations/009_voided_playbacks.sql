@@ -0,0 +1,3 @@
+ALTER TABLE playback_logs ADD COLUMN voided_at DATETIME;
+ALTER TABLE playback_logs ADD COLUMN void_reason TEXT NOT NULL DEFAULT '';
+ALTER TABLE playback_logs ADD COLUMN refunded_amount REAL NOT NULL DEFAULT 0;
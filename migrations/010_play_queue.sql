@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS play_queue (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id TEXT NOT NULL,
+    session_id TEXT NOT NULL DEFAULT '',
+    track_id INTEGER NOT NULL,
+    amount_paid REAL NOT NULL,
+    list_price REAL NOT NULL,
+    payment_status TEXT NOT NULL,
+    status TEXT NOT NULL,
+    log_id INTEGER,
+    enqueued_at DATETIME NOT NULL,
+    started_at DATETIME,
+    finished_at DATETIME,
+    FOREIGN KEY(track_id) REFERENCES tracks(id),
+    FOREIGN KEY(log_id) REFERENCES playback_logs(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_play_queue_device ON play_queue(device_id, status, id);
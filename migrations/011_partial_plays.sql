@@ -0,0 +1,7 @@
+-- 0 means the duration is unknown; set it with PUT /api/v1/tracks/{id}/duration
+-- or a catalog import.
+ALTER TABLE tracks ADD COLUMN duration_seconds INTEGER NOT NULL DEFAULT 0;
+
+ALTER TABLE playback_logs ADD COLUMN completed_at DATETIME;
+ALTER TABLE playback_logs ADD COLUMN listened_seconds INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE playback_logs ADD COLUMN skipped BOOLEAN NOT NULL DEFAULT 0;
@@ -0,0 +1,26 @@
+ALTER TABLE playback_logs ADD COLUMN quarantined BOOLEAN NOT NULL DEFAULT 0;
+
+CREATE TABLE IF NOT EXISTS device_baselines (
+    device_id TEXT PRIMARY KEY,
+    plays INTEGER NOT NULL,
+    zero_plays INTEGER NOT NULL,
+    amount_mean REAL NOT NULL,
+    amount_m2 REAL NOT NULL,
+    rate_mean REAL NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS alerts (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id TEXT NOT NULL,
+    kind TEXT NOT NULL,
+    message TEXT NOT NULL,
+    log_id INTEGER,
+    count INTEGER NOT NULL,
+    window_start DATETIME NOT NULL,
+    window_end DATETIME NOT NULL,
+    quarantined BOOLEAN NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL,
+    resolved_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS idx_alerts_device ON alerts(device_id, kind, resolved_at);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS venues (
+    id TEXT PRIMARY KEY,
+    name TEXT NOT NULL DEFAULT '',
+    time_zone TEXT NOT NULL,
+    weekdays TEXT NOT NULL DEFAULT '',
+    opens_at TEXT NOT NULL DEFAULT '',
+    closes_at TEXT NOT NULL DEFAULT ''
+);
+
+CREATE TABLE IF NOT EXISTS devices (
+    device_id TEXT PRIMARY KEY,
+    venue_id TEXT NOT NULL DEFAULT '',
+    firmware_version TEXT NOT NULL DEFAULT '',
+    first_seen_at DATETIME NOT NULL,
+    last_seen_at DATETIME NOT NULL
+);
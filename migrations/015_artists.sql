@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS artists (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL,
+    normalized_name TEXT NOT NULL UNIQUE
+);
+
+-- One artist per case- and whitespace-insensitive name, spelled as on the
+-- artist's earliest track.
+INSERT OR IGNORE INTO artists (name, normalized_name)
+SELECT name, normalized_name FROM (
+    SELECT trim(artist) AS name, lower(trim(artist)) AS normalized_name, MIN(id) AS first_track_id
+    FROM tracks
+    GROUP BY lower(trim(artist))
+)
+ORDER BY first_track_id;
+
+ALTER TABLE tracks ADD COLUMN artist_id INTEGER REFERENCES artists(id);
+
+UPDATE tracks SET artist_id = (SELECT id FROM artists WHERE normalized_name = lower(trim(tracks.artist)));
+UPDATE tracks SET artist = (SELECT name FROM artists WHERE id = tracks.artist_id);
+
+CREATE INDEX IF NOT EXISTS idx_tracks_artist ON tracks(artist_id);
+
+CREATE TRIGGER IF NOT EXISTS tracks_artist_insert AFTER INSERT ON tracks BEGIN
+    INSERT OR IGNORE INTO artists (name, normalized_name) VALUES (trim(new.artist), lower(trim(new.artist)));
+    UPDATE tracks SET
+        artist_id = (SELECT id FROM artists WHERE normalized_name = lower(trim(new.artist))),
+        artist = (SELECT name FROM artists WHERE normalized_name = lower(trim(new.artist)))
+    WHERE id = new.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS tracks_artist_update AFTER UPDATE OF artist ON tracks BEGIN
+    INSERT OR IGNORE INTO artists (name, normalized_name) VALUES (trim(new.artist), lower(trim(new.artist)));
+    UPDATE tracks SET
+        artist_id = (SELECT id FROM artists WHERE normalized_name = lower(trim(new.artist))),
+        artist = (SELECT name FROM artists WHERE normalized_name = lower(trim(new.artist)))
+    WHERE id = new.id;
+END;
@@ -0,0 +1,21 @@
+CREATE TABLE IF NOT EXISTS chart_snapshots (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    period_start DATETIME NOT NULL UNIQUE,
+    period_end DATETIME NOT NULL,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS chart_entries (
+    snapshot_id INTEGER NOT NULL REFERENCES chart_snapshots(id),
+    position INTEGER NOT NULL,
+    track_id INTEGER NOT NULL,
+    plays INTEGER NOT NULL,
+    revenue REAL NOT NULL,
+    previous_position INTEGER NOT NULL DEFAULT 0,
+    movement TEXT NOT NULL,
+    weeks_on_chart INTEGER NOT NULL,
+    peak_position INTEGER NOT NULL,
+    PRIMARY KEY (snapshot_id, position)
+);
+
+CREATE INDEX IF NOT EXISTS idx_chart_entries_track ON chart_entries(track_id, snapshot_id);
@@ -0,0 +1,21 @@
+ALTER TABLE playback_logs ADD COLUMN customer_id TEXT NOT NULL DEFAULT '';
+ALTER TABLE playback_logs ADD COLUMN paid_with_credit INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_playback_logs_customer ON playback_logs(customer_id, id) WHERE customer_id != '';
+
+CREATE TABLE IF NOT EXISTS customers (
+    id TEXT PRIMARY KEY,
+    balance REAL NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS wallet_transactions (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    customer_id TEXT NOT NULL REFERENCES customers(id),
+    kind TEXT NOT NULL,
+    amount REAL NOT NULL,
+    log_id INTEGER,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_wallet_transactions_customer ON wallet_transactions(customer_id, id);
@@ -0,0 +1,47 @@
+CREATE TABLE IF NOT EXISTS royalty_rates (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    artist_id INTEGER NOT NULL DEFAULT 0,
+    track_id INTEGER NOT NULL DEFAULT 0,
+    rate REAL NOT NULL,
+    UNIQUE (artist_id, track_id)
+);
+
+CREATE TABLE IF NOT EXISTS royalty_statements (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    artist_id INTEGER NOT NULL REFERENCES artists(id),
+    artist_name TEXT NOT NULL,
+    period_start DATETIME NOT NULL,
+    period_end DATETIME NOT NULL,
+    revenue REAL NOT NULL,
+    owed REAL NOT NULL,
+    closed_at DATETIME NOT NULL,
+    UNIQUE (artist_id, period_start)
+);
+
+CREATE TABLE IF NOT EXISTS royalty_statement_lines (
+    statement_id INTEGER NOT NULL REFERENCES royalty_statements(id),
+    track_id INTEGER NOT NULL,
+    title TEXT NOT NULL,
+    plays INTEGER NOT NULL,
+    revenue REAL NOT NULL,
+    rate REAL NOT NULL,
+    owed REAL NOT NULL,
+    PRIMARY KEY (statement_id, track_id)
+);
+
+-- Closed statements are what was paid out; they must never change.
+CREATE TRIGGER IF NOT EXISTS royalty_statements_no_update BEFORE UPDATE ON royalty_statements BEGIN
+    SELECT RAISE(ABORT, 'royalty statements are immutable');
+END;
+
+CREATE TRIGGER IF NOT EXISTS royalty_statements_no_delete BEFORE DELETE ON royalty_statements BEGIN
+    SELECT RAISE(ABORT, 'royalty statements are immutable');
+END;
+
+CREATE TRIGGER IF NOT EXISTS royalty_statement_lines_no_update BEFORE UPDATE ON royalty_statement_lines BEGIN
+    SELECT RAISE(ABORT, 'royalty statements are immutable');
+END;
+
+CREATE TRIGGER IF NOT EXISTS royalty_statement_lines_no_delete BEFORE DELETE ON royalty_statement_lines BEGIN
+    SELECT RAISE(ABORT, 'royalty statements are immutable');
+END;
@@ -0,0 +1 @@
+ALTER TABLE tracks ADD COLUMN isrc TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,27 @@
+ALTER TABLE tracks ADD COLUMN album TEXT NOT NULL DEFAULT '';
+ALTER TABLE tracks ADD COLUMN release_year INTEGER NOT NULL DEFAULT 0;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tracks_isrc ON tracks(isrc) WHERE isrc != '';
+
+CREATE TABLE IF NOT EXISTS tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE IF NOT EXISTS track_tags (
+    track_id INTEGER NOT NULL REFERENCES tracks(id),
+    tag_id INTEGER NOT NULL REFERENCES tags(id),
+    PRIMARY KEY (track_id, tag_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_track_tags_tag ON track_tags(tag_id, track_id);
+
+-- Partner identifiers (label catalogue numbers, store ids, ...); one per
+-- source per track. ISRCs live on tracks.isrc.
+CREATE TABLE IF NOT EXISTS track_external_ids (
+    track_id INTEGER NOT NULL REFERENCES tracks(id),
+    source TEXT NOT NULL,
+    external_id TEXT NOT NULL,
+    PRIMARY KEY (track_id, source),
+    UNIQUE (source, external_id)
+);
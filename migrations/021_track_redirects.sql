@@ -0,0 +1,8 @@
+-- Ids of tracks merged into another track, so old ids keep resolving.
+CREATE TABLE IF NOT EXISTS track_redirects (
+    track_id INTEGER PRIMARY KEY,
+    canonical_id INTEGER NOT NULL REFERENCES tracks(id),
+    merged_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_track_redirects_canonical ON track_redirects(canonical_id);
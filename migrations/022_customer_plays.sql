@@ -0,0 +1,7 @@
+-- Plays are counted on the customer so the loyalty cycle survives the
+-- retention job purging old playback logs.
+ALTER TABLE customers ADD COLUMN plays INTEGER NOT NULL DEFAULT 0;
+
+UPDATE customers SET plays = (
+    SELECT COUNT(*) FROM playback_logs WHERE customer_id = customers.id AND voided_at IS NULL
+);
@@ -0,0 +1,4 @@
+-- Rollup periods were written without a UTC offset, so they sorted before
+-- time parameters for the same instant and fell out of ranges starting on
+-- their hour. Store them the way the driver formats times.
+UPDATE playback_rollups SET period_start = period_start || '+00:00' WHERE period_start NOT LIKE '%+00:00';
@@ -18,17 +18,139 @@ amount_paid: Сума, яка була фактично сплачена в мо
 */
 
 type Track struct {
-	ID     int
-	Title  string
-	Artist string
-	Price  float64
+	ID              int      `json:"id"`
+	Title           string   `json:"title"`
+	Artist          string   `json:"artist"`
+	ArtistID        int      `json:"artist_id"`
+	Price           float64  `json:"price"`
+	DurationSeconds int      `json:"duration_seconds"`
+	ISRC            string   `json:"isrc,omitempty"`
+	Album           string   `json:"album,omitempty"`
+	ReleaseYear     int      `json:"release_year,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+}
+
+// TrackMetadata is a partial update of a track's optional metadata. Nil
+// fields are left unchanged; empty values clear them.
+type TrackMetadata struct {
+	ISRC            *string   `json:"isrc"`
+	Album           *string   `json:"album"`
+	ReleaseYear     *int      `json:"release_year"`
+	DurationSeconds *int      `json:"duration_seconds"`
+	Tags            *[]string `json:"tags"`
+}
+
+// ExternalID maps a partner's identifier for a track (a label catalogue
+// number, a store id, ...) to the track.
+type ExternalID struct {
+	TrackID    int    `json:"track_id"`
+	Source     string `json:"source"`
+	ExternalID string `json:"external_id"`
+}
+
+type Artist struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type ArtistStat struct {
+	ArtistID int     `json:"artist_id"`
+	Name     string  `json:"name"`
+	Plays    int     `json:"plays"`
+	Revenue  float64 `json:"revenue"`
+}
+
+type TrackStat struct {
+	TrackID int     `json:"track_id"`
+	Title   string  `json:"title"`
+	Plays   int     `json:"plays"`
+	Revenue float64 `json:"revenue"`
+}
+
+type ArtistBreakdown struct {
+	Artist  Artist      `json:"artist"`
+	Plays   int         `json:"plays"`
+	Revenue float64     `json:"revenue"`
+	Tracks  []TrackStat `json:"tracks"`
+}
+
+// DailyPlays is a track's play count and net revenue on one local date.
+type DailyPlays struct {
+	Date    string  `json:"date"`
+	Plays   int     `json:"plays"`
+	Revenue float64 `json:"revenue"`
+}
+
+// TrackStats is one track's all-time performance. Rank is its position in
+// the top tracks, where tracks with equal plays share a rank, and is nil
+// for a track that was never played. Days runs from the first to the last
+// day with plays or revenue.
+type TrackStats struct {
+	Track         Track        `json:"track"`
+	TimeZone      string       `json:"time_zone"`
+	Plays         int          `json:"plays"`
+	Revenue       float64      `json:"revenue"`
+	AveragePaid   float64      `json:"average_paid"`
+	FirstPlayedAt *time.Time   `json:"first_played_at"`
+	LastPlayedAt  *time.Time   `json:"last_played_at"`
+	Rank          *int         `json:"rank"`
+	Days          []DailyPlays `json:"days"`
+}
+
+type TrackSearchResult struct {
+	Tracks []Track `json:"tracks"`
+	Total  int     `json:"total"`
+	Limit  int     `json:"limit"`
+	Offset int     `json:"offset"`
 }
 
 type PlaybackLog struct {
-	ID         int
-	TrackID    int
-	PlayedAt   time.Time
-	AmountPaid float64
+	ID         int       `json:"id"`
+	TrackID    int       `json:"track_id"`
+	PlayedAt   time.Time `json:"played_at"`
+	AmountPaid float64   `json:"amount_paid"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	SessionID  string    `json:"session_id,omitempty"`
+
+	ListPrice     float64 `json:"list_price"`
+	PaymentStatus string  `json:"payment_status"`
+
+	VoidedAt       *time.Time `json:"voided_at,omitempty"`
+	VoidReason     string     `json:"void_reason,omitempty"`
+	RefundedAmount float64    `json:"refunded_amount,omitempty"`
+
+	CompletedAt     *time.Time `json:"completed_at,omitempty"`
+	ListenedSeconds int        `json:"listened_seconds,omitempty"`
+	Skipped         bool       `json:"skipped,omitempty"`
+
+	Quarantined bool `json:"quarantined,omitempty"`
+
+	CustomerID     string `json:"customer_id,omitempty"`
+	PaidWithCredit bool   `json:"paid_with_credit,omitempty"`
+}
+
+// Customer is a wallet holder. Plays counts the customer's plays that were
+// not voided, including those the retention job has since archived.
+type Customer struct {
+	ID        string    `json:"id"`
+	Balance   float64   `json:"balance"`
+	Plays     int       `json:"plays"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WalletTransaction is a change to a customer's credit balance; debits are
+// negative.
+type WalletTransaction struct {
+	ID         int       `json:"id"`
+	CustomerID string    `json:"customer_id"`
+	Kind       string    `json:"kind"`
+	Amount     float64   `json:"amount"`
+	LogID      int       `json:"log_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type LoyaltyRule struct {
+	FreeEvery int `json:"free_every"`
 }
 
 type TopTrackStat struct {
@@ -36,13 +158,702 @@ type TopTrackStat struct {
 	Count int    `json:"count"`
 }
 
+type TrackPlayCount struct {
+	TrackID int
+	Title   string
+	Count   int
+}
+
+type TrendingTrackStat struct {
+	TrackID       int     `json:"track_id"`
+	Title         string  `json:"title"`
+	RecentCount   int     `json:"recent_count"`
+	BaselineCount int     `json:"baseline_count"`
+	Score         float64 `json:"score"`
+}
+
+type SkipStat struct {
+	TrackID         int     `json:"track_id"`
+	Title           string  `json:"title"`
+	DurationSeconds int     `json:"duration_seconds"`
+	Plays           int     `json:"plays"`
+	Skips           int     `json:"skips"`
+	SkipRate        float64 `json:"skip_rate"`
+	AvgCompletion   float64 `json:"avg_completion"`
+}
+
+// RoyaltyRate is the share of revenue owed on an artist's tracks, or on a
+// single track when TrackID is set.
+type RoyaltyRate struct {
+	ID       int     `json:"id"`
+	ArtistID int     `json:"artist_id,omitempty"`
+	TrackID  int     `json:"track_id,omitempty"`
+	Rate     float64 `json:"rate"`
+}
+
+type RoyaltyLine struct {
+	TrackID int     `json:"track_id"`
+	Title   string  `json:"title"`
+	Plays   int     `json:"plays"`
+	Revenue float64 `json:"revenue"`
+	Rate    float64 `json:"rate"`
+	Owed    float64 `json:"owed"`
+}
+
+// RoyaltyStatement is what an artist is owed for a period. Previews have no
+// ID and no ClosedAt.
+type RoyaltyStatement struct {
+	ID          int           `json:"id,omitempty"`
+	ArtistID    int           `json:"artist_id"`
+	ArtistName  string        `json:"artist_name"`
+	PeriodStart time.Time     `json:"period_start"`
+	PeriodEnd   time.Time     `json:"period_end"`
+	Revenue     float64       `json:"revenue"`
+	Owed        float64       `json:"owed"`
+	ClosedAt    *time.Time    `json:"closed_at,omitempty"`
+	Lines       []RoyaltyLine `json:"lines,omitempty"`
+}
+
+// ChartSnapshot is the persisted top tracks chart for one period.
+type ChartSnapshot struct {
+	ID          int          `json:"id"`
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	CreatedAt   time.Time    `json:"created_at"`
+	Entries     []ChartEntry `json:"entries,omitempty"`
+}
+
+// ChartEntry is one chart position. PreviousPosition is zero for new entries
+// and re-entries.
+type ChartEntry struct {
+	SnapshotID       int     `json:"-"`
+	Position         int     `json:"position"`
+	TrackID          int     `json:"track_id"`
+	Title            string  `json:"title"`
+	Artist           string  `json:"artist"`
+	Plays            int     `json:"plays"`
+	Revenue          float64 `json:"revenue"`
+	PreviousPosition int     `json:"previous_position,omitempty"`
+	Movement         string  `json:"movement"`
+	WeeksOnChart     int     `json:"weeks_on_chart"`
+	PeakPosition     int     `json:"peak_position"`
+}
+
+// TrackPlayBucket counts one track's plays in a PlayBucket.
+type TrackPlayBucket struct {
+	TrackID int
+	Start   time.Time
+	Plays   int
+}
+
+// UsageRow is one line of a performance-rights usage report: a track's
+// performances at a venue on one local date.
+type UsageRow struct {
+	VenueID string
+	Date    string
+	TrackID int
+	ISRC    string
+	Title   string
+	Artist  string
+	Plays   int
+}
+
+// UsageReport lists a venue's performances between two local dates,
+// inclusive.
+type UsageReport struct {
+	VenueID string
+	From    string
+	To      string
+	Rows    []UsageRow
+}
+
+// DuplicateTrack is a pair of tracks that look like the same song, with
+// the track suggested to keep.
+type DuplicateTrack struct {
+	Canonical Track   `json:"canonical"`
+	Duplicate Track   `json:"duplicate"`
+	Score     float64 `json:"score"`
+}
+
+// CatalogEntry is one track in an imported catalog file. Zero values keep
+// the matched track's current value.
+type CatalogEntry struct {
+	Title           string            `json:"title"`
+	Artist          string            `json:"artist"`
+	Price           float64           `json:"price"`
+	DurationSeconds int               `json:"duration_seconds"`
+	ISRC            string            `json:"isrc"`
+	Album           string            `json:"album"`
+	ReleaseYear     int               `json:"release_year"`
+	Tags            []string          `json:"tags"`
+	ExternalIDs     map[string]string `json:"external_ids"`
+}
+
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// CatalogChange is what importing one catalog row does. Row counts entries
+// from 1, not counting a CSV header.
+type CatalogChange struct {
+	Row       int           `json:"row"`
+	Action    string        `json:"action"`
+	TrackID   int           `json:"track_id,omitempty"`
+	MatchedBy string        `json:"matched_by,omitempty"`
+	Title     string        `json:"title"`
+	Artist    string        `json:"artist"`
+	Fields    []FieldChange `json:"fields"`
+}
+
+type CatalogImportError struct {
+	Row     int    `json:"row"`
+	Message string `json:"message"`
+}
+
+// CatalogImport reports a catalog import. Changes lists only rows that
+// create or update a track; unchanged rows are counted.
+type CatalogImport struct {
+	DryRun    bool                 `json:"dry_run"`
+	Created   int                  `json:"created"`
+	Updated   int                  `json:"updated"`
+	Unchanged int                  `json:"unchanged"`
+	Changes   []CatalogChange      `json:"changes"`
+	Errors    []CatalogImportError `json:"errors,omitempty"`
+}
+
+// CatalogUpsert is a track written by a catalog import: a new track when
+// Track.ID is 0, otherwise the matched track's new catalog fields and tags.
+type CatalogUpsert struct {
+	Row         int
+	Track       Track
+	ExternalIDs []ExternalID
+	// Previous is the track as it was when the row was matched to it, and
+	// LastTrackID the newest track then; they let the import notice that
+	// the catalog changed since.
+	Previous    Track
+	LastTrackID int
+}
+
+// HeatmapFilter narrows the plays counted in a heatmap; zero values match
+// everything.
+type HeatmapFilter struct {
+	VenueID  string
+	TrackID  int
+	ArtistID int
+	Tag      string
+	From     time.Time
+	To       time.Time
+}
+
+// PlayBucket is the play count and net revenue of the plays from Start.
+// Logs are bucketed by UTC quarter hour, which lies within one local hour in
+// every time zone in use; retention rollups only keep the UTC hour.
+type PlayBucket struct {
+	Start   time.Time
+	Plays   int
+	Revenue float64
+}
+
+// TagPlayBucket is one tag's play count and net revenue in a PlayBucket.
+type TagPlayBucket struct {
+	TagID   int
+	Tag     string
+	Start   time.Time
+	Plays   int
+	Revenue float64
+}
+
+type Tag struct {
+	ID     int    `json:"id"`
+	Name   string `json:"name"`
+	Tracks int    `json:"tracks"`
+}
+
+type TagStat struct {
+	TagID        int     `json:"tag_id"`
+	Tag          string  `json:"tag"`
+	Plays        int     `json:"plays"`
+	Revenue      float64 `json:"revenue"`
+	RevenueShare float64 `json:"revenue_share"`
+}
+
+// TagStats breaks plays and revenue down by tag, optionally on one local
+// weekday (0 = Sunday) and hour. A track with several tags counts towards
+// each of them, so revenue shares can add up to more than 1.
+type TagStats struct {
+	TimeZone     string    `json:"time_zone"`
+	Weekday      *int      `json:"weekday,omitempty"`
+	Hour         *int      `json:"hour,omitempty"`
+	TotalPlays   int       `json:"total_plays"`
+	TotalRevenue float64   `json:"total_revenue"`
+	Tags         []TagStat `json:"tags"`
+}
+
+type HeatmapCell struct {
+	Plays   int     `json:"plays"`
+	Revenue float64 `json:"revenue"`
+}
+
+// Heatmap buckets plays by local weekday (0 = Sunday) and hour of day.
+type Heatmap struct {
+	TimeZone string             `json:"time_zone"`
+	Cells    [7][24]HeatmapCell `json:"cells"`
+}
+
+type TrackPair struct {
+	TrackID        int
+	RelatedTrackID int
+}
+
+type RelatedTrack struct {
+	TrackID int    `json:"track_id"`
+	Title   string `json:"title"`
+	Artist  string `json:"artist"`
+	Count   int    `json:"count"`
+}
+
+// ReconciliationRow sums a device's plays over a day in the time zone of the
+// device's venue, or UTC when it has none.
+type ReconciliationRow struct {
+	Day           string  `json:"day"`
+	TimeZone      string  `json:"time_zone"`
+	DeviceID      string  `json:"device_id"`
+	Plays         int     `json:"plays"`
+	Underpaid     int     `json:"underpaid"`
+	Overpaid      int     `json:"overpaid"`
+	ExpectedTotal float64 `json:"expected_total"`
+	PaidTotal     float64 `json:"paid_total"`
+	Difference    float64 `json:"difference"`
+}
+
+// ReconciliationBucket sums a device's plays from Start, bucketed by UTC
+// quarter hour like PlayBucket so they can be regrouped by local day.
+type ReconciliationBucket struct {
+	Start         time.Time
+	DeviceID      string
+	Plays         int
+	Underpaid     int
+	Overpaid      int
+	ExpectedTotal float64
+	PaidTotal     float64
+}
+
+type PaymentDiscrepancy struct {
+	LogID         int       `json:"log_id"`
+	TrackID       int       `json:"track_id"`
+	DeviceID      string    `json:"device_id"`
+	PlayedAt      time.Time `json:"played_at"`
+	ListPrice     float64   `json:"list_price"`
+	AmountPaid    float64   `json:"amount_paid"`
+	PaymentStatus string    `json:"payment_status"`
+}
+
+type ReconciliationReport struct {
+	From          time.Time            `json:"from"`
+	To            time.Time            `json:"to"`
+	Rows          []ReconciliationRow  `json:"rows"`
+	Discrepancies []PaymentDiscrepancy `json:"discrepancies"`
+}
+
+type RetentionMonth struct {
+	Month   string  `json:"month"`
+	Plays   int     `json:"plays"`
+	Revenue float64 `json:"revenue"`
+}
+
+type RetentionReport struct {
+	Cutoff     time.Time        `json:"cutoff"`
+	TotalPlays int              `json:"total_plays"`
+	Months     []RetentionMonth `json:"months"`
+}
+
+type PricingRule struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	Target        string    `json:"target"`
+	TrackCount    int       `json:"track_count"`
+	AdjustPercent float64   `json:"adjust_percent"`
+	WindowHours   int       `json:"window_hours"`
+	CooldownHours int       `json:"cooldown_hours"`
+	Enabled       bool      `json:"enabled"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type PriceBounds struct {
+	TrackID int     `json:"track_id"`
+	Floor   float64 `json:"floor"`
+	Ceiling float64 `json:"ceiling"`
+}
+
+type PriceChange struct {
+	ID        int       `json:"id"`
+	TrackID   int       `json:"track_id"`
+	RuleID    int       `json:"rule_id,omitempty"`
+	Source    string    `json:"source"`
+	OldPrice  float64   `json:"old_price"`
+	NewPrice  float64   `json:"new_price"`
+	Reason    string    `json:"reason"`
+	ChangedAt time.Time `json:"changed_at"`
+}
+
+type TimePriceRule struct {
+	ID            int       `json:"id"`
+	Name          string    `json:"name"`
+	TrackID       int       `json:"track_id,omitempty"`
+	Artist        string    `json:"artist,omitempty"`
+	Weekdays      []int     `json:"weekdays"`
+	StartTime     string    `json:"start_time"`
+	EndTime       string    `json:"end_time"`
+	Price         float64   `json:"price,omitempty"`
+	AdjustPercent float64   `json:"adjust_percent,omitempty"`
+	TimeZone      string    `json:"time_zone"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type EffectivePrice struct {
+	TrackID   int       `json:"track_id"`
+	BasePrice float64   `json:"base_price"`
+	Price     float64   `json:"price"`
+	RuleID    int       `json:"rule_id,omitempty"`
+	RuleName  string    `json:"rule_name,omitempty"`
+	At        time.Time `json:"at"`
+}
+
+type ScheduledPrice struct {
+	ID            int        `json:"id"`
+	TrackID       int        `json:"track_id"`
+	Price         float64    `json:"price"`
+	StartsAt      time.Time  `json:"starts_at"`
+	EndsAt        *time.Time `json:"ends_at,omitempty"`
+	Status        string     `json:"status"`
+	PreviousPrice *float64   `json:"previous_price,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
 type TrackRepository interface {
+	GetTracks() ([]Track, error)
+	// GetTrackByID also resolves the ids of tracks merged into another.
 	GetTrackByID(id int) (*Track, error)
 	UpdateTrackPrice(id int, newPrice float64) error
+	SetTrackDuration(id int, seconds int) error
+	SearchTracks(query string, limit, offset int) ([]Track, int, error)
+	UpdateTrackMetadata(track Track) error
+	GetTrackByISRC(isrc string) (*Track, error)
+	GetTrackByExternalID(source, externalID string) (*Track, error)
+	SaveExternalID(id ExternalID) error
+	DeleteExternalID(trackID int, source string) error
+	GetExternalIDs(trackID int) ([]ExternalID, error)
+	// MergeTracks moves everything that references the duplicate to the
+	// canonical track, deletes the duplicate and redirects its id to the
+	// canonical track in GetTrackByID.
+	MergeTracks(canonicalID, duplicateID int, at time.Time) error
 }
 
 type PlaybackLogRepository interface {
-	CreateLog(log PlaybackLog) error
+	CreateLog(log PlaybackLog) (*PlaybackLog, error)
+	GetLogByID(id int) (*PlaybackLog, error)
+	// VoidLog marks a log voided. A customer's play also comes off their
+	// play count, a credit payment is refunded to the wallet and the loyalty
+	// reward it earned is reversed, even if that leaves a negative balance.
+	VoidLog(id int, reason string, refundedAmount float64, voidedAt time.Time) error
+	CompleteLog(id int, listenedSeconds int, skipped bool, completedAt time.Time) error
 	GetAllLogs() []PlaybackLog
 	GetTopTracks(limit int) ([]TopTrackStat, error)
+	GetTrackPlayCounts(from, to time.Time) ([]TrackPlayCount, error)
+	GetSkipStats(from, to time.Time) ([]SkipStat, error)
+	// GetTrackPlayRange returns when the track was first and last played,
+	// or nil when it never was. Purged plays count from their rollup hour.
+	GetTrackPlayRange(trackID int) (*time.Time, *time.Time, error)
+}
+
+type RetentionRepository interface {
+	GetExpiredLogs(cutoff time.Time, limit int) ([]PlaybackLog, error)
+	PurgeExpiredLogs(cutoff time.Time, maxID int) (int, error)
+	GetExpiredLogsSummary(cutoff time.Time) ([]RetentionMonth, error)
+	// HasRollups reports whether logs from [from, to) have been rolled up,
+	// leaving them only in the archive.
+	HasRollups(from, to time.Time) (bool, error)
+}
+
+type WebhookSubscription struct {
+	ID         int       `json:"id"`
+	URL        string    `json:"url"`
+	EventTypes []string  `json:"event_types"`
+	Secret     string    `json:"secret,omitempty"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type WebhookEvent struct {
+	ID        int
+	Type      string
+	Data      []byte
+	CreatedAt time.Time
+}
+
+type WebhookDelivery struct {
+	ID             int        `json:"id"`
+	SubscriptionID int        `json:"subscription_id"`
+	EventID        int        `json:"event_id"`
+	EventType      string     `json:"event_type"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+
+	URL    string       `json:"-"`
+	Secret string       `json:"-"`
+	Event  WebhookEvent `json:"-"`
+}
+
+type QueueItem struct {
+	ID            int        `json:"id"`
+	DeviceID      string     `json:"device_id"`
+	SessionID     string     `json:"session_id,omitempty"`
+	TrackID       int        `json:"track_id"`
+	AmountPaid    float64    `json:"amount_paid"`
+	ListPrice     float64    `json:"list_price"`
+	PaymentStatus string     `json:"payment_status"`
+	Status        string     `json:"status"`
+	LogID         int        `json:"log_id,omitempty"`
+	EnqueuedAt    time.Time  `json:"enqueued_at"`
+	StartedAt     *time.Time `json:"started_at,omitempty"`
+	FinishedAt    *time.Time `json:"finished_at,omitempty"`
+}
+
+type Alert struct {
+	ID          int        `json:"id"`
+	DeviceID    string     `json:"device_id"`
+	Kind        string     `json:"kind"`
+	Message     string     `json:"message"`
+	LogID       int        `json:"log_id,omitempty"`
+	Count       int        `json:"count"`
+	WindowStart time.Time  `json:"window_start"`
+	WindowEnd   time.Time  `json:"window_end"`
+	Quarantined bool       `json:"quarantined"`
+	CreatedAt   time.Time  `json:"created_at"`
+	ResolvedAt  *time.Time `json:"resolved_at,omitempty"`
+}
+
+type Venue struct {
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	TimeZone string `json:"time_zone"`
+	Weekdays []int  `json:"weekdays"`
+	OpensAt  string `json:"opens_at,omitempty"`
+	ClosesAt string `json:"closes_at,omitempty"`
+}
+
+type Device struct {
+	DeviceID        string    `json:"device_id"`
+	VenueID         string    `json:"venue_id,omitempty"`
+	FirmwareVersion string    `json:"firmware_version,omitempty"`
+	FirstSeenAt     time.Time `json:"first_seen_at"`
+	LastSeenAt      time.Time `json:"last_seen_at"`
+	Online          bool      `json:"online"`
+}
+
+type PlaybackLoggedEvent struct {
+	LogID      int       `json:"log_id"`
+	TrackID    int       `json:"track_id"`
+	PlayedAt   time.Time `json:"played_at"`
+	AmountPaid float64   `json:"amount_paid"`
+	DeviceID   string    `json:"device_id,omitempty"`
+	SessionID  string    `json:"session_id,omitempty"`
+}
+
+type PlaybackVoidedEvent struct {
+	LogID          int       `json:"log_id"`
+	TrackID        int       `json:"track_id"`
+	Reason         string    `json:"reason"`
+	RefundedAmount float64   `json:"refunded_amount"`
+	VoidedAt       time.Time `json:"voided_at"`
+}
+
+type DeviceOfflineEvent struct {
+	AlertID    int       `json:"alert_id"`
+	DeviceID   string    `json:"device_id"`
+	VenueID    string    `json:"venue_id,omitempty"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+type PriceUpdatedEvent struct {
+	TrackID  int     `json:"track_id"`
+	OldPrice float64 `json:"old_price"`
+	NewPrice float64 `json:"new_price"`
+}
+
+type WebhookRepository interface {
+	CreateWebhook(sub WebhookSubscription) (*WebhookSubscription, error)
+	GetWebhooks() ([]WebhookSubscription, error)
+	DeleteWebhook(id int) error
+	GetWebhookDeliveries(subscriptionID int, limit int) ([]WebhookDelivery, error)
+	DispatchOutbox(limit int) (int, error)
+	// PurgeWebhookHistory removes dispatched events created before cutoff
+	// along with their finished deliveries. Events with a pending delivery
+	// are kept until it finishes.
+	PurgeWebhookHistory(cutoff time.Time) (int, error)
+	GetDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error)
+	UpdateDelivery(d WebhookDelivery) error
+}
+
+type RecommendationRepository interface {
+	GetLogsAfter(id int, limit int) ([]PlaybackLog, error)
+	GetSessionLogsBefore(log PlaybackLog, since time.Time, limit int) ([]PlaybackLog, error)
+	GetCooccurrenceCursor() (int, error)
+	AddCooccurrences(pairs []TrackPair, lastLogID int) error
+	GetRelatedTracks(trackID int, limit int) ([]RelatedTrack, error)
+}
+
+type PricingRepository interface {
+	CreatePricingRule(rule PricingRule) (*PricingRule, error)
+	GetPricingRules() ([]PricingRule, error)
+	DeletePricingRule(id int) error
+	SetPriceBounds(bounds PriceBounds) error
+	GetPriceBounds() ([]PriceBounds, error)
+	RecordPriceChange(change PriceChange) error
+	GetPriceChanges(limit int) ([]PriceChange, error)
+	GetLatestPriceChanges() ([]PriceChange, error)
+}
+
+type ScheduledPriceRepository interface {
+	CreateScheduledPrice(sp ScheduledPrice) (*ScheduledPrice, error)
+	GetScheduledPriceByID(id int) (*ScheduledPrice, error)
+	GetScheduledPrices(status string) ([]ScheduledPrice, error)
+	UpdateScheduledPrice(sp ScheduledPrice) error
+}
+
+type TimePriceRuleRepository interface {
+	CreateTimePriceRule(rule TimePriceRule) (*TimePriceRule, error)
+	GetTimePriceRules() ([]TimePriceRule, error)
+	DeleteTimePriceRule(id int) error
+}
+
+type SettingsRepository interface {
+	GetSetting(key string) (string, error)
+	SetSetting(key, value string) error
+}
+
+type ReconciliationRepository interface {
+	GetReconciliationBuckets(from, to time.Time) ([]ReconciliationBucket, error)
+	GetPaymentDiscrepancies(from, to time.Time, limit int) ([]PaymentDiscrepancy, error)
+}
+
+type QueueRepository interface {
+	EnqueueTrack(item QueueItem) (*QueueItem, error)
+	GetQueue(deviceID string) ([]QueueItem, error)
+	GetQueueItemByID(id int) (*QueueItem, error)
+	StartQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error)
+	// CancelQueueItem ends a queued item that never played and records its
+	// payment as the given log, voided with a full refund, so the payment
+	// still shows in reconciliation.
+	CancelQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error)
+	UpdateQueueItem(item QueueItem) error
+}
+
+type AnomalyRepository interface {
+	GetAnomalyCursor() (int, error)
+	GetDeviceBaseline(deviceID string) (*DeviceBaseline, error)
+	GetDeviceActivity(deviceID string, since time.Time, maxLogID int) (plays int, zeroPlays int, err error)
+	SaveAnomalyBatch(baselines []DeviceBaseline, alerts []Alert, lastLogID int) error
+}
+
+type AlertRepository interface {
+	GetOpenAlert(deviceID, kind string) (*Alert, error)
+	GetAlerts(deviceID string, includeResolved bool) ([]Alert, error)
+	GetAlertByID(id int) (*Alert, error)
+	ResolveAlert(id int, resolvedAt time.Time, release bool) error
+}
+
+type DeviceRepository interface {
+	RecordHeartbeat(device Device) (*Device, error)
+	GetDevices() ([]Device, error)
+	GetDeviceByID(id string) (*Device, error)
+	CreateOfflineAlert(alert Alert, event DeviceOfflineEvent) (*Alert, error)
+}
+
+type VenueRepository interface {
+	SaveVenue(venue Venue) error
+	GetVenues() ([]Venue, error)
+	GetVenueByID(id string) (*Venue, error)
+}
+
+type ArtistRepository interface {
+	GetArtists() ([]Artist, error)
+	GetArtistByID(id int) (*Artist, error)
+	GetArtistStats(from, to time.Time) ([]ArtistStat, error)
+	GetArtistTrackStats(artistID int, from, to time.Time) ([]TrackStat, error)
+}
+
+type HeatmapRepository interface {
+	GetPlayBuckets(filter HeatmapFilter) ([]PlayBucket, error)
+	// GetVenueTrackPlays counts the venue's logged performances per track
+	// and PlayBucket in [from, to). Voided plays did not happen and are left
+	// out.
+	GetVenueTrackPlays(venueID string, from, to time.Time) ([]TrackPlayBucket, error)
+}
+
+type ChartRepository interface {
+	GetTrackTotals(from, to time.Time) ([]TrackStat, error)
+	// GetChartHistory returns the most recent chart entry of every track that
+	// has charted.
+	GetChartHistory() ([]ChartEntry, error)
+	SaveChartSnapshot(snapshot ChartSnapshot) (*ChartSnapshot, error)
+	// GetChartSnapshots returns snapshots without entries, newest first.
+	GetChartSnapshots() ([]ChartSnapshot, error)
+	GetChartSnapshot(id int) (*ChartSnapshot, error)
+}
+
+type CustomerRepository interface {
+	// GetCustomer returns nil without an error for an unknown customer.
+	GetCustomer(id string) (*Customer, error)
+	TopUpCredit(customerID string, amount float64, at time.Time) (*Customer, error)
+	// CreateCustomerLog stores the log and applies the wallet transactions,
+	// linked to it, in one step. It fails if a debit would take the balance
+	// below zero.
+	CreateCustomerLog(log PlaybackLog, wallet []WalletTransaction) (*PlaybackLog, error)
+	// GetCustomerLogs lists plays newest first. Plays past the retention
+	// period are only in the archive.
+	GetCustomerLogs(customerID string, limit, offset int) ([]PlaybackLog, error)
+	GetWalletTransactions(customerID string, limit, offset int) ([]WalletTransaction, error)
+}
+
+type RoyaltyRepository interface {
+	SaveRoyaltyRate(rate RoyaltyRate) (*RoyaltyRate, error)
+	GetRoyaltyRates() ([]RoyaltyRate, error)
+	DeleteRoyaltyRate(id int) error
+	// SaveRoyaltyStatement returns StatementOverlap when a saved statement of
+	// the artist covers part of the statement's period.
+	SaveRoyaltyStatement(statement RoyaltyStatement) (*RoyaltyStatement, error)
+	// GetRoyaltyStatements returns statements without lines, newest period
+	// first; artistID 0 returns every artist's.
+	GetRoyaltyStatements(artistID int) ([]RoyaltyStatement, error)
+	GetRoyaltyStatement(id int) (*RoyaltyStatement, error)
+}
+
+type TagRepository interface {
+	// GetTags returns every tag with its number of tracks, by name.
+	GetTags() ([]Tag, error)
+	// GetTagByName returns nil when there is no such tag.
+	GetTagByName(name string) (*Tag, error)
+	CreateTag(name string) (*Tag, error)
+	RenameTag(id int, name string) error
+	DeleteTag(id int) error
+	// TagTrack adds a tag to a track, creating the tag if it is new.
+	TagTrack(trackID int, name string) error
+	UntagTrack(trackID int, name string) error
+	GetTagTopTracks(limit int, tag string) ([]TopTrackStat, error)
+	GetTagPlayBuckets(filter HeatmapFilter) ([]TagPlayBucket, error)
+}
+
+type CatalogRepository interface {
+	// ImportCatalog writes every upsert in one transaction and returns the
+	// track ids in order. Price changes are recorded as price history. It
+	// writes nothing and returns CatalogChanged when a matched track changed,
+	// an identifier was taken by another track or a track with a new row's
+	// title and artist was added since the upserts were planned.
+	ImportCatalog(upserts []CatalogUpsert, at time.Time) ([]int, error)
 }
@@ -0,0 +1,24 @@
+package main
+
+const (
+	PaymentPolicyReject = "reject"
+	PaymentPolicyFlag   = "accept-and-flag"
+
+	PaymentOK        = "ok"
+	PaymentUnderpaid = "underpaid"
+	PaymentOverpaid  = "overpaid"
+
+	paymentPolicySetting = "payment_policy"
+	paymentTolerance     = 0.005
+)
+
+func classifyPayment(amountPaid, listPrice float64) string {
+	switch {
+	case amountPaid < listPrice-paymentTolerance:
+		return PaymentUnderpaid
+	case amountPaid > listPrice+paymentTolerance:
+		return PaymentOverpaid
+	default:
+		return PaymentOK
+	}
+}
@@ -0,0 +1,238 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	PricingTargetTop    = "top"
+	PricingTargetBottom = "bottom"
+
+	PriceSourceRule     = "pricing_rule"
+	PriceSourceSchedule = "schedule"
+	PriceSourceImport   = "catalog_import"
+)
+
+func roundPrice(price float64) float64 {
+	return math.Round(price*100) / 100
+}
+
+func (b PriceBounds) Clamp(price float64) float64 {
+	if b.Floor > 0 && price < b.Floor {
+		price = b.Floor
+	}
+	if b.Ceiling > 0 && price > b.Ceiling {
+		price = b.Ceiling
+	}
+	return price
+}
+
+// planPriceChanges applies enabled rules in id order. plays maps a rule's
+// window in hours to play counts per track. A track changed by one rule is
+// not touched by later rules in the same run, and tracks changed within a
+// rule's cooldown are skipped entirely.
+func planPriceChanges(now time.Time, rules []PricingRule, tracks []Track, plays map[int]map[int]int,
+	bounds map[int]PriceBounds, lastChanged map[int]time.Time) []PriceChange {
+	var changes []PriceChange
+	changed := make(map[int]bool)
+
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+
+		counts := plays[rule.WindowHours]
+		ranked := make([]Track, 0, len(tracks))
+		for _, track := range tracks {
+			if rule.Target == PricingTargetTop && counts[track.ID] == 0 {
+				continue
+			}
+			ranked = append(ranked, track)
+		}
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if rule.Target == PricingTargetTop {
+				return counts[ranked[i].ID] > counts[ranked[j].ID]
+			}
+			return counts[ranked[i].ID] < counts[ranked[j].ID]
+		})
+		if len(ranked) > rule.TrackCount {
+			ranked = ranked[:rule.TrackCount]
+		}
+
+		cooldown := time.Duration(rule.CooldownHours) * time.Hour
+		for _, track := range ranked {
+			if changed[track.ID] {
+				continue
+			}
+			if last, ok := lastChanged[track.ID]; ok && now.Sub(last) < cooldown {
+				continue
+			}
+
+			newPrice := bounds[track.ID].Clamp(roundPrice(track.Price * (1 + rule.AdjustPercent/100)))
+			if newPrice <= 0 || newPrice == track.Price {
+				continue
+			}
+
+			changed[track.ID] = true
+			changes = append(changes, PriceChange{
+				TrackID:  track.ID,
+				RuleID:   rule.ID,
+				Source:   PriceSourceRule,
+				OldPrice: track.Price,
+				NewPrice: newPrice,
+				Reason: fmt.Sprintf("%s: %s %d by plays in last %dh, %+g%%",
+					rule.Name, rule.Target, rule.TrackCount, rule.WindowHours, rule.AdjustPercent),
+				ChangedAt: now,
+			})
+		}
+	}
+	return changes
+}
+
+// PricingJob periodically applies the pricing rules.
+type PricingJob struct {
+	service *Service
+}
+
+func NewPricingJob(service *Service) *PricingJob {
+	return &PricingJob{service: service}
+}
+
+func (j *PricingJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		changes, err := j.service.ApplyPricing(time.Now())
+		if err != nil {
+			slog.Error("pricing job failed", "error", err)
+		} else if len(changes) > 0 {
+			slog.Info("pricing job changed prices", "count", len(changes))
+		}
+	}
+}
+
+// parseClock parses "HH:MM" into minutes since midnight. "24:00" is accepted
+// so that a range can end at midnight.
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		if value == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+func (rule TimePriceRule) appliesTo(track Track) bool {
+	switch {
+	case rule.TrackID != 0:
+		return rule.TrackID == track.ID
+	case rule.Artist != "":
+		return strings.EqualFold(rule.Artist, track.Artist)
+	default:
+		return true
+	}
+}
+
+func (rule TimePriceRule) specificity() int {
+	switch {
+	case rule.TrackID != 0:
+		return 2
+	case rule.Artist != "":
+		return 1
+	default:
+		return 0
+	}
+}
+
+// activeAt reports whether at falls in the rule's time range, read in the
+// venue's time zone, or the rule's own when the play has no venue. A range
+// whose end is before its start runs past midnight, and the part after
+// midnight belongs to the weekday the range started on.
+func (rule TimePriceRule) activeAt(at time.Time, venueTimeZone string) bool {
+	timeZone := venueTimeZone
+	if timeZone == "" {
+		timeZone = rule.TimeZone
+	}
+	return inTimeRange(timeZone, rule.StartTime, rule.EndTime, rule.Weekdays, at)
+}
+
+func inTimeRange(timeZone, startTime, endTime string, weekdays []int, at time.Time) bool {
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return false
+	}
+	start, err := parseClock(startTime)
+	if err != nil {
+		return false
+	}
+	end, err := parseClock(endTime)
+	if err != nil {
+		return false
+	}
+
+	local := at.In(loc)
+	minute := local.Hour()*60 + local.Minute()
+	day := local.Weekday()
+
+	switch {
+	case start < end:
+		if minute < start || minute >= end {
+			return false
+		}
+	case minute >= start:
+	case minute < end:
+		day = (day + 6) % 7
+	default:
+		return false
+	}
+
+	return len(weekdays) == 0 || slices.Contains(weekdays, int(day))
+}
+
+func (rule TimePriceRule) apply(basePrice float64) float64 {
+	if rule.Price > 0 {
+		return rule.Price
+	}
+	return roundPrice(basePrice * (1 + rule.AdjustPercent/100))
+}
+
+// effectivePrice picks the most specific rule active at the given time
+// (track over artist over whole catalog, newest rule on ties). venueTimeZone
+// is the time zone of the venue the track plays at, if any.
+func effectivePrice(track Track, rules []TimePriceRule, at time.Time, venueTimeZone string) EffectivePrice {
+	result := EffectivePrice{TrackID: track.ID, BasePrice: track.Price, Price: track.Price, At: at}
+
+	var best *TimePriceRule
+	for i, rule := range rules {
+		if !rule.appliesTo(track) || !rule.activeAt(at, venueTimeZone) {
+			continue
+		}
+		if best == nil || rule.specificity() > best.specificity() ||
+			rule.specificity() == best.specificity() && rule.ID > best.ID {
+			best = &rules[i]
+		}
+	}
+
+	if best != nil {
+		result.Price = best.apply(track.Price)
+		result.RuleID = best.ID
+		result.RuleName = best.Name
+	}
+	return result
+}
@@ -9,6 +9,7 @@ package proto
 import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -58,11 +59,17 @@ func (*Empty) Descriptor() ([]byte, []int) {
 }
 
 type LogPlaybackRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
-	AmountPaid    float64                `protobuf:"fixed64,2,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	TrackId        int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	AmountPaid     float64                `protobuf:"fixed64,2,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
+	DeviceId       string                 `protobuf:"bytes,3,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	SessionId      string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CustomerId     string                 `protobuf:"bytes,5,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	PayWithCredit  bool                   `protobuf:"varint,6,opt,name=pay_with_credit,json=payWithCredit,proto3" json:"pay_with_credit,omitempty"`
+	ExternalSource string                 `protobuf:"bytes,7,opt,name=external_source,json=externalSource,proto3" json:"external_source,omitempty"`
+	ExternalId     string                 `protobuf:"bytes,8,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
 }
 
 func (x *LogPlaybackRequest) Reset() {
@@ -109,6 +116,48 @@ func (x *LogPlaybackRequest) GetAmountPaid() float64 {
 	return 0
 }
 
+func (x *LogPlaybackRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *LogPlaybackRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *LogPlaybackRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *LogPlaybackRequest) GetPayWithCredit() bool {
+	if x != nil {
+		return x.PayWithCredit
+	}
+	return false
+}
+
+func (x *LogPlaybackRequest) GetExternalSource() string {
+	if x != nil {
+		return x.ExternalSource
+	}
+	return ""
+}
+
+func (x *LogPlaybackRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
 type TopTrack struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
@@ -257,28 +306,3112 @@ func (x *UpdatePriceRequest) GetNewPrice() float64 {
 	return 0
 }
 
-var File_proto_analytics_proto protoreflect.FileDescriptor
+type TrendingTrack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	RecentCount   int32                  `protobuf:"varint,3,opt,name=recent_count,json=recentCount,proto3" json:"recent_count,omitempty"`
+	BaselineCount int32                  `protobuf:"varint,4,opt,name=baseline_count,json=baselineCount,proto3" json:"baseline_count,omitempty"`
+	Score         float64                `protobuf:"fixed64,5,opt,name=score,proto3" json:"score,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
 
-const file_proto_analytics_proto_rawDesc = "" +
-	"\n" +
-	"\x15proto/analytics.proto\x12\tanalytics\"\a\n" +
-	"\x05Empty\"P\n" +
-	"\x12LogPlaybackRequest\x12\x19\n" +
-	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x1f\n" +
-	"\vamount_paid\x18\x02 \x01(\x01R\n" +
-	"amountPaid\"6\n" +
-	"\bTopTrack\x12\x14\n" +
-	"\x05title\x18\x01 \x01(\tR\x05title\x12\x14\n" +
-	"\x05count\x18\x02 \x01(\x05R\x05count\"@\n" +
-	"\x11TopTracksResponse\x12+\n" +
-	"\x06tracks\x18\x01 \x03(\v2\x13.analytics.TopTrackR\x06tracks\"L\n" +
-	"\x12UpdatePriceRequest\x12\x19\n" +
-	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x1b\n" +
-	"\tnew_price\x18\x02 \x01(\x01R\bnewPrice2\xd2\x01\n" +
-	"\x10AnalyticsService\x12>\n" +
-	"\vLogPlayback\x12\x1d.analytics.LogPlaybackRequest\x1a\x10.analytics.Empty\x12>\n" +
-	"\fGetTopTracks\x12\x10.analytics.Empty\x1a\x1c.analytics.TopTracksResponse\x12>\n" +
-	"\vUpdatePrice\x12\x1d.analytics.UpdatePriceRequest\x1a\x10.analytics.EmptyB\x18Z\x16jukebox/analytic/protob\x06proto3"
+func (x *TrendingTrack) Reset() {
+	*x = TrendingTrack{}
+	mi := &file_proto_analytics_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrendingTrack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendingTrack) ProtoMessage() {}
+
+func (x *TrendingTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendingTrack.ProtoReflect.Descriptor instead.
+func (*TrendingTrack) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *TrendingTrack) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *TrendingTrack) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TrendingTrack) GetRecentCount() int32 {
+	if x != nil {
+		return x.RecentCount
+	}
+	return 0
+}
+
+func (x *TrendingTrack) GetBaselineCount() int32 {
+	if x != nil {
+		return x.BaselineCount
+	}
+	return 0
+}
+
+func (x *TrendingTrack) GetScore() float64 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+type TrendingTracksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tracks        []*TrendingTrack       `protobuf:"bytes,1,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrendingTracksResponse) Reset() {
+	*x = TrendingTracksResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrendingTracksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrendingTracksResponse) ProtoMessage() {}
+
+func (x *TrendingTracksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrendingTracksResponse.ProtoReflect.Descriptor instead.
+func (*TrendingTracksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *TrendingTracksResponse) GetTracks() []*TrendingTrack {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+type RelatedTracksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedTracksRequest) Reset() {
+	*x = RelatedTracksRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedTracksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedTracksRequest) ProtoMessage() {}
+
+func (x *RelatedTracksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedTracksRequest.ProtoReflect.Descriptor instead.
+func (*RelatedTracksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RelatedTracksRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+type RelatedTrack struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist        string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	Count         int32                  `protobuf:"varint,4,opt,name=count,proto3" json:"count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedTrack) Reset() {
+	*x = RelatedTrack{}
+	mi := &file_proto_analytics_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedTrack) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedTrack) ProtoMessage() {}
+
+func (x *RelatedTrack) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedTrack.ProtoReflect.Descriptor instead.
+func (*RelatedTrack) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RelatedTrack) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *RelatedTrack) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *RelatedTrack) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *RelatedTrack) GetCount() int32 {
+	if x != nil {
+		return x.Count
+	}
+	return 0
+}
+
+type RelatedTracksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tracks        []*RelatedTrack        `protobuf:"bytes,1,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RelatedTracksResponse) Reset() {
+	*x = RelatedTracksResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RelatedTracksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RelatedTracksResponse) ProtoMessage() {}
+
+func (x *RelatedTracksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RelatedTracksResponse.ProtoReflect.Descriptor instead.
+func (*RelatedTracksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *RelatedTracksResponse) GetTracks() []*RelatedTrack {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+type SchedulePriceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,2,opt,name=price,proto3" json:"price,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchedulePriceRequest) Reset() {
+	*x = SchedulePriceRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchedulePriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchedulePriceRequest) ProtoMessage() {}
+
+func (x *SchedulePriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchedulePriceRequest.ProtoReflect.Descriptor instead.
+func (*SchedulePriceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *SchedulePriceRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *SchedulePriceRequest) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *SchedulePriceRequest) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *SchedulePriceRequest) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+type ScheduledPrice struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	TrackId       int32                  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Price         float64                `protobuf:"fixed64,3,opt,name=price,proto3" json:"price,omitempty"`
+	StartsAt      *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=starts_at,json=startsAt,proto3" json:"starts_at,omitempty"`
+	EndsAt        *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=ends_at,json=endsAt,proto3" json:"ends_at,omitempty"`
+	Status        string                 `protobuf:"bytes,6,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledPrice) Reset() {
+	*x = ScheduledPrice{}
+	mi := &file_proto_analytics_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledPrice) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledPrice) ProtoMessage() {}
+
+func (x *ScheduledPrice) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledPrice.ProtoReflect.Descriptor instead.
+func (*ScheduledPrice) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ScheduledPrice) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *ScheduledPrice) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *ScheduledPrice) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *ScheduledPrice) GetStartsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartsAt
+	}
+	return nil
+}
+
+func (x *ScheduledPrice) GetEndsAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EndsAt
+	}
+	return nil
+}
+
+func (x *ScheduledPrice) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ListScheduledPricesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListScheduledPricesRequest) Reset() {
+	*x = ListScheduledPricesRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListScheduledPricesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListScheduledPricesRequest) ProtoMessage() {}
+
+func (x *ListScheduledPricesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListScheduledPricesRequest.ProtoReflect.Descriptor instead.
+func (*ListScheduledPricesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListScheduledPricesRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type ScheduledPricesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Prices        []*ScheduledPrice      `protobuf:"bytes,1,rep,name=prices,proto3" json:"prices,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ScheduledPricesResponse) Reset() {
+	*x = ScheduledPricesResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScheduledPricesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScheduledPricesResponse) ProtoMessage() {}
+
+func (x *ScheduledPricesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScheduledPricesResponse.ProtoReflect.Descriptor instead.
+func (*ScheduledPricesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ScheduledPricesResponse) GetPrices() []*ScheduledPrice {
+	if x != nil {
+		return x.Prices
+	}
+	return nil
+}
+
+type CancelScheduledPriceRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelScheduledPriceRequest) Reset() {
+	*x = CancelScheduledPriceRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelScheduledPriceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelScheduledPriceRequest) ProtoMessage() {}
+
+func (x *CancelScheduledPriceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelScheduledPriceRequest.ProtoReflect.Descriptor instead.
+func (*CancelScheduledPriceRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CancelScheduledPriceRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type VoidPlaybackRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	LogId          int32                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	Reason         string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	RefundedAmount *float64               `protobuf:"fixed64,3,opt,name=refunded_amount,json=refundedAmount,proto3,oneof" json:"refunded_amount,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VoidPlaybackRequest) Reset() {
+	*x = VoidPlaybackRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoidPlaybackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidPlaybackRequest) ProtoMessage() {}
+
+func (x *VoidPlaybackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidPlaybackRequest.ProtoReflect.Descriptor instead.
+func (*VoidPlaybackRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *VoidPlaybackRequest) GetLogId() int32 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *VoidPlaybackRequest) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *VoidPlaybackRequest) GetRefundedAmount() float64 {
+	if x != nil && x.RefundedAmount != nil {
+		return *x.RefundedAmount
+	}
+	return 0
+}
+
+type VoidPlaybackResponse struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	LogId          int32                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	TrackId        int32                  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	AmountPaid     float64                `protobuf:"fixed64,3,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
+	RefundedAmount float64                `protobuf:"fixed64,4,opt,name=refunded_amount,json=refundedAmount,proto3" json:"refunded_amount,omitempty"`
+	Reason         string                 `protobuf:"bytes,5,opt,name=reason,proto3" json:"reason,omitempty"`
+	VoidedAt       *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=voided_at,json=voidedAt,proto3" json:"voided_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *VoidPlaybackResponse) Reset() {
+	*x = VoidPlaybackResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *VoidPlaybackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*VoidPlaybackResponse) ProtoMessage() {}
+
+func (x *VoidPlaybackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use VoidPlaybackResponse.ProtoReflect.Descriptor instead.
+func (*VoidPlaybackResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *VoidPlaybackResponse) GetLogId() int32 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *VoidPlaybackResponse) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *VoidPlaybackResponse) GetAmountPaid() float64 {
+	if x != nil {
+		return x.AmountPaid
+	}
+	return 0
+}
+
+func (x *VoidPlaybackResponse) GetRefundedAmount() float64 {
+	if x != nil {
+		return x.RefundedAmount
+	}
+	return 0
+}
+
+func (x *VoidPlaybackResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *VoidPlaybackResponse) GetVoidedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.VoidedAt
+	}
+	return nil
+}
+
+type EnqueueTrackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	TrackId       int32                  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	AmountPaid    float64                `protobuf:"fixed64,3,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
+	SessionId     string                 `protobuf:"bytes,4,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *EnqueueTrackRequest) Reset() {
+	*x = EnqueueTrackRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *EnqueueTrackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*EnqueueTrackRequest) ProtoMessage() {}
+
+func (x *EnqueueTrackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use EnqueueTrackRequest.ProtoReflect.Descriptor instead.
+func (*EnqueueTrackRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *EnqueueTrackRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *EnqueueTrackRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *EnqueueTrackRequest) GetAmountPaid() float64 {
+	if x != nil {
+		return x.AmountPaid
+	}
+	return 0
+}
+
+func (x *EnqueueTrackRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type QueueItem struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	DeviceId      string                 `protobuf:"bytes,2,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	TrackId       int32                  `protobuf:"varint,3,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	AmountPaid    float64                `protobuf:"fixed64,4,opt,name=amount_paid,json=amountPaid,proto3" json:"amount_paid,omitempty"`
+	Status        string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	LogId         int32                  `protobuf:"varint,6,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	EnqueuedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=enqueued_at,json=enqueuedAt,proto3" json:"enqueued_at,omitempty"`
+	StartedAt     *timestamppb.Timestamp `protobuf:"bytes,8,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,9,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItem) Reset() {
+	*x = QueueItem{}
+	mi := &file_proto_analytics_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItem) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItem) ProtoMessage() {}
+
+func (x *QueueItem) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItem.ProtoReflect.Descriptor instead.
+func (*QueueItem) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *QueueItem) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *QueueItem) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *QueueItem) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *QueueItem) GetAmountPaid() float64 {
+	if x != nil {
+		return x.AmountPaid
+	}
+	return 0
+}
+
+func (x *QueueItem) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *QueueItem) GetLogId() int32 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *QueueItem) GetEnqueuedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.EnqueuedAt
+	}
+	return nil
+}
+
+func (x *QueueItem) GetStartedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.StartedAt
+	}
+	return nil
+}
+
+func (x *QueueItem) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+type ListQueueRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId      string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListQueueRequest) Reset() {
+	*x = ListQueueRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListQueueRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListQueueRequest) ProtoMessage() {}
+
+func (x *ListQueueRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListQueueRequest.ProtoReflect.Descriptor instead.
+func (*ListQueueRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *ListQueueRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+type QueueResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Items         []*QueueItem           `protobuf:"bytes,1,rep,name=items,proto3" json:"items,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueResponse) Reset() {
+	*x = QueueResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueResponse) ProtoMessage() {}
+
+func (x *QueueResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueResponse.ProtoReflect.Descriptor instead.
+func (*QueueResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *QueueResponse) GetItems() []*QueueItem {
+	if x != nil {
+		return x.Items
+	}
+	return nil
+}
+
+type QueueItemRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *QueueItemRequest) Reset() {
+	*x = QueueItemRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QueueItemRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QueueItemRequest) ProtoMessage() {}
+
+func (x *QueueItemRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QueueItemRequest.ProtoReflect.Descriptor instead.
+func (*QueueItemRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *QueueItemRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type CompletePlaybackRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	LogId           int32                  `protobuf:"varint,1,opt,name=log_id,json=logId,proto3" json:"log_id,omitempty"`
+	ListenedSeconds int32                  `protobuf:"varint,2,opt,name=listened_seconds,json=listenedSeconds,proto3" json:"listened_seconds,omitempty"`
+	Skipped         bool                   `protobuf:"varint,3,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CompletePlaybackRequest) Reset() {
+	*x = CompletePlaybackRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompletePlaybackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompletePlaybackRequest) ProtoMessage() {}
+
+func (x *CompletePlaybackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompletePlaybackRequest.ProtoReflect.Descriptor instead.
+func (*CompletePlaybackRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *CompletePlaybackRequest) GetLogId() int32 {
+	if x != nil {
+		return x.LogId
+	}
+	return 0
+}
+
+func (x *CompletePlaybackRequest) GetListenedSeconds() int32 {
+	if x != nil {
+		return x.ListenedSeconds
+	}
+	return 0
+}
+
+func (x *CompletePlaybackRequest) GetSkipped() bool {
+	if x != nil {
+		return x.Skipped
+	}
+	return false
+}
+
+type SkipStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkipStatsRequest) Reset() {
+	*x = SkipStatsRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipStatsRequest) ProtoMessage() {}
+
+func (x *SkipStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipStatsRequest.ProtoReflect.Descriptor instead.
+func (*SkipStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *SkipStatsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *SkipStatsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type SkipStat struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TrackId         int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	DurationSeconds int32                  `protobuf:"varint,3,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	Plays           int32                  `protobuf:"varint,4,opt,name=plays,proto3" json:"plays,omitempty"`
+	Skips           int32                  `protobuf:"varint,5,opt,name=skips,proto3" json:"skips,omitempty"`
+	SkipRate        float64                `protobuf:"fixed64,6,opt,name=skip_rate,json=skipRate,proto3" json:"skip_rate,omitempty"`
+	AvgCompletion   float64                `protobuf:"fixed64,7,opt,name=avg_completion,json=avgCompletion,proto3" json:"avg_completion,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *SkipStat) Reset() {
+	*x = SkipStat{}
+	mi := &file_proto_analytics_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipStat) ProtoMessage() {}
+
+func (x *SkipStat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipStat.ProtoReflect.Descriptor instead.
+func (*SkipStat) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *SkipStat) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *SkipStat) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *SkipStat) GetDurationSeconds() int32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *SkipStat) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *SkipStat) GetSkips() int32 {
+	if x != nil {
+		return x.Skips
+	}
+	return 0
+}
+
+func (x *SkipStat) GetSkipRate() float64 {
+	if x != nil {
+		return x.SkipRate
+	}
+	return 0
+}
+
+func (x *SkipStat) GetAvgCompletion() float64 {
+	if x != nil {
+		return x.AvgCompletion
+	}
+	return 0
+}
+
+type SkipStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tracks        []*SkipStat            `protobuf:"bytes,1,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SkipStatsResponse) Reset() {
+	*x = SkipStatsResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SkipStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SkipStatsResponse) ProtoMessage() {}
+
+func (x *SkipStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SkipStatsResponse.ProtoReflect.Descriptor instead.
+func (*SkipStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SkipStatsResponse) GetTracks() []*SkipStat {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+type HeartbeatRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	DeviceId        string                 `protobuf:"bytes,1,opt,name=device_id,json=deviceId,proto3" json:"device_id,omitempty"`
+	FirmwareVersion string                 `protobuf:"bytes,2,opt,name=firmware_version,json=firmwareVersion,proto3" json:"firmware_version,omitempty"`
+	VenueId         string                 `protobuf:"bytes,3,opt,name=venue_id,json=venueId,proto3" json:"venue_id,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HeartbeatRequest) Reset() {
+	*x = HeartbeatRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeartbeatRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeartbeatRequest) ProtoMessage() {}
+
+func (x *HeartbeatRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeartbeatRequest.ProtoReflect.Descriptor instead.
+func (*HeartbeatRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *HeartbeatRequest) GetDeviceId() string {
+	if x != nil {
+		return x.DeviceId
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetFirmwareVersion() string {
+	if x != nil {
+		return x.FirmwareVersion
+	}
+	return ""
+}
+
+func (x *HeartbeatRequest) GetVenueId() string {
+	if x != nil {
+		return x.VenueId
+	}
+	return ""
+}
+
+type SearchTracksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Query         string                 `protobuf:"bytes,1,opt,name=query,proto3" json:"query,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,3,opt,name=offset,proto3" json:"offset,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTracksRequest) Reset() {
+	*x = SearchTracksRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTracksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTracksRequest) ProtoMessage() {}
+
+func (x *SearchTracksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTracksRequest.ProtoReflect.Descriptor instead.
+func (*SearchTracksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *SearchTracksRequest) GetQuery() string {
+	if x != nil {
+		return x.Query
+	}
+	return ""
+}
+
+func (x *SearchTracksRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchTracksRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+type Track struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Id              int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Artist          string                 `protobuf:"bytes,3,opt,name=artist,proto3" json:"artist,omitempty"`
+	Price           float64                `protobuf:"fixed64,4,opt,name=price,proto3" json:"price,omitempty"`
+	DurationSeconds int32                  `protobuf:"varint,5,opt,name=duration_seconds,json=durationSeconds,proto3" json:"duration_seconds,omitempty"`
+	Isrc            string                 `protobuf:"bytes,6,opt,name=isrc,proto3" json:"isrc,omitempty"`
+	Album           string                 `protobuf:"bytes,7,opt,name=album,proto3" json:"album,omitempty"`
+	ReleaseYear     int32                  `protobuf:"varint,8,opt,name=release_year,json=releaseYear,proto3" json:"release_year,omitempty"`
+	Tags            []string               `protobuf:"bytes,9,rep,name=tags,proto3" json:"tags,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *Track) Reset() {
+	*x = Track{}
+	mi := &file_proto_analytics_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Track) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Track) ProtoMessage() {}
+
+func (x *Track) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Track.ProtoReflect.Descriptor instead.
+func (*Track) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *Track) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Track) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Track) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *Track) GetPrice() float64 {
+	if x != nil {
+		return x.Price
+	}
+	return 0
+}
+
+func (x *Track) GetDurationSeconds() int32 {
+	if x != nil {
+		return x.DurationSeconds
+	}
+	return 0
+}
+
+func (x *Track) GetIsrc() string {
+	if x != nil {
+		return x.Isrc
+	}
+	return ""
+}
+
+func (x *Track) GetAlbum() string {
+	if x != nil {
+		return x.Album
+	}
+	return ""
+}
+
+func (x *Track) GetReleaseYear() int32 {
+	if x != nil {
+		return x.ReleaseYear
+	}
+	return 0
+}
+
+func (x *Track) GetTags() []string {
+	if x != nil {
+		return x.Tags
+	}
+	return nil
+}
+
+type SearchTracksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tracks        []*Track               `protobuf:"bytes,1,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchTracksResponse) Reset() {
+	*x = SearchTracksResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchTracksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchTracksResponse) ProtoMessage() {}
+
+func (x *SearchTracksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchTracksResponse.ProtoReflect.Descriptor instead.
+func (*SearchTracksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *SearchTracksResponse) GetTracks() []*Track {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+func (x *SearchTracksResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type TopArtistsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sort          string                 `protobuf:"bytes,1,opt,name=sort,proto3" json:"sort,omitempty"`
+	Limit         int32                  `protobuf:"varint,2,opt,name=limit,proto3" json:"limit,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopArtistsRequest) Reset() {
+	*x = TopArtistsRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopArtistsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopArtistsRequest) ProtoMessage() {}
+
+func (x *TopArtistsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopArtistsRequest.ProtoReflect.Descriptor instead.
+func (*TopArtistsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *TopArtistsRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *TopArtistsRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *TopArtistsRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *TopArtistsRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type ArtistStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ArtistId      int32                  `protobuf:"varint,1,opt,name=artist_id,json=artistId,proto3" json:"artist_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtistStat) Reset() {
+	*x = ArtistStat{}
+	mi := &file_proto_analytics_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtistStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtistStat) ProtoMessage() {}
+
+func (x *ArtistStat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtistStat.ProtoReflect.Descriptor instead.
+func (*ArtistStat) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *ArtistStat) GetArtistId() int32 {
+	if x != nil {
+		return x.ArtistId
+	}
+	return 0
+}
+
+func (x *ArtistStat) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArtistStat) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *ArtistStat) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+type TopArtistsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Artists       []*ArtistStat          `protobuf:"bytes,1,rep,name=artists,proto3" json:"artists,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopArtistsResponse) Reset() {
+	*x = TopArtistsResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopArtistsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopArtistsResponse) ProtoMessage() {}
+
+func (x *TopArtistsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopArtistsResponse.ProtoReflect.Descriptor instead.
+func (*TopArtistsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *TopArtistsResponse) GetArtists() []*ArtistStat {
+	if x != nil {
+		return x.Artists
+	}
+	return nil
+}
+
+type ArtistTracksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ArtistId      int32                  `protobuf:"varint,1,opt,name=artist_id,json=artistId,proto3" json:"artist_id,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=to,proto3" json:"to,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtistTracksRequest) Reset() {
+	*x = ArtistTracksRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtistTracksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtistTracksRequest) ProtoMessage() {}
+
+func (x *ArtistTracksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtistTracksRequest.ProtoReflect.Descriptor instead.
+func (*ArtistTracksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *ArtistTracksRequest) GetArtistId() int32 {
+	if x != nil {
+		return x.ArtistId
+	}
+	return 0
+}
+
+func (x *ArtistTracksRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *ArtistTracksRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+type TrackStat struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title         string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackStat) Reset() {
+	*x = TrackStat{}
+	mi := &file_proto_analytics_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackStat) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackStat) ProtoMessage() {}
+
+func (x *TrackStat) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackStat.ProtoReflect.Descriptor instead.
+func (*TrackStat) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TrackStat) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *TrackStat) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *TrackStat) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *TrackStat) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+type ArtistTracksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ArtistId      int32                  `protobuf:"varint,1,opt,name=artist_id,json=artistId,proto3" json:"artist_id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	Tracks        []*TrackStat           `protobuf:"bytes,5,rep,name=tracks,proto3" json:"tracks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ArtistTracksResponse) Reset() {
+	*x = ArtistTracksResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ArtistTracksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ArtistTracksResponse) ProtoMessage() {}
+
+func (x *ArtistTracksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ArtistTracksResponse.ProtoReflect.Descriptor instead.
+func (*ArtistTracksResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *ArtistTracksResponse) GetArtistId() int32 {
+	if x != nil {
+		return x.ArtistId
+	}
+	return 0
+}
+
+func (x *ArtistTracksResponse) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *ArtistTracksResponse) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *ArtistTracksResponse) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *ArtistTracksResponse) GetTracks() []*TrackStat {
+	if x != nil {
+		return x.Tracks
+	}
+	return nil
+}
+
+type HeatmapRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	VenueId       string                 `protobuf:"bytes,1,opt,name=venue_id,json=venueId,proto3" json:"venue_id,omitempty"`
+	TrackId       int32                  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	ArtistId      int32                  `protobuf:"varint,3,opt,name=artist_id,json=artistId,proto3" json:"artist_id,omitempty"`
+	TimeZone      string                 `protobuf:"bytes,4,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	From          *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=from,proto3" json:"from,omitempty"`
+	To            *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=to,proto3" json:"to,omitempty"`
+	Tag           string                 `protobuf:"bytes,7,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeatmapRequest) Reset() {
+	*x = HeatmapRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeatmapRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeatmapRequest) ProtoMessage() {}
+
+func (x *HeatmapRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeatmapRequest.ProtoReflect.Descriptor instead.
+func (*HeatmapRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *HeatmapRequest) GetVenueId() string {
+	if x != nil {
+		return x.VenueId
+	}
+	return ""
+}
+
+func (x *HeatmapRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *HeatmapRequest) GetArtistId() int32 {
+	if x != nil {
+		return x.ArtistId
+	}
+	return 0
+}
+
+func (x *HeatmapRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *HeatmapRequest) GetFrom() *timestamppb.Timestamp {
+	if x != nil {
+		return x.From
+	}
+	return nil
+}
+
+func (x *HeatmapRequest) GetTo() *timestamppb.Timestamp {
+	if x != nil {
+		return x.To
+	}
+	return nil
+}
+
+func (x *HeatmapRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type HeatmapCell struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Weekday       int32                  `protobuf:"varint,1,opt,name=weekday,proto3" json:"weekday,omitempty"`
+	Hour          int32                  `protobuf:"varint,2,opt,name=hour,proto3" json:"hour,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeatmapCell) Reset() {
+	*x = HeatmapCell{}
+	mi := &file_proto_analytics_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeatmapCell) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeatmapCell) ProtoMessage() {}
+
+func (x *HeatmapCell) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeatmapCell.ProtoReflect.Descriptor instead.
+func (*HeatmapCell) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *HeatmapCell) GetWeekday() int32 {
+	if x != nil {
+		return x.Weekday
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetHour() int32 {
+	if x != nil {
+		return x.Hour
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *HeatmapCell) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+type HeatmapResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TimeZone      string                 `protobuf:"bytes,1,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	Cells         []*HeatmapCell         `protobuf:"bytes,2,rep,name=cells,proto3" json:"cells,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeatmapResponse) Reset() {
+	*x = HeatmapResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeatmapResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeatmapResponse) ProtoMessage() {}
+
+func (x *HeatmapResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeatmapResponse.ProtoReflect.Descriptor instead.
+func (*HeatmapResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *HeatmapResponse) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *HeatmapResponse) GetCells() []*HeatmapCell {
+	if x != nil {
+		return x.Cells
+	}
+	return nil
+}
+
+type GetChartRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetChartRequest) Reset() {
+	*x = GetChartRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetChartRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetChartRequest) ProtoMessage() {}
+
+func (x *GetChartRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetChartRequest.ProtoReflect.Descriptor instead.
+func (*GetChartRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *GetChartRequest) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+type ChartEntry struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	Position         int32                  `protobuf:"varint,1,opt,name=position,proto3" json:"position,omitempty"`
+	TrackId          int32                  `protobuf:"varint,2,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	Title            string                 `protobuf:"bytes,3,opt,name=title,proto3" json:"title,omitempty"`
+	Artist           string                 `protobuf:"bytes,4,opt,name=artist,proto3" json:"artist,omitempty"`
+	Plays            int32                  `protobuf:"varint,5,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue          float64                `protobuf:"fixed64,6,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	PreviousPosition int32                  `protobuf:"varint,7,opt,name=previous_position,json=previousPosition,proto3" json:"previous_position,omitempty"`
+	Movement         string                 `protobuf:"bytes,8,opt,name=movement,proto3" json:"movement,omitempty"`
+	WeeksOnChart     int32                  `protobuf:"varint,9,opt,name=weeks_on_chart,json=weeksOnChart,proto3" json:"weeks_on_chart,omitempty"`
+	PeakPosition     int32                  `protobuf:"varint,10,opt,name=peak_position,json=peakPosition,proto3" json:"peak_position,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ChartEntry) Reset() {
+	*x = ChartEntry{}
+	mi := &file_proto_analytics_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChartEntry) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChartEntry) ProtoMessage() {}
+
+func (x *ChartEntry) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChartEntry.ProtoReflect.Descriptor instead.
+func (*ChartEntry) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{40}
+}
+
+func (x *ChartEntry) GetPosition() int32 {
+	if x != nil {
+		return x.Position
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ChartEntry) GetArtist() string {
+	if x != nil {
+		return x.Artist
+	}
+	return ""
+}
+
+func (x *ChartEntry) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetPreviousPosition() int32 {
+	if x != nil {
+		return x.PreviousPosition
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetMovement() string {
+	if x != nil {
+		return x.Movement
+	}
+	return ""
+}
+
+func (x *ChartEntry) GetWeeksOnChart() int32 {
+	if x != nil {
+		return x.WeeksOnChart
+	}
+	return 0
+}
+
+func (x *ChartEntry) GetPeakPosition() int32 {
+	if x != nil {
+		return x.PeakPosition
+	}
+	return 0
+}
+
+type Chart struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            int32                  `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	PeriodStart   *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=period_start,json=periodStart,proto3" json:"period_start,omitempty"`
+	PeriodEnd     *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=period_end,json=periodEnd,proto3" json:"period_end,omitempty"`
+	Entries       []*ChartEntry          `protobuf:"bytes,4,rep,name=entries,proto3" json:"entries,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Chart) Reset() {
+	*x = Chart{}
+	mi := &file_proto_analytics_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Chart) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Chart) ProtoMessage() {}
+
+func (x *Chart) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Chart.ProtoReflect.Descriptor instead.
+func (*Chart) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *Chart) GetId() int32 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Chart) GetPeriodStart() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PeriodStart
+	}
+	return nil
+}
+
+func (x *Chart) GetPeriodEnd() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PeriodEnd
+	}
+	return nil
+}
+
+func (x *Chart) GetEntries() []*ChartEntry {
+	if x != nil {
+		return x.Entries
+	}
+	return nil
+}
+
+type TopUpCreditRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Amount        float64                `protobuf:"fixed64,2,opt,name=amount,proto3" json:"amount,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TopUpCreditRequest) Reset() {
+	*x = TopUpCreditRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TopUpCreditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TopUpCreditRequest) ProtoMessage() {}
+
+func (x *TopUpCreditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TopUpCreditRequest.ProtoReflect.Descriptor instead.
+func (*TopUpCreditRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *TopUpCreditRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *TopUpCreditRequest) GetAmount() float64 {
+	if x != nil {
+		return x.Amount
+	}
+	return 0
+}
+
+type GetCustomerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetCustomerRequest) Reset() {
+	*x = GetCustomerRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetCustomerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetCustomerRequest) ProtoMessage() {}
+
+func (x *GetCustomerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetCustomerRequest.ProtoReflect.Descriptor instead.
+func (*GetCustomerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *GetCustomerRequest) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+type Customer struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	CustomerId    string                 `protobuf:"bytes,1,opt,name=customer_id,json=customerId,proto3" json:"customer_id,omitempty"`
+	Balance       float64                `protobuf:"fixed64,2,opt,name=balance,proto3" json:"balance,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Customer) Reset() {
+	*x = Customer{}
+	mi := &file_proto_analytics_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Customer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Customer) ProtoMessage() {}
+
+func (x *Customer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Customer.ProtoReflect.Descriptor instead.
+func (*Customer) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *Customer) GetCustomerId() string {
+	if x != nil {
+		return x.CustomerId
+	}
+	return ""
+}
+
+func (x *Customer) GetBalance() float64 {
+	if x != nil {
+		return x.Balance
+	}
+	return 0
+}
+
+func (x *Customer) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+type LookupTrackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	ExternalId    string                 `protobuf:"bytes,2,opt,name=external_id,json=externalId,proto3" json:"external_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LookupTrackRequest) Reset() {
+	*x = LookupTrackRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LookupTrackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LookupTrackRequest) ProtoMessage() {}
+
+func (x *LookupTrackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LookupTrackRequest.ProtoReflect.Descriptor instead.
+func (*LookupTrackRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *LookupTrackRequest) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *LookupTrackRequest) GetExternalId() string {
+	if x != nil {
+		return x.ExternalId
+	}
+	return ""
+}
+
+type TagTopTracksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tag           string                 `protobuf:"bytes,1,opt,name=tag,proto3" json:"tag,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TagTopTracksRequest) Reset() {
+	*x = TagTopTracksRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TagTopTracksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TagTopTracksRequest) ProtoMessage() {}
+
+func (x *TagTopTracksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TagTopTracksRequest.ProtoReflect.Descriptor instead.
+func (*TagTopTracksRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *TagTopTracksRequest) GetTag() string {
+	if x != nil {
+		return x.Tag
+	}
+	return ""
+}
+
+type TrackStatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TrackId       int32                  `protobuf:"varint,1,opt,name=track_id,json=trackId,proto3" json:"track_id,omitempty"`
+	TimeZone      string                 `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackStatsRequest) Reset() {
+	*x = TrackStatsRequest{}
+	mi := &file_proto_analytics_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackStatsRequest) ProtoMessage() {}
+
+func (x *TrackStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackStatsRequest.ProtoReflect.Descriptor instead.
+func (*TrackStatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *TrackStatsRequest) GetTrackId() int32 {
+	if x != nil {
+		return x.TrackId
+	}
+	return 0
+}
+
+func (x *TrackStatsRequest) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+type DailyPlays struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Date          string                 `protobuf:"bytes,1,opt,name=date,proto3" json:"date,omitempty"`
+	Plays         int32                  `protobuf:"varint,2,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,3,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DailyPlays) Reset() {
+	*x = DailyPlays{}
+	mi := &file_proto_analytics_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DailyPlays) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DailyPlays) ProtoMessage() {}
+
+func (x *DailyPlays) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DailyPlays.ProtoReflect.Descriptor instead.
+func (*DailyPlays) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DailyPlays) GetDate() string {
+	if x != nil {
+		return x.Date
+	}
+	return ""
+}
+
+func (x *DailyPlays) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *DailyPlays) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+type TrackStatsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Track         *Track                 `protobuf:"bytes,1,opt,name=track,proto3" json:"track,omitempty"`
+	TimeZone      string                 `protobuf:"bytes,2,opt,name=time_zone,json=timeZone,proto3" json:"time_zone,omitempty"`
+	Plays         int32                  `protobuf:"varint,3,opt,name=plays,proto3" json:"plays,omitempty"`
+	Revenue       float64                `protobuf:"fixed64,4,opt,name=revenue,proto3" json:"revenue,omitempty"`
+	AveragePaid   float64                `protobuf:"fixed64,5,opt,name=average_paid,json=averagePaid,proto3" json:"average_paid,omitempty"`
+	FirstPlayedAt *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=first_played_at,json=firstPlayedAt,proto3" json:"first_played_at,omitempty"`
+	LastPlayedAt  *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=last_played_at,json=lastPlayedAt,proto3" json:"last_played_at,omitempty"`
+	Rank          int32                  `protobuf:"varint,8,opt,name=rank,proto3" json:"rank,omitempty"`
+	Days          []*DailyPlays          `protobuf:"bytes,9,rep,name=days,proto3" json:"days,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackStatsResponse) Reset() {
+	*x = TrackStatsResponse{}
+	mi := &file_proto_analytics_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackStatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackStatsResponse) ProtoMessage() {}
+
+func (x *TrackStatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_analytics_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackStatsResponse.ProtoReflect.Descriptor instead.
+func (*TrackStatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_analytics_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *TrackStatsResponse) GetTrack() *Track {
+	if x != nil {
+		return x.Track
+	}
+	return nil
+}
+
+func (x *TrackStatsResponse) GetTimeZone() string {
+	if x != nil {
+		return x.TimeZone
+	}
+	return ""
+}
+
+func (x *TrackStatsResponse) GetPlays() int32 {
+	if x != nil {
+		return x.Plays
+	}
+	return 0
+}
+
+func (x *TrackStatsResponse) GetRevenue() float64 {
+	if x != nil {
+		return x.Revenue
+	}
+	return 0
+}
+
+func (x *TrackStatsResponse) GetAveragePaid() float64 {
+	if x != nil {
+		return x.AveragePaid
+	}
+	return 0
+}
+
+func (x *TrackStatsResponse) GetFirstPlayedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FirstPlayedAt
+	}
+	return nil
+}
+
+func (x *TrackStatsResponse) GetLastPlayedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.LastPlayedAt
+	}
+	return nil
+}
+
+func (x *TrackStatsResponse) GetRank() int32 {
+	if x != nil {
+		return x.Rank
+	}
+	return 0
+}
+
+func (x *TrackStatsResponse) GetDays() []*DailyPlays {
+	if x != nil {
+		return x.Days
+	}
+	return nil
+}
+
+var File_proto_analytics_proto protoreflect.FileDescriptor
+
+const file_proto_analytics_proto_rawDesc = "" +
+	"\n" +
+	"\x15proto/analytics.proto\x12\tanalytics\x1a\x1fgoogle/protobuf/timestamp.proto\"\a\n" +
+	"\x05Empty\"\x9f\x02\n" +
+	"\x12LogPlaybackRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x1f\n" +
+	"\vamount_paid\x18\x02 \x01(\x01R\n" +
+	"amountPaid\x12\x1b\n" +
+	"\tdevice_id\x18\x03 \x01(\tR\bdeviceId\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vcustomer_id\x18\x05 \x01(\tR\n" +
+	"customerId\x12&\n" +
+	"\x0fpay_with_credit\x18\x06 \x01(\bR\rpayWithCredit\x12'\n" +
+	"\x0fexternal_source\x18\a \x01(\tR\x0eexternalSource\x12\x1f\n" +
+	"\vexternal_id\x18\b \x01(\tR\n" +
+	"externalId\"6\n" +
+	"\bTopTrack\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12\x14\n" +
+	"\x05count\x18\x02 \x01(\x05R\x05count\"@\n" +
+	"\x11TopTracksResponse\x12+\n" +
+	"\x06tracks\x18\x01 \x03(\v2\x13.analytics.TopTrackR\x06tracks\"L\n" +
+	"\x12UpdatePriceRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x1b\n" +
+	"\tnew_price\x18\x02 \x01(\x01R\bnewPrice\"\xa0\x01\n" +
+	"\rTrendingTrack\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12!\n" +
+	"\frecent_count\x18\x03 \x01(\x05R\vrecentCount\x12%\n" +
+	"\x0ebaseline_count\x18\x04 \x01(\x05R\rbaselineCount\x12\x14\n" +
+	"\x05score\x18\x05 \x01(\x01R\x05score\"J\n" +
+	"\x16TrendingTracksResponse\x120\n" +
+	"\x06tracks\x18\x01 \x03(\v2\x18.analytics.TrendingTrackR\x06tracks\"1\n" +
+	"\x14RelatedTracksRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\"m\n" +
+	"\fRelatedTrack\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x03 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05count\x18\x04 \x01(\x05R\x05count\"H\n" +
+	"\x15RelatedTracksResponse\x12/\n" +
+	"\x06tracks\x18\x01 \x03(\v2\x17.analytics.RelatedTrackR\x06tracks\"\xb5\x01\n" +
+	"\x14SchedulePriceRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05price\x18\x02 \x01(\x01R\x05price\x127\n" +
+	"\tstarts_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\"\xd7\x01\n" +
+	"\x0eScheduledPrice\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05price\x18\x03 \x01(\x01R\x05price\x127\n" +
+	"\tstarts_at\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\bstartsAt\x123\n" +
+	"\aends_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x06endsAt\x12\x16\n" +
+	"\x06status\x18\x06 \x01(\tR\x06status\"4\n" +
+	"\x1aListScheduledPricesRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"L\n" +
+	"\x17ScheduledPricesResponse\x121\n" +
+	"\x06prices\x18\x01 \x03(\v2\x19.analytics.ScheduledPriceR\x06prices\"-\n" +
+	"\x1bCancelScheduledPriceRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\x86\x01\n" +
+	"\x13VoidPlaybackRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x05R\x05logId\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12,\n" +
+	"\x0frefunded_amount\x18\x03 \x01(\x01H\x00R\x0erefundedAmount\x88\x01\x01B\x12\n" +
+	"\x10_refunded_amount\"\xe3\x01\n" +
+	"\x14VoidPlaybackResponse\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x05R\x05logId\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x1f\n" +
+	"\vamount_paid\x18\x03 \x01(\x01R\n" +
+	"amountPaid\x12'\n" +
+	"\x0frefunded_amount\x18\x04 \x01(\x01R\x0erefundedAmount\x12\x16\n" +
+	"\x06reason\x18\x05 \x01(\tR\x06reason\x127\n" +
+	"\tvoided_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\bvoidedAt\"\x8d\x01\n" +
+	"\x13EnqueueTrackRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x1f\n" +
+	"\vamount_paid\x18\x03 \x01(\x01R\n" +
+	"amountPaid\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x04 \x01(\tR\tsessionId\"\xd8\x02\n" +
+	"\tQueueItem\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x1b\n" +
+	"\tdevice_id\x18\x02 \x01(\tR\bdeviceId\x12\x19\n" +
+	"\btrack_id\x18\x03 \x01(\x05R\atrackId\x12\x1f\n" +
+	"\vamount_paid\x18\x04 \x01(\x01R\n" +
+	"amountPaid\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x15\n" +
+	"\x06log_id\x18\x06 \x01(\x05R\x05logId\x12;\n" +
+	"\venqueued_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"enqueuedAt\x129\n" +
+	"\n" +
+	"started_at\x18\b \x01(\v2\x1a.google.protobuf.TimestampR\tstartedAt\x12;\n" +
+	"\vfinished_at\x18\t \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt\"/\n" +
+	"\x10ListQueueRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\";\n" +
+	"\rQueueResponse\x12*\n" +
+	"\x05items\x18\x01 \x03(\v2\x14.analytics.QueueItemR\x05items\"\"\n" +
+	"\x10QueueItemRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"u\n" +
+	"\x17CompletePlaybackRequest\x12\x15\n" +
+	"\x06log_id\x18\x01 \x01(\x05R\x05logId\x12)\n" +
+	"\x10listened_seconds\x18\x02 \x01(\x05R\x0flistenedSeconds\x12\x18\n" +
+	"\askipped\x18\x03 \x01(\bR\askipped\"n\n" +
+	"\x10SkipStatsRequest\x12.\n" +
+	"\x04from\x18\x01 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"\xd6\x01\n" +
+	"\bSkipStat\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12)\n" +
+	"\x10duration_seconds\x18\x03 \x01(\x05R\x0fdurationSeconds\x12\x14\n" +
+	"\x05plays\x18\x04 \x01(\x05R\x05plays\x12\x14\n" +
+	"\x05skips\x18\x05 \x01(\x05R\x05skips\x12\x1b\n" +
+	"\tskip_rate\x18\x06 \x01(\x01R\bskipRate\x12%\n" +
+	"\x0eavg_completion\x18\a \x01(\x01R\ravgCompletion\"@\n" +
+	"\x11SkipStatsResponse\x12+\n" +
+	"\x06tracks\x18\x01 \x03(\v2\x13.analytics.SkipStatR\x06tracks\"u\n" +
+	"\x10HeartbeatRequest\x12\x1b\n" +
+	"\tdevice_id\x18\x01 \x01(\tR\bdeviceId\x12)\n" +
+	"\x10firmware_version\x18\x02 \x01(\tR\x0ffirmwareVersion\x12\x19\n" +
+	"\bvenue_id\x18\x03 \x01(\tR\avenueId\"Y\n" +
+	"\x13SearchTracksRequest\x12\x14\n" +
+	"\x05query\x18\x01 \x01(\tR\x05query\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12\x16\n" +
+	"\x06offset\x18\x03 \x01(\x05R\x06offset\"\xe7\x01\n" +
+	"\x05Track\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x03 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05price\x18\x04 \x01(\x01R\x05price\x12)\n" +
+	"\x10duration_seconds\x18\x05 \x01(\x05R\x0fdurationSeconds\x12\x12\n" +
+	"\x04isrc\x18\x06 \x01(\tR\x04isrc\x12\x14\n" +
+	"\x05album\x18\a \x01(\tR\x05album\x12!\n" +
+	"\frelease_year\x18\b \x01(\x05R\vreleaseYear\x12\x12\n" +
+	"\x04tags\x18\t \x03(\tR\x04tags\"V\n" +
+	"\x14SearchTracksResponse\x12(\n" +
+	"\x06tracks\x18\x01 \x03(\v2\x10.analytics.TrackR\x06tracks\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"\x99\x01\n" +
+	"\x11TopArtistsRequest\x12\x12\n" +
+	"\x04sort\x18\x01 \x01(\tR\x04sort\x12\x14\n" +
+	"\x05limit\x18\x02 \x01(\x05R\x05limit\x12.\n" +
+	"\x04from\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"m\n" +
+	"\n" +
+	"ArtistStat\x12\x1b\n" +
+	"\tartist_id\x18\x01 \x01(\x05R\bartistId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\"E\n" +
+	"\x12TopArtistsResponse\x12/\n" +
+	"\aartists\x18\x01 \x03(\v2\x15.analytics.ArtistStatR\aartists\"\x8e\x01\n" +
+	"\x13ArtistTracksRequest\x12\x1b\n" +
+	"\tartist_id\x18\x01 \x01(\x05R\bartistId\x12.\n" +
+	"\x04from\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\"l\n" +
+	"\tTrackStat\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\"\xa5\x01\n" +
+	"\x14ArtistTracksResponse\x12\x1b\n" +
+	"\tartist_id\x18\x01 \x01(\x05R\bartistId\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\x12,\n" +
+	"\x06tracks\x18\x05 \x03(\v2\x14.analytics.TrackStatR\x06tracks\"\xee\x01\n" +
+	"\x0eHeatmapRequest\x12\x19\n" +
+	"\bvenue_id\x18\x01 \x01(\tR\avenueId\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x1b\n" +
+	"\tartist_id\x18\x03 \x01(\x05R\bartistId\x12\x1b\n" +
+	"\ttime_zone\x18\x04 \x01(\tR\btimeZone\x12.\n" +
+	"\x04from\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\x04from\x12*\n" +
+	"\x02to\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\x02to\x12\x10\n" +
+	"\x03tag\x18\a \x01(\tR\x03tag\"k\n" +
+	"\vHeatmapCell\x12\x18\n" +
+	"\aweekday\x18\x01 \x01(\x05R\aweekday\x12\x12\n" +
+	"\x04hour\x18\x02 \x01(\x05R\x04hour\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\"\\\n" +
+	"\x0fHeatmapResponse\x12\x1b\n" +
+	"\ttime_zone\x18\x01 \x01(\tR\btimeZone\x12,\n" +
+	"\x05cells\x18\x02 \x03(\v2\x16.analytics.HeatmapCellR\x05cells\"!\n" +
+	"\x0fGetChartRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\"\xb5\x02\n" +
+	"\n" +
+	"ChartEntry\x12\x1a\n" +
+	"\bposition\x18\x01 \x01(\x05R\bposition\x12\x19\n" +
+	"\btrack_id\x18\x02 \x01(\x05R\atrackId\x12\x14\n" +
+	"\x05title\x18\x03 \x01(\tR\x05title\x12\x16\n" +
+	"\x06artist\x18\x04 \x01(\tR\x06artist\x12\x14\n" +
+	"\x05plays\x18\x05 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x06 \x01(\x01R\arevenue\x12+\n" +
+	"\x11previous_position\x18\a \x01(\x05R\x10previousPosition\x12\x1a\n" +
+	"\bmovement\x18\b \x01(\tR\bmovement\x12$\n" +
+	"\x0eweeks_on_chart\x18\t \x01(\x05R\fweeksOnChart\x12#\n" +
+	"\rpeak_position\x18\n" +
+	" \x01(\x05R\fpeakPosition\"\xc2\x01\n" +
+	"\x05Chart\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x05R\x02id\x12=\n" +
+	"\fperiod_start\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vperiodStart\x129\n" +
+	"\n" +
+	"period_end\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\tperiodEnd\x12/\n" +
+	"\aentries\x18\x04 \x03(\v2\x15.analytics.ChartEntryR\aentries\"M\n" +
+	"\x12TopUpCreditRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x16\n" +
+	"\x06amount\x18\x02 \x01(\x01R\x06amount\"5\n" +
+	"\x12GetCustomerRequest\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\"[\n" +
+	"\bCustomer\x12\x1f\n" +
+	"\vcustomer_id\x18\x01 \x01(\tR\n" +
+	"customerId\x12\x18\n" +
+	"\abalance\x18\x02 \x01(\x01R\abalance\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\"M\n" +
+	"\x12LookupTrackRequest\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x1f\n" +
+	"\vexternal_id\x18\x02 \x01(\tR\n" +
+	"externalId\"'\n" +
+	"\x13TagTopTracksRequest\x12\x10\n" +
+	"\x03tag\x18\x01 \x01(\tR\x03tag\"K\n" +
+	"\x11TrackStatsRequest\x12\x19\n" +
+	"\btrack_id\x18\x01 \x01(\x05R\atrackId\x12\x1b\n" +
+	"\ttime_zone\x18\x02 \x01(\tR\btimeZone\"P\n" +
+	"\n" +
+	"DailyPlays\x12\x12\n" +
+	"\x04date\x18\x01 \x01(\tR\x04date\x12\x14\n" +
+	"\x05plays\x18\x02 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x03 \x01(\x01R\arevenue\"\xf1\x02\n" +
+	"\x12TrackStatsResponse\x12&\n" +
+	"\x05track\x18\x01 \x01(\v2\x10.analytics.TrackR\x05track\x12\x1b\n" +
+	"\ttime_zone\x18\x02 \x01(\tR\btimeZone\x12\x14\n" +
+	"\x05plays\x18\x03 \x01(\x05R\x05plays\x12\x18\n" +
+	"\arevenue\x18\x04 \x01(\x01R\arevenue\x12!\n" +
+	"\faverage_paid\x18\x05 \x01(\x01R\vaveragePaid\x12B\n" +
+	"\x0ffirst_played_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\rfirstPlayedAt\x12@\n" +
+	"\x0elast_played_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\flastPlayedAt\x12\x12\n" +
+	"\x04rank\x18\b \x01(\x05R\x04rank\x12)\n" +
+	"\x04days\x18\t \x03(\v2\x15.analytics.DailyPlaysR\x04days2\xc6\x0f\n" +
+	"\x10AnalyticsService\x12>\n" +
+	"\vLogPlayback\x12\x1d.analytics.LogPlaybackRequest\x1a\x10.analytics.Empty\x12>\n" +
+	"\fGetTopTracks\x12\x10.analytics.Empty\x1a\x1c.analytics.TopTracksResponse\x12>\n" +
+	"\vUpdatePrice\x12\x1d.analytics.UpdatePriceRequest\x1a\x10.analytics.Empty\x12H\n" +
+	"\x11GetTrendingTracks\x12\x10.analytics.Empty\x1a!.analytics.TrendingTracksResponse\x12U\n" +
+	"\x10GetRelatedTracks\x12\x1f.analytics.RelatedTracksRequest\x1a .analytics.RelatedTracksResponse\x12K\n" +
+	"\rSchedulePrice\x12\x1f.analytics.SchedulePriceRequest\x1a\x19.analytics.ScheduledPrice\x12`\n" +
+	"\x13ListScheduledPrices\x12%.analytics.ListScheduledPricesRequest\x1a\".analytics.ScheduledPricesResponse\x12P\n" +
+	"\x14CancelScheduledPrice\x12&.analytics.CancelScheduledPriceRequest\x1a\x10.analytics.Empty\x12O\n" +
+	"\fVoidPlayback\x12\x1e.analytics.VoidPlaybackRequest\x1a\x1f.analytics.VoidPlaybackResponse\x12D\n" +
+	"\fEnqueueTrack\x12\x1e.analytics.EnqueueTrackRequest\x1a\x14.analytics.QueueItem\x12B\n" +
+	"\tListQueue\x12\x1b.analytics.ListQueueRequest\x1a\x18.analytics.QueueResponse\x12C\n" +
+	"\x0eStartQueueItem\x12\x1b.analytics.QueueItemRequest\x1a\x14.analytics.QueueItem\x12D\n" +
+	"\x0fFinishQueueItem\x12\x1b.analytics.QueueItemRequest\x1a\x14.analytics.QueueItem\x12B\n" +
+	"\rSkipQueueItem\x12\x1b.analytics.QueueItemRequest\x1a\x14.analytics.QueueItem\x12H\n" +
+	"\x10CompletePlayback\x12\".analytics.CompletePlaybackRequest\x1a\x10.analytics.Empty\x12I\n" +
+	"\fGetSkipStats\x12\x1b.analytics.SkipStatsRequest\x1a\x1c.analytics.SkipStatsResponse\x12:\n" +
+	"\tHeartbeat\x12\x1b.analytics.HeartbeatRequest\x1a\x10.analytics.Empty\x12O\n" +
+	"\fSearchTracks\x12\x1e.analytics.SearchTracksRequest\x1a\x1f.analytics.SearchTracksResponse\x12L\n" +
+	"\rGetTopArtists\x12\x1c.analytics.TopArtistsRequest\x1a\x1d.analytics.TopArtistsResponse\x12R\n" +
+	"\x0fGetArtistTracks\x12\x1e.analytics.ArtistTracksRequest\x1a\x1f.analytics.ArtistTracksResponse\x12C\n" +
+	"\n" +
+	"GetHeatmap\x12\x19.analytics.HeatmapRequest\x1a\x1a.analytics.HeatmapResponse\x128\n" +
+	"\bGetChart\x12\x1a.analytics.GetChartRequest\x1a\x10.analytics.Chart\x12A\n" +
+	"\vTopUpCredit\x12\x1d.analytics.TopUpCreditRequest\x1a\x13.analytics.Customer\x12A\n" +
+	"\vGetCustomer\x12\x1d.analytics.GetCustomerRequest\x1a\x13.analytics.Customer\x12>\n" +
+	"\vLookupTrack\x12\x1d.analytics.LookupTrackRequest\x1a\x10.analytics.Track\x12L\n" +
+	"\rGetTrackStats\x12\x1c.analytics.TrackStatsRequest\x1a\x1d.analytics.TrackStatsResponse\x12O\n" +
+	"\x0fGetTagTopTracks\x12\x1e.analytics.TagTopTracksRequest\x1a\x1c.analytics.TopTracksResponseB\x18Z\x16jukebox/analytic/protob\x06proto3"
 
 var (
 	file_proto_analytics_proto_rawDescOnce sync.Once
@@ -292,27 +3425,153 @@ func file_proto_analytics_proto_rawDescGZIP() []byte {
 	return file_proto_analytics_proto_rawDescData
 }
 
-var file_proto_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_proto_analytics_proto_msgTypes = make([]protoimpl.MessageInfo, 50)
 var file_proto_analytics_proto_goTypes = []any{
-	(*Empty)(nil),              // 0: analytics.Empty
-	(*LogPlaybackRequest)(nil), // 1: analytics.LogPlaybackRequest
-	(*TopTrack)(nil),           // 2: analytics.TopTrack
-	(*TopTracksResponse)(nil),  // 3: analytics.TopTracksResponse
-	(*UpdatePriceRequest)(nil), // 4: analytics.UpdatePriceRequest
+	(*Empty)(nil),                       // 0: analytics.Empty
+	(*LogPlaybackRequest)(nil),          // 1: analytics.LogPlaybackRequest
+	(*TopTrack)(nil),                    // 2: analytics.TopTrack
+	(*TopTracksResponse)(nil),           // 3: analytics.TopTracksResponse
+	(*UpdatePriceRequest)(nil),          // 4: analytics.UpdatePriceRequest
+	(*TrendingTrack)(nil),               // 5: analytics.TrendingTrack
+	(*TrendingTracksResponse)(nil),      // 6: analytics.TrendingTracksResponse
+	(*RelatedTracksRequest)(nil),        // 7: analytics.RelatedTracksRequest
+	(*RelatedTrack)(nil),                // 8: analytics.RelatedTrack
+	(*RelatedTracksResponse)(nil),       // 9: analytics.RelatedTracksResponse
+	(*SchedulePriceRequest)(nil),        // 10: analytics.SchedulePriceRequest
+	(*ScheduledPrice)(nil),              // 11: analytics.ScheduledPrice
+	(*ListScheduledPricesRequest)(nil),  // 12: analytics.ListScheduledPricesRequest
+	(*ScheduledPricesResponse)(nil),     // 13: analytics.ScheduledPricesResponse
+	(*CancelScheduledPriceRequest)(nil), // 14: analytics.CancelScheduledPriceRequest
+	(*VoidPlaybackRequest)(nil),         // 15: analytics.VoidPlaybackRequest
+	(*VoidPlaybackResponse)(nil),        // 16: analytics.VoidPlaybackResponse
+	(*EnqueueTrackRequest)(nil),         // 17: analytics.EnqueueTrackRequest
+	(*QueueItem)(nil),                   // 18: analytics.QueueItem
+	(*ListQueueRequest)(nil),            // 19: analytics.ListQueueRequest
+	(*QueueResponse)(nil),               // 20: analytics.QueueResponse
+	(*QueueItemRequest)(nil),            // 21: analytics.QueueItemRequest
+	(*CompletePlaybackRequest)(nil),     // 22: analytics.CompletePlaybackRequest
+	(*SkipStatsRequest)(nil),            // 23: analytics.SkipStatsRequest
+	(*SkipStat)(nil),                    // 24: analytics.SkipStat
+	(*SkipStatsResponse)(nil),           // 25: analytics.SkipStatsResponse
+	(*HeartbeatRequest)(nil),            // 26: analytics.HeartbeatRequest
+	(*SearchTracksRequest)(nil),         // 27: analytics.SearchTracksRequest
+	(*Track)(nil),                       // 28: analytics.Track
+	(*SearchTracksResponse)(nil),        // 29: analytics.SearchTracksResponse
+	(*TopArtistsRequest)(nil),           // 30: analytics.TopArtistsRequest
+	(*ArtistStat)(nil),                  // 31: analytics.ArtistStat
+	(*TopArtistsResponse)(nil),          // 32: analytics.TopArtistsResponse
+	(*ArtistTracksRequest)(nil),         // 33: analytics.ArtistTracksRequest
+	(*TrackStat)(nil),                   // 34: analytics.TrackStat
+	(*ArtistTracksResponse)(nil),        // 35: analytics.ArtistTracksResponse
+	(*HeatmapRequest)(nil),              // 36: analytics.HeatmapRequest
+	(*HeatmapCell)(nil),                 // 37: analytics.HeatmapCell
+	(*HeatmapResponse)(nil),             // 38: analytics.HeatmapResponse
+	(*GetChartRequest)(nil),             // 39: analytics.GetChartRequest
+	(*ChartEntry)(nil),                  // 40: analytics.ChartEntry
+	(*Chart)(nil),                       // 41: analytics.Chart
+	(*TopUpCreditRequest)(nil),          // 42: analytics.TopUpCreditRequest
+	(*GetCustomerRequest)(nil),          // 43: analytics.GetCustomerRequest
+	(*Customer)(nil),                    // 44: analytics.Customer
+	(*LookupTrackRequest)(nil),          // 45: analytics.LookupTrackRequest
+	(*TagTopTracksRequest)(nil),         // 46: analytics.TagTopTracksRequest
+	(*TrackStatsRequest)(nil),           // 47: analytics.TrackStatsRequest
+	(*DailyPlays)(nil),                  // 48: analytics.DailyPlays
+	(*TrackStatsResponse)(nil),          // 49: analytics.TrackStatsResponse
+	(*timestamppb.Timestamp)(nil),       // 50: google.protobuf.Timestamp
 }
 var file_proto_analytics_proto_depIdxs = []int32{
-	2, // 0: analytics.TopTracksResponse.tracks:type_name -> analytics.TopTrack
-	1, // 1: analytics.AnalyticsService.LogPlayback:input_type -> analytics.LogPlaybackRequest
-	0, // 2: analytics.AnalyticsService.GetTopTracks:input_type -> analytics.Empty
-	4, // 3: analytics.AnalyticsService.UpdatePrice:input_type -> analytics.UpdatePriceRequest
-	0, // 4: analytics.AnalyticsService.LogPlayback:output_type -> analytics.Empty
-	3, // 5: analytics.AnalyticsService.GetTopTracks:output_type -> analytics.TopTracksResponse
-	0, // 6: analytics.AnalyticsService.UpdatePrice:output_type -> analytics.Empty
-	4, // [4:7] is the sub-list for method output_type
-	1, // [1:4] is the sub-list for method input_type
-	1, // [1:1] is the sub-list for extension type_name
-	1, // [1:1] is the sub-list for extension extendee
-	0, // [0:1] is the sub-list for field type_name
+	2,  // 0: analytics.TopTracksResponse.tracks:type_name -> analytics.TopTrack
+	5,  // 1: analytics.TrendingTracksResponse.tracks:type_name -> analytics.TrendingTrack
+	8,  // 2: analytics.RelatedTracksResponse.tracks:type_name -> analytics.RelatedTrack
+	50, // 3: analytics.SchedulePriceRequest.starts_at:type_name -> google.protobuf.Timestamp
+	50, // 4: analytics.SchedulePriceRequest.ends_at:type_name -> google.protobuf.Timestamp
+	50, // 5: analytics.ScheduledPrice.starts_at:type_name -> google.protobuf.Timestamp
+	50, // 6: analytics.ScheduledPrice.ends_at:type_name -> google.protobuf.Timestamp
+	11, // 7: analytics.ScheduledPricesResponse.prices:type_name -> analytics.ScheduledPrice
+	50, // 8: analytics.VoidPlaybackResponse.voided_at:type_name -> google.protobuf.Timestamp
+	50, // 9: analytics.QueueItem.enqueued_at:type_name -> google.protobuf.Timestamp
+	50, // 10: analytics.QueueItem.started_at:type_name -> google.protobuf.Timestamp
+	50, // 11: analytics.QueueItem.finished_at:type_name -> google.protobuf.Timestamp
+	18, // 12: analytics.QueueResponse.items:type_name -> analytics.QueueItem
+	50, // 13: analytics.SkipStatsRequest.from:type_name -> google.protobuf.Timestamp
+	50, // 14: analytics.SkipStatsRequest.to:type_name -> google.protobuf.Timestamp
+	24, // 15: analytics.SkipStatsResponse.tracks:type_name -> analytics.SkipStat
+	28, // 16: analytics.SearchTracksResponse.tracks:type_name -> analytics.Track
+	50, // 17: analytics.TopArtistsRequest.from:type_name -> google.protobuf.Timestamp
+	50, // 18: analytics.TopArtistsRequest.to:type_name -> google.protobuf.Timestamp
+	31, // 19: analytics.TopArtistsResponse.artists:type_name -> analytics.ArtistStat
+	50, // 20: analytics.ArtistTracksRequest.from:type_name -> google.protobuf.Timestamp
+	50, // 21: analytics.ArtistTracksRequest.to:type_name -> google.protobuf.Timestamp
+	34, // 22: analytics.ArtistTracksResponse.tracks:type_name -> analytics.TrackStat
+	50, // 23: analytics.HeatmapRequest.from:type_name -> google.protobuf.Timestamp
+	50, // 24: analytics.HeatmapRequest.to:type_name -> google.protobuf.Timestamp
+	37, // 25: analytics.HeatmapResponse.cells:type_name -> analytics.HeatmapCell
+	50, // 26: analytics.Chart.period_start:type_name -> google.protobuf.Timestamp
+	50, // 27: analytics.Chart.period_end:type_name -> google.protobuf.Timestamp
+	40, // 28: analytics.Chart.entries:type_name -> analytics.ChartEntry
+	28, // 29: analytics.TrackStatsResponse.track:type_name -> analytics.Track
+	50, // 30: analytics.TrackStatsResponse.first_played_at:type_name -> google.protobuf.Timestamp
+	50, // 31: analytics.TrackStatsResponse.last_played_at:type_name -> google.protobuf.Timestamp
+	48, // 32: analytics.TrackStatsResponse.days:type_name -> analytics.DailyPlays
+	1,  // 33: analytics.AnalyticsService.LogPlayback:input_type -> analytics.LogPlaybackRequest
+	0,  // 34: analytics.AnalyticsService.GetTopTracks:input_type -> analytics.Empty
+	4,  // 35: analytics.AnalyticsService.UpdatePrice:input_type -> analytics.UpdatePriceRequest
+	0,  // 36: analytics.AnalyticsService.GetTrendingTracks:input_type -> analytics.Empty
+	7,  // 37: analytics.AnalyticsService.GetRelatedTracks:input_type -> analytics.RelatedTracksRequest
+	10, // 38: analytics.AnalyticsService.SchedulePrice:input_type -> analytics.SchedulePriceRequest
+	12, // 39: analytics.AnalyticsService.ListScheduledPrices:input_type -> analytics.ListScheduledPricesRequest
+	14, // 40: analytics.AnalyticsService.CancelScheduledPrice:input_type -> analytics.CancelScheduledPriceRequest
+	15, // 41: analytics.AnalyticsService.VoidPlayback:input_type -> analytics.VoidPlaybackRequest
+	17, // 42: analytics.AnalyticsService.EnqueueTrack:input_type -> analytics.EnqueueTrackRequest
+	19, // 43: analytics.AnalyticsService.ListQueue:input_type -> analytics.ListQueueRequest
+	21, // 44: analytics.AnalyticsService.StartQueueItem:input_type -> analytics.QueueItemRequest
+	21, // 45: analytics.AnalyticsService.FinishQueueItem:input_type -> analytics.QueueItemRequest
+	21, // 46: analytics.AnalyticsService.SkipQueueItem:input_type -> analytics.QueueItemRequest
+	22, // 47: analytics.AnalyticsService.CompletePlayback:input_type -> analytics.CompletePlaybackRequest
+	23, // 48: analytics.AnalyticsService.GetSkipStats:input_type -> analytics.SkipStatsRequest
+	26, // 49: analytics.AnalyticsService.Heartbeat:input_type -> analytics.HeartbeatRequest
+	27, // 50: analytics.AnalyticsService.SearchTracks:input_type -> analytics.SearchTracksRequest
+	30, // 51: analytics.AnalyticsService.GetTopArtists:input_type -> analytics.TopArtistsRequest
+	33, // 52: analytics.AnalyticsService.GetArtistTracks:input_type -> analytics.ArtistTracksRequest
+	36, // 53: analytics.AnalyticsService.GetHeatmap:input_type -> analytics.HeatmapRequest
+	39, // 54: analytics.AnalyticsService.GetChart:input_type -> analytics.GetChartRequest
+	42, // 55: analytics.AnalyticsService.TopUpCredit:input_type -> analytics.TopUpCreditRequest
+	43, // 56: analytics.AnalyticsService.GetCustomer:input_type -> analytics.GetCustomerRequest
+	45, // 57: analytics.AnalyticsService.LookupTrack:input_type -> analytics.LookupTrackRequest
+	47, // 58: analytics.AnalyticsService.GetTrackStats:input_type -> analytics.TrackStatsRequest
+	46, // 59: analytics.AnalyticsService.GetTagTopTracks:input_type -> analytics.TagTopTracksRequest
+	0,  // 60: analytics.AnalyticsService.LogPlayback:output_type -> analytics.Empty
+	3,  // 61: analytics.AnalyticsService.GetTopTracks:output_type -> analytics.TopTracksResponse
+	0,  // 62: analytics.AnalyticsService.UpdatePrice:output_type -> analytics.Empty
+	6,  // 63: analytics.AnalyticsService.GetTrendingTracks:output_type -> analytics.TrendingTracksResponse
+	9,  // 64: analytics.AnalyticsService.GetRelatedTracks:output_type -> analytics.RelatedTracksResponse
+	11, // 65: analytics.AnalyticsService.SchedulePrice:output_type -> analytics.ScheduledPrice
+	13, // 66: analytics.AnalyticsService.ListScheduledPrices:output_type -> analytics.ScheduledPricesResponse
+	0,  // 67: analytics.AnalyticsService.CancelScheduledPrice:output_type -> analytics.Empty
+	16, // 68: analytics.AnalyticsService.VoidPlayback:output_type -> analytics.VoidPlaybackResponse
+	18, // 69: analytics.AnalyticsService.EnqueueTrack:output_type -> analytics.QueueItem
+	20, // 70: analytics.AnalyticsService.ListQueue:output_type -> analytics.QueueResponse
+	18, // 71: analytics.AnalyticsService.StartQueueItem:output_type -> analytics.QueueItem
+	18, // 72: analytics.AnalyticsService.FinishQueueItem:output_type -> analytics.QueueItem
+	18, // 73: analytics.AnalyticsService.SkipQueueItem:output_type -> analytics.QueueItem
+	0,  // 74: analytics.AnalyticsService.CompletePlayback:output_type -> analytics.Empty
+	25, // 75: analytics.AnalyticsService.GetSkipStats:output_type -> analytics.SkipStatsResponse
+	0,  // 76: analytics.AnalyticsService.Heartbeat:output_type -> analytics.Empty
+	29, // 77: analytics.AnalyticsService.SearchTracks:output_type -> analytics.SearchTracksResponse
+	32, // 78: analytics.AnalyticsService.GetTopArtists:output_type -> analytics.TopArtistsResponse
+	35, // 79: analytics.AnalyticsService.GetArtistTracks:output_type -> analytics.ArtistTracksResponse
+	38, // 80: analytics.AnalyticsService.GetHeatmap:output_type -> analytics.HeatmapResponse
+	41, // 81: analytics.AnalyticsService.GetChart:output_type -> analytics.Chart
+	44, // 82: analytics.AnalyticsService.TopUpCredit:output_type -> analytics.Customer
+	44, // 83: analytics.AnalyticsService.GetCustomer:output_type -> analytics.Customer
+	28, // 84: analytics.AnalyticsService.LookupTrack:output_type -> analytics.Track
+	49, // 85: analytics.AnalyticsService.GetTrackStats:output_type -> analytics.TrackStatsResponse
+	3,  // 86: analytics.AnalyticsService.GetTagTopTracks:output_type -> analytics.TopTracksResponse
+	60, // [60:87] is the sub-list for method output_type
+	33, // [33:60] is the sub-list for method input_type
+	33, // [33:33] is the sub-list for extension type_name
+	33, // [33:33] is the sub-list for extension extendee
+	0,  // [0:33] is the sub-list for field type_name
 }
 
 func init() { file_proto_analytics_proto_init() }
@@ -320,13 +3579,14 @@ func file_proto_analytics_proto_init() {
 	if File_proto_analytics_proto != nil {
 		return
 	}
+	file_proto_analytics_proto_msgTypes[15].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_analytics_proto_rawDesc), len(file_proto_analytics_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   5,
+			NumMessages:   50,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,33 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	AnalyticsService_LogPlayback_FullMethodName  = "/analytics.AnalyticsService/LogPlayback"
-	AnalyticsService_GetTopTracks_FullMethodName = "/analytics.AnalyticsService/GetTopTracks"
-	AnalyticsService_UpdatePrice_FullMethodName  = "/analytics.AnalyticsService/UpdatePrice"
+	AnalyticsService_LogPlayback_FullMethodName          = "/analytics.AnalyticsService/LogPlayback"
+	AnalyticsService_GetTopTracks_FullMethodName         = "/analytics.AnalyticsService/GetTopTracks"
+	AnalyticsService_UpdatePrice_FullMethodName          = "/analytics.AnalyticsService/UpdatePrice"
+	AnalyticsService_GetTrendingTracks_FullMethodName    = "/analytics.AnalyticsService/GetTrendingTracks"
+	AnalyticsService_GetRelatedTracks_FullMethodName     = "/analytics.AnalyticsService/GetRelatedTracks"
+	AnalyticsService_SchedulePrice_FullMethodName        = "/analytics.AnalyticsService/SchedulePrice"
+	AnalyticsService_ListScheduledPrices_FullMethodName  = "/analytics.AnalyticsService/ListScheduledPrices"
+	AnalyticsService_CancelScheduledPrice_FullMethodName = "/analytics.AnalyticsService/CancelScheduledPrice"
+	AnalyticsService_VoidPlayback_FullMethodName         = "/analytics.AnalyticsService/VoidPlayback"
+	AnalyticsService_EnqueueTrack_FullMethodName         = "/analytics.AnalyticsService/EnqueueTrack"
+	AnalyticsService_ListQueue_FullMethodName            = "/analytics.AnalyticsService/ListQueue"
+	AnalyticsService_StartQueueItem_FullMethodName       = "/analytics.AnalyticsService/StartQueueItem"
+	AnalyticsService_FinishQueueItem_FullMethodName      = "/analytics.AnalyticsService/FinishQueueItem"
+	AnalyticsService_SkipQueueItem_FullMethodName        = "/analytics.AnalyticsService/SkipQueueItem"
+	AnalyticsService_CompletePlayback_FullMethodName     = "/analytics.AnalyticsService/CompletePlayback"
+	AnalyticsService_GetSkipStats_FullMethodName         = "/analytics.AnalyticsService/GetSkipStats"
+	AnalyticsService_Heartbeat_FullMethodName            = "/analytics.AnalyticsService/Heartbeat"
+	AnalyticsService_SearchTracks_FullMethodName         = "/analytics.AnalyticsService/SearchTracks"
+	AnalyticsService_GetTopArtists_FullMethodName        = "/analytics.AnalyticsService/GetTopArtists"
+	AnalyticsService_GetArtistTracks_FullMethodName      = "/analytics.AnalyticsService/GetArtistTracks"
+	AnalyticsService_GetHeatmap_FullMethodName           = "/analytics.AnalyticsService/GetHeatmap"
+	AnalyticsService_GetChart_FullMethodName             = "/analytics.AnalyticsService/GetChart"
+	AnalyticsService_TopUpCredit_FullMethodName          = "/analytics.AnalyticsService/TopUpCredit"
+	AnalyticsService_GetCustomer_FullMethodName          = "/analytics.AnalyticsService/GetCustomer"
+	AnalyticsService_LookupTrack_FullMethodName          = "/analytics.AnalyticsService/LookupTrack"
+	AnalyticsService_GetTrackStats_FullMethodName        = "/analytics.AnalyticsService/GetTrackStats"
+	AnalyticsService_GetTagTopTracks_FullMethodName      = "/analytics.AnalyticsService/GetTagTopTracks"
 )
 
 // AnalyticsServiceClient is the client API for AnalyticsService service.
@@ -31,6 +55,30 @@ type AnalyticsServiceClient interface {
 	LogPlayback(ctx context.Context, in *LogPlaybackRequest, opts ...grpc.CallOption) (*Empty, error)
 	GetTopTracks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TopTracksResponse, error)
 	UpdatePrice(ctx context.Context, in *UpdatePriceRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetTrendingTracks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrendingTracksResponse, error)
+	GetRelatedTracks(ctx context.Context, in *RelatedTracksRequest, opts ...grpc.CallOption) (*RelatedTracksResponse, error)
+	SchedulePrice(ctx context.Context, in *SchedulePriceRequest, opts ...grpc.CallOption) (*ScheduledPrice, error)
+	ListScheduledPrices(ctx context.Context, in *ListScheduledPricesRequest, opts ...grpc.CallOption) (*ScheduledPricesResponse, error)
+	CancelScheduledPrice(ctx context.Context, in *CancelScheduledPriceRequest, opts ...grpc.CallOption) (*Empty, error)
+	VoidPlayback(ctx context.Context, in *VoidPlaybackRequest, opts ...grpc.CallOption) (*VoidPlaybackResponse, error)
+	EnqueueTrack(ctx context.Context, in *EnqueueTrackRequest, opts ...grpc.CallOption) (*QueueItem, error)
+	ListQueue(ctx context.Context, in *ListQueueRequest, opts ...grpc.CallOption) (*QueueResponse, error)
+	StartQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error)
+	FinishQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error)
+	SkipQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error)
+	CompletePlayback(ctx context.Context, in *CompletePlaybackRequest, opts ...grpc.CallOption) (*Empty, error)
+	GetSkipStats(ctx context.Context, in *SkipStatsRequest, opts ...grpc.CallOption) (*SkipStatsResponse, error)
+	Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Empty, error)
+	SearchTracks(ctx context.Context, in *SearchTracksRequest, opts ...grpc.CallOption) (*SearchTracksResponse, error)
+	GetTopArtists(ctx context.Context, in *TopArtistsRequest, opts ...grpc.CallOption) (*TopArtistsResponse, error)
+	GetArtistTracks(ctx context.Context, in *ArtistTracksRequest, opts ...grpc.CallOption) (*ArtistTracksResponse, error)
+	GetHeatmap(ctx context.Context, in *HeatmapRequest, opts ...grpc.CallOption) (*HeatmapResponse, error)
+	GetChart(ctx context.Context, in *GetChartRequest, opts ...grpc.CallOption) (*Chart, error)
+	TopUpCredit(ctx context.Context, in *TopUpCreditRequest, opts ...grpc.CallOption) (*Customer, error)
+	GetCustomer(ctx context.Context, in *GetCustomerRequest, opts ...grpc.CallOption) (*Customer, error)
+	LookupTrack(ctx context.Context, in *LookupTrackRequest, opts ...grpc.CallOption) (*Track, error)
+	GetTrackStats(ctx context.Context, in *TrackStatsRequest, opts ...grpc.CallOption) (*TrackStatsResponse, error)
+	GetTagTopTracks(ctx context.Context, in *TagTopTracksRequest, opts ...grpc.CallOption) (*TopTracksResponse, error)
 }
 
 type analyticsServiceClient struct {
@@ -71,6 +119,246 @@ func (c *analyticsServiceClient) UpdatePrice(ctx context.Context, in *UpdatePric
 	return out, nil
 }
 
+func (c *analyticsServiceClient) GetTrendingTracks(ctx context.Context, in *Empty, opts ...grpc.CallOption) (*TrendingTracksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrendingTracksResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetTrendingTracks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetRelatedTracks(ctx context.Context, in *RelatedTracksRequest, opts ...grpc.CallOption) (*RelatedTracksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RelatedTracksResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetRelatedTracks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) SchedulePrice(ctx context.Context, in *SchedulePriceRequest, opts ...grpc.CallOption) (*ScheduledPrice, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledPrice)
+	err := c.cc.Invoke(ctx, AnalyticsService_SchedulePrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) ListScheduledPrices(ctx context.Context, in *ListScheduledPricesRequest, opts ...grpc.CallOption) (*ScheduledPricesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ScheduledPricesResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_ListScheduledPrices_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) CancelScheduledPrice(ctx context.Context, in *CancelScheduledPriceRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AnalyticsService_CancelScheduledPrice_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) VoidPlayback(ctx context.Context, in *VoidPlaybackRequest, opts ...grpc.CallOption) (*VoidPlaybackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(VoidPlaybackResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_VoidPlayback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) EnqueueTrack(ctx context.Context, in *EnqueueTrackRequest, opts ...grpc.CallOption) (*QueueItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueItem)
+	err := c.cc.Invoke(ctx, AnalyticsService_EnqueueTrack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) ListQueue(ctx context.Context, in *ListQueueRequest, opts ...grpc.CallOption) (*QueueResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_ListQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) StartQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueItem)
+	err := c.cc.Invoke(ctx, AnalyticsService_StartQueueItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) FinishQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueItem)
+	err := c.cc.Invoke(ctx, AnalyticsService_FinishQueueItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) SkipQueueItem(ctx context.Context, in *QueueItemRequest, opts ...grpc.CallOption) (*QueueItem, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(QueueItem)
+	err := c.cc.Invoke(ctx, AnalyticsService_SkipQueueItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) CompletePlayback(ctx context.Context, in *CompletePlaybackRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AnalyticsService_CompletePlayback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetSkipStats(ctx context.Context, in *SkipStatsRequest, opts ...grpc.CallOption) (*SkipStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SkipStatsResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetSkipStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) Heartbeat(ctx context.Context, in *HeartbeatRequest, opts ...grpc.CallOption) (*Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Empty)
+	err := c.cc.Invoke(ctx, AnalyticsService_Heartbeat_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) SearchTracks(ctx context.Context, in *SearchTracksRequest, opts ...grpc.CallOption) (*SearchTracksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchTracksResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_SearchTracks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetTopArtists(ctx context.Context, in *TopArtistsRequest, opts ...grpc.CallOption) (*TopArtistsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopArtistsResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetTopArtists_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetArtistTracks(ctx context.Context, in *ArtistTracksRequest, opts ...grpc.CallOption) (*ArtistTracksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ArtistTracksResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetArtistTracks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetHeatmap(ctx context.Context, in *HeatmapRequest, opts ...grpc.CallOption) (*HeatmapResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HeatmapResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetHeatmap_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetChart(ctx context.Context, in *GetChartRequest, opts ...grpc.CallOption) (*Chart, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Chart)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetChart_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) TopUpCredit(ctx context.Context, in *TopUpCreditRequest, opts ...grpc.CallOption) (*Customer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, AnalyticsService_TopUpCredit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetCustomer(ctx context.Context, in *GetCustomerRequest, opts ...grpc.CallOption) (*Customer, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Customer)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetCustomer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) LookupTrack(ctx context.Context, in *LookupTrackRequest, opts ...grpc.CallOption) (*Track, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Track)
+	err := c.cc.Invoke(ctx, AnalyticsService_LookupTrack_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetTrackStats(ctx context.Context, in *TrackStatsRequest, opts ...grpc.CallOption) (*TrackStatsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrackStatsResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetTrackStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *analyticsServiceClient) GetTagTopTracks(ctx context.Context, in *TagTopTracksRequest, opts ...grpc.CallOption) (*TopTracksResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TopTracksResponse)
+	err := c.cc.Invoke(ctx, AnalyticsService_GetTagTopTracks_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AnalyticsServiceServer is the server API for AnalyticsService service.
 // All implementations must embed UnimplementedAnalyticsServiceServer
 // for forward compatibility.
@@ -78,6 +366,30 @@ type AnalyticsServiceServer interface {
 	LogPlayback(context.Context, *LogPlaybackRequest) (*Empty, error)
 	GetTopTracks(context.Context, *Empty) (*TopTracksResponse, error)
 	UpdatePrice(context.Context, *UpdatePriceRequest) (*Empty, error)
+	GetTrendingTracks(context.Context, *Empty) (*TrendingTracksResponse, error)
+	GetRelatedTracks(context.Context, *RelatedTracksRequest) (*RelatedTracksResponse, error)
+	SchedulePrice(context.Context, *SchedulePriceRequest) (*ScheduledPrice, error)
+	ListScheduledPrices(context.Context, *ListScheduledPricesRequest) (*ScheduledPricesResponse, error)
+	CancelScheduledPrice(context.Context, *CancelScheduledPriceRequest) (*Empty, error)
+	VoidPlayback(context.Context, *VoidPlaybackRequest) (*VoidPlaybackResponse, error)
+	EnqueueTrack(context.Context, *EnqueueTrackRequest) (*QueueItem, error)
+	ListQueue(context.Context, *ListQueueRequest) (*QueueResponse, error)
+	StartQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error)
+	FinishQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error)
+	SkipQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error)
+	CompletePlayback(context.Context, *CompletePlaybackRequest) (*Empty, error)
+	GetSkipStats(context.Context, *SkipStatsRequest) (*SkipStatsResponse, error)
+	Heartbeat(context.Context, *HeartbeatRequest) (*Empty, error)
+	SearchTracks(context.Context, *SearchTracksRequest) (*SearchTracksResponse, error)
+	GetTopArtists(context.Context, *TopArtistsRequest) (*TopArtistsResponse, error)
+	GetArtistTracks(context.Context, *ArtistTracksRequest) (*ArtistTracksResponse, error)
+	GetHeatmap(context.Context, *HeatmapRequest) (*HeatmapResponse, error)
+	GetChart(context.Context, *GetChartRequest) (*Chart, error)
+	TopUpCredit(context.Context, *TopUpCreditRequest) (*Customer, error)
+	GetCustomer(context.Context, *GetCustomerRequest) (*Customer, error)
+	LookupTrack(context.Context, *LookupTrackRequest) (*Track, error)
+	GetTrackStats(context.Context, *TrackStatsRequest) (*TrackStatsResponse, error)
+	GetTagTopTracks(context.Context, *TagTopTracksRequest) (*TopTracksResponse, error)
 	mustEmbedUnimplementedAnalyticsServiceServer()
 }
 
@@ -97,6 +409,78 @@ func (UnimplementedAnalyticsServiceServer) GetTopTracks(context.Context, *Empty)
 func (UnimplementedAnalyticsServiceServer) UpdatePrice(context.Context, *UpdatePriceRequest) (*Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method UpdatePrice not implemented")
 }
+func (UnimplementedAnalyticsServiceServer) GetTrendingTracks(context.Context, *Empty) (*TrendingTracksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTrendingTracks not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetRelatedTracks(context.Context, *RelatedTracksRequest) (*RelatedTracksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRelatedTracks not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) SchedulePrice(context.Context, *SchedulePriceRequest) (*ScheduledPrice, error) {
+	return nil, status.Error(codes.Unimplemented, "method SchedulePrice not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) ListScheduledPrices(context.Context, *ListScheduledPricesRequest) (*ScheduledPricesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListScheduledPrices not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) CancelScheduledPrice(context.Context, *CancelScheduledPriceRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method CancelScheduledPrice not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) VoidPlayback(context.Context, *VoidPlaybackRequest) (*VoidPlaybackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method VoidPlayback not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) EnqueueTrack(context.Context, *EnqueueTrackRequest) (*QueueItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method EnqueueTrack not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) ListQueue(context.Context, *ListQueueRequest) (*QueueResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListQueue not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) StartQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method StartQueueItem not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) FinishQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method FinishQueueItem not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) SkipQueueItem(context.Context, *QueueItemRequest) (*QueueItem, error) {
+	return nil, status.Error(codes.Unimplemented, "method SkipQueueItem not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) CompletePlayback(context.Context, *CompletePlaybackRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method CompletePlayback not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetSkipStats(context.Context, *SkipStatsRequest) (*SkipStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetSkipStats not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) Heartbeat(context.Context, *HeartbeatRequest) (*Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method Heartbeat not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) SearchTracks(context.Context, *SearchTracksRequest) (*SearchTracksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method SearchTracks not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetTopArtists(context.Context, *TopArtistsRequest) (*TopArtistsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTopArtists not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetArtistTracks(context.Context, *ArtistTracksRequest) (*ArtistTracksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetArtistTracks not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetHeatmap(context.Context, *HeatmapRequest) (*HeatmapResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetHeatmap not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetChart(context.Context, *GetChartRequest) (*Chart, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetChart not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) TopUpCredit(context.Context, *TopUpCreditRequest) (*Customer, error) {
+	return nil, status.Error(codes.Unimplemented, "method TopUpCredit not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetCustomer(context.Context, *GetCustomerRequest) (*Customer, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetCustomer not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) LookupTrack(context.Context, *LookupTrackRequest) (*Track, error) {
+	return nil, status.Error(codes.Unimplemented, "method LookupTrack not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetTrackStats(context.Context, *TrackStatsRequest) (*TrackStatsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTrackStats not implemented")
+}
+func (UnimplementedAnalyticsServiceServer) GetTagTopTracks(context.Context, *TagTopTracksRequest) (*TopTracksResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetTagTopTracks not implemented")
+}
 func (UnimplementedAnalyticsServiceServer) mustEmbedUnimplementedAnalyticsServiceServer() {}
 func (UnimplementedAnalyticsServiceServer) testEmbeddedByValue()                          {}
 
@@ -172,24 +556,552 @@ func _AnalyticsService_UpdatePrice_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
-// AnalyticsService_ServiceDesc is the grpc.ServiceDesc for AnalyticsService service.
-// It's only intended for direct use with grpc.RegisterService,
-// and not to be introspected or modified (even as a copy)
-var AnalyticsService_ServiceDesc = grpc.ServiceDesc{
-	ServiceName: "analytics.AnalyticsService",
-	HandlerType: (*AnalyticsServiceServer)(nil),
-	Methods: []grpc.MethodDesc{
-		{
-			MethodName: "LogPlayback",
-			Handler:    _AnalyticsService_LogPlayback_Handler,
-		},
-		{
-			MethodName: "GetTopTracks",
-			Handler:    _AnalyticsService_GetTopTracks_Handler,
-		},
-		{
-			MethodName: "UpdatePrice",
-			Handler:    _AnalyticsService_UpdatePrice_Handler,
+func _AnalyticsService_GetTrendingTracks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetTrendingTracks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetTrendingTracks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetTrendingTracks(ctx, req.(*Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetRelatedTracks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RelatedTracksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetRelatedTracks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetRelatedTracks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetRelatedTracks(ctx, req.(*RelatedTracksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_SchedulePrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchedulePriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).SchedulePrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_SchedulePrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).SchedulePrice(ctx, req.(*SchedulePriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_ListScheduledPrices_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListScheduledPricesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).ListScheduledPrices(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_ListScheduledPrices_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).ListScheduledPrices(ctx, req.(*ListScheduledPricesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_CancelScheduledPrice_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CancelScheduledPriceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).CancelScheduledPrice(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_CancelScheduledPrice_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).CancelScheduledPrice(ctx, req.(*CancelScheduledPriceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_VoidPlayback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(VoidPlaybackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).VoidPlayback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_VoidPlayback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).VoidPlayback(ctx, req.(*VoidPlaybackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_EnqueueTrack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(EnqueueTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).EnqueueTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_EnqueueTrack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).EnqueueTrack(ctx, req.(*EnqueueTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_ListQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).ListQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_ListQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).ListQueue(ctx, req.(*ListQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_StartQueueItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).StartQueueItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_StartQueueItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).StartQueueItem(ctx, req.(*QueueItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_FinishQueueItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).FinishQueueItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_FinishQueueItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).FinishQueueItem(ctx, req.(*QueueItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_SkipQueueItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(QueueItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).SkipQueueItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_SkipQueueItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).SkipQueueItem(ctx, req.(*QueueItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_CompletePlayback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompletePlaybackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).CompletePlayback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_CompletePlayback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).CompletePlayback(ctx, req.(*CompletePlaybackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetSkipStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SkipStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetSkipStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetSkipStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetSkipStats(ctx, req.(*SkipStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_Heartbeat_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeartbeatRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).Heartbeat(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_Heartbeat_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).Heartbeat(ctx, req.(*HeartbeatRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_SearchTracks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchTracksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).SearchTracks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_SearchTracks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).SearchTracks(ctx, req.(*SearchTracksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetTopArtists_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopArtistsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetTopArtists(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetTopArtists_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetTopArtists(ctx, req.(*TopArtistsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetArtistTracks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ArtistTracksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetArtistTracks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetArtistTracks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetArtistTracks(ctx, req.(*ArtistTracksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetHeatmap_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HeatmapRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetHeatmap(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetHeatmap_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetHeatmap(ctx, req.(*HeatmapRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetChart_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetChartRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetChart(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetChart_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetChart(ctx, req.(*GetChartRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_TopUpCredit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TopUpCreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).TopUpCredit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_TopUpCredit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).TopUpCredit(ctx, req.(*TopUpCreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetCustomer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetCustomerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetCustomer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetCustomer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetCustomer(ctx, req.(*GetCustomerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_LookupTrack_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(LookupTrackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).LookupTrack(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_LookupTrack_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).LookupTrack(ctx, req.(*LookupTrackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetTrackStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetTrackStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetTrackStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetTrackStats(ctx, req.(*TrackStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _AnalyticsService_GetTagTopTracks_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TagTopTracksRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AnalyticsServiceServer).GetTagTopTracks(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: AnalyticsService_GetTagTopTracks_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AnalyticsServiceServer).GetTagTopTracks(ctx, req.(*TagTopTracksRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// AnalyticsService_ServiceDesc is the grpc.ServiceDesc for AnalyticsService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var AnalyticsService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "analytics.AnalyticsService",
+	HandlerType: (*AnalyticsServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "LogPlayback",
+			Handler:    _AnalyticsService_LogPlayback_Handler,
+		},
+		{
+			MethodName: "GetTopTracks",
+			Handler:    _AnalyticsService_GetTopTracks_Handler,
+		},
+		{
+			MethodName: "UpdatePrice",
+			Handler:    _AnalyticsService_UpdatePrice_Handler,
+		},
+		{
+			MethodName: "GetTrendingTracks",
+			Handler:    _AnalyticsService_GetTrendingTracks_Handler,
+		},
+		{
+			MethodName: "GetRelatedTracks",
+			Handler:    _AnalyticsService_GetRelatedTracks_Handler,
+		},
+		{
+			MethodName: "SchedulePrice",
+			Handler:    _AnalyticsService_SchedulePrice_Handler,
+		},
+		{
+			MethodName: "ListScheduledPrices",
+			Handler:    _AnalyticsService_ListScheduledPrices_Handler,
+		},
+		{
+			MethodName: "CancelScheduledPrice",
+			Handler:    _AnalyticsService_CancelScheduledPrice_Handler,
+		},
+		{
+			MethodName: "VoidPlayback",
+			Handler:    _AnalyticsService_VoidPlayback_Handler,
+		},
+		{
+			MethodName: "EnqueueTrack",
+			Handler:    _AnalyticsService_EnqueueTrack_Handler,
+		},
+		{
+			MethodName: "ListQueue",
+			Handler:    _AnalyticsService_ListQueue_Handler,
+		},
+		{
+			MethodName: "StartQueueItem",
+			Handler:    _AnalyticsService_StartQueueItem_Handler,
+		},
+		{
+			MethodName: "FinishQueueItem",
+			Handler:    _AnalyticsService_FinishQueueItem_Handler,
+		},
+		{
+			MethodName: "SkipQueueItem",
+			Handler:    _AnalyticsService_SkipQueueItem_Handler,
+		},
+		{
+			MethodName: "CompletePlayback",
+			Handler:    _AnalyticsService_CompletePlayback_Handler,
+		},
+		{
+			MethodName: "GetSkipStats",
+			Handler:    _AnalyticsService_GetSkipStats_Handler,
+		},
+		{
+			MethodName: "Heartbeat",
+			Handler:    _AnalyticsService_Heartbeat_Handler,
+		},
+		{
+			MethodName: "SearchTracks",
+			Handler:    _AnalyticsService_SearchTracks_Handler,
+		},
+		{
+			MethodName: "GetTopArtists",
+			Handler:    _AnalyticsService_GetTopArtists_Handler,
+		},
+		{
+			MethodName: "GetArtistTracks",
+			Handler:    _AnalyticsService_GetArtistTracks_Handler,
+		},
+		{
+			MethodName: "GetHeatmap",
+			Handler:    _AnalyticsService_GetHeatmap_Handler,
+		},
+		{
+			MethodName: "GetChart",
+			Handler:    _AnalyticsService_GetChart_Handler,
+		},
+		{
+			MethodName: "TopUpCredit",
+			Handler:    _AnalyticsService_TopUpCredit_Handler,
+		},
+		{
+			MethodName: "GetCustomer",
+			Handler:    _AnalyticsService_GetCustomer_Handler,
+		},
+		{
+			MethodName: "LookupTrack",
+			Handler:    _AnalyticsService_LookupTrack_Handler,
+		},
+		{
+			MethodName: "GetTrackStats",
+			Handler:    _AnalyticsService_GetTrackStats_Handler,
+		},
+		{
+			MethodName: "GetTagTopTracks",
+			Handler:    _AnalyticsService_GetTagTopTracks_Handler,
 		},
 	},
 	Streams:  []grpc.StreamDesc{},
@@ -0,0 +1,10 @@
+package main
+
+const (
+	QueueStatusQueued   = "queued"
+	QueueStatusPlaying  = "playing"
+	QueueStatusFinished = "finished"
+	QueueStatusSkipped  = "skipped"
+
+	skippedBeforePlayingReason = "skipped before playing"
+)
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	cooccurrenceBatchSize = 1000
+	cooccurrenceNeighbors = 3
+	cooccurrenceGap       = 2 * time.Hour
+)
+
+// CooccurrenceJob incrementally counts how often tracks are played close to
+// each other on the same session (or device, when no session is reported).
+// Each new log is paired with the few plays that preceded it, so every pair
+// is counted exactly once no matter how the logs are split into batches.
+type CooccurrenceJob struct {
+	repo IRepository
+}
+
+func NewCooccurrenceJob(repo IRepository) *CooccurrenceJob {
+	return &CooccurrenceJob{repo: repo}
+}
+
+func (j *CooccurrenceJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.RunOnce(); err != nil {
+			slog.Error("co-occurrence job failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce processes every log added since the previous run and returns how
+// many logs were processed.
+func (j *CooccurrenceJob) RunOnce() (int, error) {
+	lastID, err := j.repo.GetCooccurrenceCursor()
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+	for {
+		logs, err := j.repo.GetLogsAfter(lastID, cooccurrenceBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(logs) == 0 {
+			return total, nil
+		}
+
+		var pairs []TrackPair
+		for _, log := range logs {
+			if log.DeviceID == "" && log.SessionID == "" || !pairable(log) {
+				continue
+			}
+			previous, err := j.repo.GetSessionLogsBefore(log, log.PlayedAt.Add(-cooccurrenceGap), cooccurrenceNeighbors)
+			if err != nil {
+				return total, err
+			}
+			for _, prev := range previous {
+				if prev.TrackID == log.TrackID || !pairable(prev) {
+					continue
+				}
+				pairs = append(pairs,
+					TrackPair{TrackID: log.TrackID, RelatedTrackID: prev.TrackID},
+					TrackPair{TrackID: prev.TrackID, RelatedTrackID: log.TrackID},
+				)
+			}
+		}
+
+		lastID = logs[len(logs)-1].ID
+		if err := j.repo.AddCooccurrences(pairs, lastID); err != nil {
+			return total, err
+		}
+		total += len(logs)
+	}
+}
+
+// pairable reports whether a log counts toward co-occurrence: voided plays
+// and plays quarantined as anomalous do not.
+func pairable(log PlaybackLog) bool {
+	return log.VoidedAt == nil && !log.Quarantined
+}
@@ -1,21 +1,130 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
 )
 
 type IRepository interface {
 	TrackRepository
 	PlaybackLogRepository
+	RetentionRepository
+	WebhookRepository
+	RecommendationRepository
+	PricingRepository
+	ScheduledPriceRepository
+	TimePriceRuleRepository
+	SettingsRepository
+	ReconciliationRepository
+	QueueRepository
+	AnomalyRepository
+	AlertRepository
+	DeviceRepository
+	VenueRepository
+	ArtistRepository
+	HeatmapRepository
+	ChartRepository
+	CustomerRepository
+	RoyaltyRepository
+	CatalogRepository
+	TagRepository
+}
+
+type rollupKey struct {
+	trackID     int
+	periodStart time.Time
+}
+
+type rollup struct {
+	playCount int
+	revenue   float64
 }
 
 type inMemoryRepository struct {
-	tracks map[int]*Track
-	logs   []PlaybackLog
+	tracks    map[int]*Track
+	artists   map[int]Artist
+	logs      []PlaybackLog
+	nextLogID int
+	rollups   map[rollupKey]*rollup
+
+	externalIDs []ExternalID
+	redirects   map[int]int
+
+	tags      []Tag
+	nextTagID int
+
+	webhooks       []WebhookSubscription
+	outbox         []WebhookEvent
+	nextEventID    int
+	dispatched     int
+	deliveries     []WebhookDelivery
+	nextDeliveryID int
+	nextWebhookID  int
+
+	cooccurrences      map[TrackPair]int
+	cooccurrenceLastID int
+
+	pricingRules      []PricingRule
+	nextPricingRuleID int
+	priceBounds       map[int]PriceBounds
+	priceChanges      []PriceChange
+
+	scheduledPrices []ScheduledPrice
+
+	timePriceRules      []TimePriceRule
+	nextTimePriceRuleID int
+
+	settings map[string]string
+
+	queue []QueueItem
+
+	anomalyLastID int
+	baselines     map[string]DeviceBaseline
+	alerts        []Alert
+
+	devices map[string]Device
+	venues  map[string]Venue
+
+	charts []ChartSnapshot
+
+	customers map[string]*Customer
+	wallet    []WalletTransaction
+
+	royaltyRates      []RoyaltyRate
+	nextRoyaltyRateID int
+	statements        []RoyaltyStatement
+}
+
+func (r *inMemoryRepository) GetTracks() ([]Track, error) {
+	var tracks []Track
+	for _, track := range r.tracks {
+		tracks = append(tracks, *track)
+	}
+	sort.Slice(tracks, func(i, j int) bool {
+		return tracks[i].ID < tracks[j].ID
+	})
+	return tracks, nil
+}
+
+// canonicalTrackID follows the redirect of a merged track.
+func (r *inMemoryRepository) canonicalTrackID(id int) int {
+	if canonicalID, ok := r.redirects[id]; ok {
+		return canonicalID
+	}
+	return id
 }
 
 func (r *inMemoryRepository) GetTrackByID(id int) (*Track, error) {
+	id = r.canonicalTrackID(id)
 	track, ok := r.tracks[id]
 	if !ok {
 		return nil, fmt.Errorf("track with id %d not found", id)
@@ -28,29 +137,329 @@ func (r *inMemoryRepository) UpdateTrackPrice(id int, newPrice float64) error {
 	if err != nil {
 		return err
 	}
+	r.addOutboxEvent(EventPriceUpdated, PriceUpdatedEvent{TrackID: track.ID, OldPrice: track.Price, NewPrice: newPrice})
 	track.Price = newPrice
 	return nil
 }
 
-func (r *inMemoryRepository) CreateLog(log PlaybackLog) error {
-	r.logs = append(r.logs, log)
+// SearchTracks matches tracks whose title or artist contains every word of
+// the query, ignoring case.
+func (r *inMemoryRepository) SearchTracks(query string, limit, offset int) ([]Track, int, error) {
+	tracks, _ := r.GetTracks()
+	words := strings.Fields(foldText(query))
+
+	var matches []Track
+	for _, track := range tracks {
+		text := foldText(track.Title + " " + track.Artist)
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, track)
+		}
+	}
+
+	return page(matches, limit, offset), len(matches), nil
+}
+
+// foldText lowercases s and strips its diacritics, so "Beyoncé" matches
+// "beyonce" as it does in the FTS5 index.
+func foldText(s string) string {
+	folded, _, err := transform.String(transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC), s)
+	if err != nil {
+		folded = s
+	}
+	return strings.ToLower(folded)
+}
+
+// page returns the limit items starting at offset.
+func page[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return nil
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+func (r *inMemoryRepository) SetTrackDuration(id int, seconds int) error {
+	track, err := r.GetTrackByID(id)
+	if err != nil {
+		return err
+	}
+	track.DurationSeconds = seconds
+	return nil
+}
+
+func (r *inMemoryRepository) UpdateTrackMetadata(track Track) error {
+	existing, err := r.GetTrackByID(track.ID)
+	if err != nil {
+		return err
+	}
+	existing.ISRC = track.ISRC
+	existing.Album = track.Album
+	existing.ReleaseYear = track.ReleaseYear
+	existing.DurationSeconds = track.DurationSeconds
+	existing.Tags = slices.Clone(track.Tags)
+	r.ensureTags(track.Tags)
+	return nil
+}
+
+func (r *inMemoryRepository) GetTrackByISRC(isrc string) (*Track, error) {
+	tracks, _ := r.GetTracks()
+	for _, track := range tracks {
+		if track.ISRC == isrc {
+			return r.tracks[track.ID], nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *inMemoryRepository) GetTrackByExternalID(source, externalID string) (*Track, error) {
+	for _, id := range r.externalIDs {
+		if id.Source == source && id.ExternalID == externalID {
+			return r.GetTrackByID(id.TrackID)
+		}
+	}
+	return nil, nil
+}
+
+func (r *inMemoryRepository) SaveExternalID(id ExternalID) error {
+	for i := range r.externalIDs {
+		if r.externalIDs[i].TrackID == id.TrackID && r.externalIDs[i].Source == id.Source {
+			r.externalIDs[i] = id
+			return nil
+		}
+	}
+	r.externalIDs = append(r.externalIDs, id)
 	return nil
 }
 
+func (r *inMemoryRepository) DeleteExternalID(trackID int, source string) error {
+	for i, id := range r.externalIDs {
+		if id.TrackID == trackID && id.Source == source {
+			r.externalIDs = slices.Delete(r.externalIDs, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("external id %q for track %d not found", source, trackID)
+}
+
+func (r *inMemoryRepository) GetExternalIDs(trackID int) ([]ExternalID, error) {
+	var ids []ExternalID
+	for _, id := range r.externalIDs {
+		if id.TrackID == trackID {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool {
+		return ids[i].Source < ids[j].Source
+	})
+	return ids, nil
+}
+
+func (r *inMemoryRepository) MergeTracks(canonicalID, duplicateID int, at time.Time) error {
+	canonical, ok := r.tracks[canonicalID]
+	if !ok {
+		return fmt.Errorf("track with id %d not found", canonicalID)
+	}
+	duplicate, ok := r.tracks[duplicateID]
+	if !ok {
+		return fmt.Errorf("track with id %d not found", duplicateID)
+	}
+
+	for i := range r.logs {
+		if r.logs[i].TrackID == duplicateID {
+			r.logs[i].TrackID = canonicalID
+		}
+	}
+	for i := range r.priceChanges {
+		if r.priceChanges[i].TrackID == duplicateID {
+			r.priceChanges[i].TrackID = canonicalID
+		}
+	}
+	for i := range r.queue {
+		if r.queue[i].TrackID == duplicateID {
+			r.queue[i].TrackID = canonicalID
+		}
+	}
+	for i := range r.scheduledPrices {
+		if r.scheduledPrices[i].TrackID == duplicateID {
+			r.scheduledPrices[i].TrackID = canonicalID
+		}
+	}
+	for i := range r.timePriceRules {
+		if r.timePriceRules[i].TrackID == duplicateID {
+			r.timePriceRules[i].TrackID = canonicalID
+		}
+	}
+
+	for key, rollup := range r.rollups {
+		if key.trackID != duplicateID {
+			continue
+		}
+		delete(r.rollups, key)
+		key.trackID = canonicalID
+		if existing, ok := r.rollups[key]; ok {
+			existing.playCount += rollup.playCount
+			existing.revenue += rollup.revenue
+		} else {
+			r.rollups[key] = rollup
+		}
+	}
+	for pair, count := range r.cooccurrences {
+		if pair.TrackID != duplicateID && pair.RelatedTrackID != duplicateID {
+			continue
+		}
+		delete(r.cooccurrences, pair)
+		if pair.TrackID == duplicateID {
+			pair.TrackID = canonicalID
+		}
+		if pair.RelatedTrackID == duplicateID {
+			pair.RelatedTrackID = canonicalID
+		}
+		if pair.TrackID != pair.RelatedTrackID {
+			r.cooccurrences[pair] += count
+		}
+	}
+
+	// Where both tracks have a setting, the canonical track's wins.
+	if bounds, ok := r.priceBounds[duplicateID]; ok {
+		if _, exists := r.priceBounds[canonicalID]; !exists {
+			bounds.TrackID = canonicalID
+			r.priceBounds[canonicalID] = bounds
+		}
+		delete(r.priceBounds, duplicateID)
+	}
+	r.royaltyRates = slices.DeleteFunc(r.royaltyRates, func(rate RoyaltyRate) bool {
+		return rate.TrackID == duplicateID && slices.ContainsFunc(r.royaltyRates, func(other RoyaltyRate) bool {
+			return other.TrackID == canonicalID && other.ArtistID == rate.ArtistID
+		})
+	})
+	for i := range r.royaltyRates {
+		if r.royaltyRates[i].TrackID == duplicateID {
+			r.royaltyRates[i].TrackID = canonicalID
+		}
+	}
+	r.externalIDs = slices.DeleteFunc(r.externalIDs, func(id ExternalID) bool {
+		return id.TrackID == duplicateID && slices.ContainsFunc(r.externalIDs, func(other ExternalID) bool {
+			return other.TrackID == canonicalID && other.Source == id.Source
+		})
+	})
+	for i := range r.externalIDs {
+		if r.externalIDs[i].TrackID == duplicateID {
+			r.externalIDs[i].TrackID = canonicalID
+		}
+	}
+	canonical.Tags = slices.Compact(slices.Sorted(slices.Values(append(slices.Clone(canonical.Tags), duplicate.Tags...))))
+	if canonical.ISRC == "" {
+		canonical.ISRC = duplicate.ISRC
+	}
+
+	for id, target := range r.redirects {
+		if target == duplicateID {
+			r.redirects[id] = canonicalID
+		}
+	}
+	r.redirects[duplicateID] = canonicalID
+	delete(r.tracks, duplicateID)
+	return nil
+}
+
+func (r *inMemoryRepository) CreateLog(log PlaybackLog) (*PlaybackLog, error) {
+	r.nextLogID++
+	log.ID = r.nextLogID
+	r.logs = append(r.logs, log)
+	r.addOutboxEvent(EventPlaybackLogged, PlaybackLoggedEvent{
+		LogID:      log.ID,
+		TrackID:    log.TrackID,
+		PlayedAt:   log.PlayedAt,
+		AmountPaid: log.AmountPaid,
+		DeviceID:   log.DeviceID,
+		SessionID:  log.SessionID,
+	})
+	return &log, nil
+}
+
+func (r *inMemoryRepository) GetLogByID(id int) (*PlaybackLog, error) {
+	for i := range r.logs {
+		if r.logs[i].ID == id {
+			log := r.logs[i]
+			return &log, nil
+		}
+	}
+	return nil, fmt.Errorf("playback log with id %d not found", id)
+}
+
+func (r *inMemoryRepository) VoidLog(id int, reason string, refundedAmount float64, voidedAt time.Time) error {
+	for i := range r.logs {
+		log := &r.logs[i]
+		if log.ID != id || log.VoidedAt != nil {
+			continue
+		}
+		log.VoidedAt = &voidedAt
+		log.VoidReason = reason
+		log.RefundedAmount = refundedAmount
+		if customer, ok := r.customers[log.CustomerID]; ok {
+			r.voidCustomerPlay(customer, *log, voidedAt)
+		}
+		r.addOutboxEvent(EventPlaybackVoided, PlaybackVoidedEvent{
+			LogID:          id,
+			TrackID:        log.TrackID,
+			Reason:         reason,
+			RefundedAmount: refundedAmount,
+			VoidedAt:       voidedAt,
+		})
+		return nil
+	}
+	return fmt.Errorf("playback log with id %d not found or already voided", id)
+}
+
+func (r *inMemoryRepository) CompleteLog(id int, listenedSeconds int, skipped bool, completedAt time.Time) error {
+	for i := range r.logs {
+		log := &r.logs[i]
+		if log.ID != id || log.CompletedAt != nil {
+			continue
+		}
+		log.CompletedAt = &completedAt
+		log.ListenedSeconds = listenedSeconds
+		log.Skipped = skipped
+		return nil
+	}
+	return fmt.Errorf("playback log with id %d not found or already completed", id)
+}
+
 func (r *inMemoryRepository) GetAllLogs() []PlaybackLog {
 	return r.logs
 }
 
 func (r *inMemoryRepository) GetTopTracks(limit int) ([]TopTrackStat, error) {
+	return r.topTracks(limit, "")
+}
+
+func (r *inMemoryRepository) GetTagTopTracks(limit int, tag string) ([]TopTrackStat, error) {
+	return r.topTracks(limit, tag)
+}
+
+// topTracks ranks tracks by plays, only counting tracks with the tag unless
+// it is empty.
+func (r *inMemoryRepository) topTracks(limit int, tag string) ([]TopTrackStat, error) {
 	counts := make(map[int]int)
 	for _, log := range r.logs {
-		counts[log.TrackID]++
+		if log.VoidedAt == nil && !log.Quarantined {
+			counts[log.TrackID]++
+		}
+	}
+	for key, agg := range r.rollups {
+		counts[key.trackID] += agg.playCount
 	}
 
 	var stats []TopTrackStat
 	for trackID, count := range counts {
 		track, err := r.GetTrackByID(trackID)
-		if err == nil {
+		if err == nil && (tag == "" || slices.Contains(track.Tags, tag)) {
 			stats = append(stats, TopTrackStat{Title: track.Title, Count: count})
 		}
 	}
@@ -65,14 +474,1449 @@ func (r *inMemoryRepository) GetTopTracks(limit int) ([]TopTrackStat, error) {
 	return stats, nil
 }
 
-func NewInMemoryRepository() IRepository {
-	tracks := map[int]*Track{
-		1: {ID: 1, Title: "Dirty Diana", Artist: "Michael Jackson", Price: 1.25},
-		2: {ID: 2, Title: "Comfortably Numb", Artist: "Pink Floyd", Price: 1.50},
-		3: {ID: 3, Title: "Space Oddity", Artist: "David Bowie", Price: 1.00},
+func (r *inMemoryRepository) GetTrackPlayCounts(from, to time.Time) ([]TrackPlayCount, error) {
+	counts := make(map[int]int)
+	for _, log := range r.logs {
+		if !log.PlayedAt.Before(from) && log.PlayedAt.Before(to) && log.VoidedAt == nil && !log.Quarantined {
+			counts[log.TrackID]++
+		}
 	}
-	return &inMemoryRepository{
-		tracks: tracks,
-		logs:   []PlaybackLog{},
+
+	var stats []TrackPlayCount
+	for trackID, count := range counts {
+		track, err := r.GetTrackByID(trackID)
+		if err == nil {
+			stats = append(stats, TrackPlayCount{TrackID: trackID, Title: track.Title, Count: count})
+		}
+	}
+	return stats, nil
+}
+
+func (r *inMemoryRepository) GetTrackPlayRange(trackID int) (*time.Time, *time.Time, error) {
+	var first, last *time.Time
+	add := func(t time.Time) {
+		if first == nil || t.Before(*first) {
+			first = &t
+		}
+		if last == nil || t.After(*last) {
+			last = &t
+		}
+	}
+	for _, log := range r.logs {
+		if log.TrackID == trackID && log.VoidedAt == nil && !log.Quarantined {
+			add(log.PlayedAt.UTC())
+		}
+	}
+	for key, agg := range r.rollups {
+		if key.trackID == trackID && agg.playCount > 0 {
+			add(key.periodStart.UTC())
+		}
+	}
+	return first, last, nil
+}
+
+func (r *inMemoryRepository) GetSkipStats(from, to time.Time) ([]SkipStat, error) {
+	byTrack := make(map[int]*SkipStat)
+	completion := make(map[int]float64)
+	for _, log := range r.logs {
+		if log.PlayedAt.Before(from) || !log.PlayedAt.Before(to) || log.VoidedAt != nil || log.Quarantined || log.CompletedAt == nil {
+			continue
+		}
+		track, err := r.GetTrackByID(log.TrackID)
+		if err != nil {
+			continue
+		}
+		s, ok := byTrack[track.ID]
+		if !ok {
+			s = &SkipStat{TrackID: track.ID, Title: track.Title, DurationSeconds: track.DurationSeconds}
+			byTrack[track.ID] = s
+		}
+		s.Plays++
+		if log.Skipped {
+			s.Skips++
+		}
+		if track.DurationSeconds > 0 {
+			completion[track.ID] += min(float64(log.ListenedSeconds)/float64(track.DurationSeconds), 1)
+		}
+	}
+
+	var stats []SkipStat
+	for trackID, s := range byTrack {
+		s.SkipRate = float64(s.Skips) / float64(s.Plays)
+		if s.DurationSeconds > 0 {
+			s.AvgCompletion = completion[trackID] / float64(s.Plays)
+		}
+		stats = append(stats, *s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		return stats[i].TrackID < stats[j].TrackID
+	})
+	return stats, nil
+}
+
+func (r *inMemoryRepository) GetExpiredLogs(cutoff time.Time, limit int) ([]PlaybackLog, error) {
+	var expired []PlaybackLog
+	for _, log := range r.logs {
+		if len(expired) == limit {
+			break
+		}
+		if log.PlayedAt.Before(cutoff) {
+			expired = append(expired, log)
+		}
+	}
+	return expired, nil
+}
+
+func (r *inMemoryRepository) PurgeExpiredLogs(cutoff time.Time, maxID int) (int, error) {
+	kept := r.logs[:0]
+	deleted := 0
+	for _, log := range r.logs {
+		if !log.PlayedAt.Before(cutoff) || log.ID > maxID {
+			kept = append(kept, log)
+			continue
+		}
+
+		key := rollupKey{trackID: log.TrackID, periodStart: log.PlayedAt.UTC().Truncate(time.Hour)}
+		agg, ok := r.rollups[key]
+		if !ok {
+			agg = &rollup{}
+			r.rollups[key] = agg
+		}
+		if log.VoidedAt == nil && !log.Quarantined {
+			agg.playCount++
+		}
+		if !log.Quarantined {
+			agg.revenue += log.AmountPaid - log.RefundedAmount
+		}
+		deleted++
+	}
+	r.logs = kept
+	return deleted, nil
+}
+
+func (r *inMemoryRepository) HasRollups(from, to time.Time) (bool, error) {
+	for key := range r.rollups {
+		if !key.periodStart.Before(from) && key.periodStart.Before(to) {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *inMemoryRepository) GetExpiredLogsSummary(cutoff time.Time) ([]RetentionMonth, error) {
+	byMonth := make(map[string]*RetentionMonth)
+	for _, log := range r.logs {
+		if !log.PlayedAt.Before(cutoff) {
+			continue
+		}
+		month := log.PlayedAt.UTC().Format("2006-01")
+		m, ok := byMonth[month]
+		if !ok {
+			m = &RetentionMonth{Month: month}
+			byMonth[month] = m
+		}
+		m.Plays++
+		m.Revenue += log.AmountPaid - log.RefundedAmount
+	}
+
+	var months []RetentionMonth
+	for _, m := range byMonth {
+		months = append(months, *m)
+	}
+	sort.Slice(months, func(i, j int) bool {
+		return months[i].Month < months[j].Month
+	})
+	return months, nil
+}
+
+func (r *inMemoryRepository) addOutboxEvent(eventType string, data any) {
+	if !slices.ContainsFunc(r.webhooks, func(sub WebhookSubscription) bool { return sub.Subscribes(eventType) }) {
+		return
+	}
+	payload, _ := json.Marshal(data)
+	r.nextEventID++
+	r.outbox = append(r.outbox, WebhookEvent{
+		ID:        r.nextEventID,
+		Type:      eventType,
+		Data:      payload,
+		CreatedAt: time.Now().UTC(),
+	})
+}
+
+func (r *inMemoryRepository) CreateWebhook(sub WebhookSubscription) (*WebhookSubscription, error) {
+	r.nextWebhookID++
+	sub.ID = r.nextWebhookID
+	r.webhooks = append(r.webhooks, sub)
+	return &sub, nil
+}
+
+func (r *inMemoryRepository) GetWebhooks() ([]WebhookSubscription, error) {
+	return append([]WebhookSubscription(nil), r.webhooks...), nil
+}
+
+func (r *inMemoryRepository) DeleteWebhook(id int) error {
+	for i, sub := range r.webhooks {
+		if sub.ID == id {
+			r.webhooks = append(r.webhooks[:i], r.webhooks[i+1:]...)
+			for j := range r.deliveries {
+				if r.deliveries[j].SubscriptionID == id && r.deliveries[j].Status == DeliveryPending {
+					r.deliveries[j].Status = DeliveryFailed
+					r.deliveries[j].LastError = "subscription deleted"
+				}
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("webhook with id %d not found", id)
+}
+
+func (r *inMemoryRepository) GetWebhookDeliveries(subscriptionID int, limit int) ([]WebhookDelivery, error) {
+	var deliveries []WebhookDelivery
+	for i := len(r.deliveries) - 1; i >= 0 && len(deliveries) < limit; i-- {
+		if r.deliveries[i].SubscriptionID == subscriptionID {
+			deliveries = append(deliveries, r.deliveries[i])
+		}
+	}
+	return deliveries, nil
+}
+
+func (r *inMemoryRepository) DispatchOutbox(limit int) (int, error) {
+	n := 0
+	for ; r.dispatched < len(r.outbox) && n < limit; r.dispatched++ {
+		event := r.outbox[r.dispatched]
+		for _, sub := range r.webhooks {
+			if !sub.Subscribes(event.Type) {
+				continue
+			}
+			r.nextDeliveryID++
+			r.deliveries = append(r.deliveries, WebhookDelivery{
+				ID:             r.nextDeliveryID,
+				SubscriptionID: sub.ID,
+				EventID:        event.ID,
+				EventType:      event.Type,
+				Status:         DeliveryPending,
+				NextAttemptAt:  event.CreatedAt,
+				URL:            sub.URL,
+				Secret:         sub.Secret,
+				Event:          event,
+			})
+		}
+		n++
+	}
+	return n, nil
+}
+
+func (r *inMemoryRepository) PurgeWebhookHistory(cutoff time.Time) (int, error) {
+	expired := func(e WebhookEvent) bool { return e.CreatedAt.Before(cutoff) }
+	dispatched := r.outbox[:r.dispatched]
+
+	r.deliveries = slices.DeleteFunc(r.deliveries, func(d WebhookDelivery) bool {
+		return d.Status != DeliveryPending && slices.ContainsFunc(dispatched, func(e WebhookEvent) bool {
+			return e.ID == d.EventID && expired(e)
+		})
+	})
+	kept := dispatched[:0]
+	for _, e := range dispatched {
+		pending := slices.ContainsFunc(r.deliveries, func(d WebhookDelivery) bool { return d.EventID == e.ID })
+		if pending || !expired(e) {
+			kept = append(kept, e)
+		}
+	}
+	purged := r.dispatched - len(kept)
+	r.outbox = append(kept, r.outbox[r.dispatched:]...)
+	r.dispatched = len(kept)
+	return purged, nil
+}
+
+func (r *inMemoryRepository) GetDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	var due []WebhookDelivery
+	for _, d := range r.deliveries {
+		if len(due) == limit {
+			break
+		}
+		if d.Status == DeliveryPending && !d.NextAttemptAt.After(now) {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (r *inMemoryRepository) UpdateDelivery(d WebhookDelivery) error {
+	for i := range r.deliveries {
+		if r.deliveries[i].ID == d.ID {
+			r.deliveries[i] = d
+			return nil
+		}
+	}
+	return fmt.Errorf("delivery with id %d not found", d.ID)
+}
+
+func (r *inMemoryRepository) GetLogsAfter(id int, limit int) ([]PlaybackLog, error) {
+	var logs []PlaybackLog
+	for _, log := range r.logs {
+		if len(logs) == limit {
+			break
+		}
+		if log.ID > id {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (r *inMemoryRepository) GetSessionLogsBefore(log PlaybackLog, since time.Time, limit int) ([]PlaybackLog, error) {
+	var logs []PlaybackLog
+	for i := len(r.logs) - 1; i >= 0 && len(logs) < limit; i-- {
+		prev := r.logs[i]
+		if prev.ID >= log.ID || prev.PlayedAt.Before(since) {
+			continue
+		}
+		if log.SessionID != "" && prev.SessionID == log.SessionID ||
+			log.SessionID == "" && prev.DeviceID == log.DeviceID {
+			logs = append(logs, prev)
+		}
+	}
+	return logs, nil
+}
+
+func (r *inMemoryRepository) GetCooccurrenceCursor() (int, error) {
+	return r.cooccurrenceLastID, nil
+}
+
+func (r *inMemoryRepository) AddCooccurrences(pairs []TrackPair, lastLogID int) error {
+	for _, p := range pairs {
+		r.cooccurrences[p]++
+	}
+	r.cooccurrenceLastID = lastLogID
+	return nil
+}
+
+func (r *inMemoryRepository) GetRelatedTracks(trackID int, limit int) ([]RelatedTrack, error) {
+	var related []RelatedTrack
+	for pair, count := range r.cooccurrences {
+		if pair.TrackID != trackID {
+			continue
+		}
+		track, err := r.GetTrackByID(pair.RelatedTrackID)
+		if err == nil {
+			related = append(related, RelatedTrack{TrackID: track.ID, Title: track.Title, Artist: track.Artist, Count: count})
+		}
+	}
+
+	sort.Slice(related, func(i, j int) bool {
+		if related[i].Count != related[j].Count {
+			return related[i].Count > related[j].Count
+		}
+		return related[i].TrackID < related[j].TrackID
+	})
+
+	if len(related) > limit {
+		related = related[:limit]
+	}
+	return related, nil
+}
+
+func (r *inMemoryRepository) CreatePricingRule(rule PricingRule) (*PricingRule, error) {
+	r.nextPricingRuleID++
+	rule.ID = r.nextPricingRuleID
+	r.pricingRules = append(r.pricingRules, rule)
+	return &rule, nil
+}
+
+func (r *inMemoryRepository) GetPricingRules() ([]PricingRule, error) {
+	return append([]PricingRule(nil), r.pricingRules...), nil
+}
+
+func (r *inMemoryRepository) DeletePricingRule(id int) error {
+	for i, rule := range r.pricingRules {
+		if rule.ID == id {
+			r.pricingRules = append(r.pricingRules[:i], r.pricingRules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("pricing rule with id %d not found", id)
+}
+
+func (r *inMemoryRepository) SetPriceBounds(bounds PriceBounds) error {
+	r.priceBounds[bounds.TrackID] = bounds
+	return nil
+}
+
+func (r *inMemoryRepository) GetPriceBounds() ([]PriceBounds, error) {
+	var bounds []PriceBounds
+	for _, b := range r.priceBounds {
+		bounds = append(bounds, b)
+	}
+	return bounds, nil
+}
+
+func (r *inMemoryRepository) RecordPriceChange(change PriceChange) error {
+	change.ID = len(r.priceChanges) + 1
+	r.priceChanges = append(r.priceChanges, change)
+	return nil
+}
+
+func (r *inMemoryRepository) GetPriceChanges(limit int) ([]PriceChange, error) {
+	var changes []PriceChange
+	for i := len(r.priceChanges) - 1; i >= 0 && len(changes) < limit; i-- {
+		changes = append(changes, r.priceChanges[i])
+	}
+	return changes, nil
+}
+
+func (r *inMemoryRepository) GetLatestPriceChanges() ([]PriceChange, error) {
+	latest := make(map[int]PriceChange)
+	for _, change := range r.priceChanges {
+		latest[change.TrackID] = change
+	}
+
+	var changes []PriceChange
+	for _, change := range latest {
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+func (r *inMemoryRepository) CreateScheduledPrice(sp ScheduledPrice) (*ScheduledPrice, error) {
+	sp.ID = len(r.scheduledPrices) + 1
+	r.scheduledPrices = append(r.scheduledPrices, sp)
+	return &sp, nil
+}
+
+func (r *inMemoryRepository) GetScheduledPriceByID(id int) (*ScheduledPrice, error) {
+	for _, sp := range r.scheduledPrices {
+		if sp.ID == id {
+			return &sp, nil
+		}
+	}
+	return nil, fmt.Errorf("scheduled price with id %d not found", id)
+}
+
+func (r *inMemoryRepository) GetScheduledPrices(status string) ([]ScheduledPrice, error) {
+	var prices []ScheduledPrice
+	for _, sp := range r.scheduledPrices {
+		if status == "" || sp.Status == status {
+			prices = append(prices, sp)
+		}
+	}
+	sort.SliceStable(prices, func(i, j int) bool {
+		return prices[i].StartsAt.Before(prices[j].StartsAt)
+	})
+	return prices, nil
+}
+
+func (r *inMemoryRepository) UpdateScheduledPrice(sp ScheduledPrice) error {
+	for i := range r.scheduledPrices {
+		if r.scheduledPrices[i].ID == sp.ID {
+			r.scheduledPrices[i].Status = sp.Status
+			r.scheduledPrices[i].PreviousPrice = sp.PreviousPrice
+			return nil
+		}
+	}
+	return fmt.Errorf("scheduled price with id %d not found", sp.ID)
+}
+
+func (r *inMemoryRepository) CreateTimePriceRule(rule TimePriceRule) (*TimePriceRule, error) {
+	r.nextTimePriceRuleID++
+	rule.ID = r.nextTimePriceRuleID
+	r.timePriceRules = append(r.timePriceRules, rule)
+	return &rule, nil
+}
+
+func (r *inMemoryRepository) GetTimePriceRules() ([]TimePriceRule, error) {
+	return append([]TimePriceRule(nil), r.timePriceRules...), nil
+}
+
+func (r *inMemoryRepository) DeleteTimePriceRule(id int) error {
+	for i, rule := range r.timePriceRules {
+		if rule.ID == id {
+			r.timePriceRules = append(r.timePriceRules[:i], r.timePriceRules[i+1:]...)
+			return nil
+		}
+	}
+	return fmt.Errorf("time price rule with id %d not found", id)
+}
+
+func (r *inMemoryRepository) GetSetting(key string) (string, error) {
+	return r.settings[key], nil
+}
+
+func (r *inMemoryRepository) SetSetting(key, value string) error {
+	r.settings[key] = value
+	return nil
+}
+
+func (r *inMemoryRepository) GetReconciliationBuckets(from, to time.Time) ([]ReconciliationBucket, error) {
+	type bucketKey struct {
+		start    time.Time
+		deviceID string
+	}
+	byKey := make(map[bucketKey]*ReconciliationBucket)
+	var keys []bucketKey
+	for _, log := range r.logs {
+		if log.PlayedAt.Before(from) || !log.PlayedAt.Before(to) || log.PaymentStatus == "" {
+			continue
+		}
+		key := bucketKey{start: log.PlayedAt.UTC().Truncate(playBucket), deviceID: log.DeviceID}
+		bucket, ok := byKey[key]
+		if !ok {
+			bucket = &ReconciliationBucket{Start: key.start, DeviceID: key.deviceID}
+			byKey[key] = bucket
+			keys = append(keys, key)
+		}
+		bucket.Plays++
+		switch log.PaymentStatus {
+		case PaymentUnderpaid:
+			bucket.Underpaid++
+		case PaymentOverpaid:
+			bucket.Overpaid++
+		}
+		bucket.ExpectedTotal += log.ListPrice
+		bucket.PaidTotal += log.AmountPaid
+	}
+
+	var buckets []ReconciliationBucket
+	for _, key := range keys {
+		buckets = append(buckets, *byKey[key])
+	}
+	return buckets, nil
+}
+
+func (r *inMemoryRepository) GetPaymentDiscrepancies(from, to time.Time, limit int) ([]PaymentDiscrepancy, error) {
+	var discrepancies []PaymentDiscrepancy
+	for _, log := range r.logs {
+		if len(discrepancies) == limit {
+			break
+		}
+		if log.PlayedAt.Before(from) || !log.PlayedAt.Before(to) {
+			continue
+		}
+		if log.PaymentStatus != PaymentUnderpaid && log.PaymentStatus != PaymentOverpaid {
+			continue
+		}
+		discrepancies = append(discrepancies, PaymentDiscrepancy{
+			LogID:         log.ID,
+			TrackID:       log.TrackID,
+			DeviceID:      log.DeviceID,
+			PlayedAt:      log.PlayedAt,
+			ListPrice:     log.ListPrice,
+			AmountPaid:    log.AmountPaid,
+			PaymentStatus: log.PaymentStatus,
+		})
+	}
+	return discrepancies, nil
+}
+
+func (r *inMemoryRepository) EnqueueTrack(item QueueItem) (*QueueItem, error) {
+	item.ID = len(r.queue) + 1
+	r.queue = append(r.queue, item)
+	return &item, nil
+}
+
+func (r *inMemoryRepository) GetQueue(deviceID string) ([]QueueItem, error) {
+	var queue []QueueItem
+	for _, item := range r.queue {
+		if item.DeviceID == deviceID && (item.Status == QueueStatusQueued || item.Status == QueueStatusPlaying) {
+			queue = append(queue, item)
+		}
+	}
+	return queue, nil
+}
+
+func (r *inMemoryRepository) GetQueueItemByID(id int) (*QueueItem, error) {
+	if id < 1 || id > len(r.queue) {
+		return nil, fmt.Errorf("queue item with id %d not found", id)
+	}
+	item := r.queue[id-1]
+	return &item, nil
+}
+
+func (r *inMemoryRepository) StartQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error) {
+	if r.queue[item.ID-1].Status != QueueStatusQueued {
+		return nil, fmt.Errorf("queue item with id %d is no longer queued", item.ID)
+	}
+	created, err := r.CreateLog(log)
+	if err != nil {
+		return nil, err
+	}
+	item.LogID = created.ID
+	r.queue[item.ID-1] = item
+	return &item, nil
+}
+
+func (r *inMemoryRepository) CancelQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error) {
+	if r.queue[item.ID-1].Status != QueueStatusQueued {
+		return nil, fmt.Errorf("queue item with id %d is no longer queued", item.ID)
+	}
+	voidedAt := *log.VoidedAt
+	log.VoidedAt = nil
+	created, err := r.CreateLog(log)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.VoidLog(created.ID, log.VoidReason, log.RefundedAmount, voidedAt); err != nil {
+		return nil, err
+	}
+	item.LogID = created.ID
+	r.queue[item.ID-1] = item
+	return &item, nil
+}
+
+func (r *inMemoryRepository) UpdateQueueItem(item QueueItem) error {
+	if item.ID < 1 || item.ID > len(r.queue) {
+		return fmt.Errorf("queue item with id %d not found", item.ID)
+	}
+	r.queue[item.ID-1] = item
+	return nil
+}
+
+func NewInMemoryRepository() IRepository {
+	tracks := map[int]*Track{
+		1: {ID: 1, Title: "Dirty Diana", Artist: "Michael Jackson", ArtistID: 1, Price: 1.25, DurationSeconds: 281},
+		2: {ID: 2, Title: "Comfortably Numb", Artist: "Pink Floyd", ArtistID: 2, Price: 1.50, DurationSeconds: 383},
+		3: {ID: 3, Title: "Space Oddity", Artist: "David Bowie", ArtistID: 3, Price: 1.00, DurationSeconds: 315},
+	}
+	artists := map[int]Artist{
+		1: {ID: 1, Name: "Michael Jackson"},
+		2: {ID: 2, Name: "Pink Floyd"},
+		3: {ID: 3, Name: "David Bowie"},
+	}
+	return &inMemoryRepository{
+		tracks:        tracks,
+		artists:       artists,
+		logs:          []PlaybackLog{},
+		rollups:       make(map[rollupKey]*rollup),
+		cooccurrences: make(map[TrackPair]int),
+		priceBounds:   make(map[int]PriceBounds),
+		settings:      make(map[string]string),
+		baselines:     make(map[string]DeviceBaseline),
+		devices:       make(map[string]Device),
+		venues:        make(map[string]Venue),
+		customers:     make(map[string]*Customer),
+		redirects:     make(map[int]int),
+	}
+}
+
+func (r *inMemoryRepository) GetAnomalyCursor() (int, error) {
+	return r.anomalyLastID, nil
+}
+
+func (r *inMemoryRepository) GetDeviceBaseline(deviceID string) (*DeviceBaseline, error) {
+	baseline, ok := r.baselines[deviceID]
+	if !ok {
+		baseline = DeviceBaseline{DeviceID: deviceID}
+	}
+	return &baseline, nil
+}
+
+func (r *inMemoryRepository) GetDeviceActivity(deviceID string, since time.Time, maxLogID int) (int, int, error) {
+	plays, zeroPlays := 0, 0
+	for _, log := range r.logs {
+		if log.DeviceID != deviceID || log.ID > maxLogID || log.PlayedAt.Before(since) || log.VoidedAt != nil {
+			continue
+		}
+		plays++
+		if log.AmountPaid == 0 {
+			zeroPlays++
+		}
+	}
+	return plays, zeroPlays, nil
+}
+
+func (r *inMemoryRepository) SaveAnomalyBatch(baselines []DeviceBaseline, alerts []Alert, lastLogID int) error {
+	for _, baseline := range baselines {
+		r.baselines[baseline.DeviceID] = baseline
+	}
+	for _, alert := range alerts {
+		if alert.ID == 0 {
+			alert.ID = len(r.alerts) + 1
+			r.alerts = append(r.alerts, alert)
+		} else {
+			r.alerts[alert.ID-1] = alert
+		}
+		if alert.Quarantined {
+			r.setQuarantined(alert, true)
+		}
+	}
+	r.anomalyLastID = lastLogID
+	return nil
+}
+
+func (r *inMemoryRepository) setQuarantined(alert Alert, quarantined bool) {
+	for i := range r.logs {
+		log := &r.logs[i]
+		if log.DeviceID == alert.DeviceID && !log.PlayedAt.Before(alert.WindowStart) && !log.PlayedAt.After(alert.WindowEnd) {
+			log.Quarantined = quarantined
+		}
+	}
+}
+
+func (r *inMemoryRepository) GetOpenAlert(deviceID, kind string) (*Alert, error) {
+	for i := len(r.alerts) - 1; i >= 0; i-- {
+		alert := r.alerts[i]
+		if alert.DeviceID == deviceID && alert.Kind == kind && alert.ResolvedAt == nil {
+			return &alert, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *inMemoryRepository) GetAlerts(deviceID string, includeResolved bool) ([]Alert, error) {
+	var alerts []Alert
+	for i := len(r.alerts) - 1; i >= 0; i-- {
+		alert := r.alerts[i]
+		if deviceID != "" && alert.DeviceID != deviceID {
+			continue
+		}
+		if alert.ResolvedAt != nil && !includeResolved {
+			continue
+		}
+		alerts = append(alerts, alert)
+	}
+	return alerts, nil
+}
+
+func (r *inMemoryRepository) GetAlertByID(id int) (*Alert, error) {
+	if id < 1 || id > len(r.alerts) {
+		return nil, fmt.Errorf("alert with id %d not found", id)
+	}
+	alert := r.alerts[id-1]
+	return &alert, nil
+}
+
+func (r *inMemoryRepository) ResolveAlert(id int, resolvedAt time.Time, release bool) error {
+	if id < 1 || id > len(r.alerts) || r.alerts[id-1].ResolvedAt != nil {
+		return fmt.Errorf("alert with id %d not found or already resolved", id)
+	}
+	alert := &r.alerts[id-1]
+	alert.ResolvedAt = &resolvedAt
+	if release && alert.Quarantined {
+		r.setQuarantined(*alert, false)
+		alert.Quarantined = false
+		for _, other := range r.alerts {
+			if other.DeviceID == alert.DeviceID && other.Quarantined && other.ResolvedAt == nil {
+				r.setQuarantined(other, true)
+			}
+		}
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) RecordHeartbeat(device Device) (*Device, error) {
+	stored, ok := r.devices[device.DeviceID]
+	if !ok {
+		stored = Device{DeviceID: device.DeviceID, FirstSeenAt: device.LastSeenAt}
+	}
+	if device.VenueID != "" {
+		stored.VenueID = device.VenueID
+	}
+	if device.FirmwareVersion != "" {
+		stored.FirmwareVersion = device.FirmwareVersion
+	}
+	stored.LastSeenAt = device.LastSeenAt
+	r.devices[device.DeviceID] = stored
+	return &stored, nil
+}
+
+func (r *inMemoryRepository) GetDevices() ([]Device, error) {
+	var devices []Device
+	for _, device := range r.devices {
+		devices = append(devices, device)
+	}
+	sort.Slice(devices, func(i, j int) bool {
+		return devices[i].DeviceID < devices[j].DeviceID
+	})
+	return devices, nil
+}
+
+func (r *inMemoryRepository) GetDeviceByID(id string) (*Device, error) {
+	device, ok := r.devices[id]
+	if !ok {
+		return nil, fmt.Errorf("device %q not found", id)
+	}
+	return &device, nil
+}
+
+func (r *inMemoryRepository) CreateOfflineAlert(alert Alert, event DeviceOfflineEvent) (*Alert, error) {
+	alert.ID = len(r.alerts) + 1
+	r.alerts = append(r.alerts, alert)
+	event.AlertID = alert.ID
+	r.addOutboxEvent(EventDeviceOffline, event)
+	return &alert, nil
+}
+
+func (r *inMemoryRepository) SaveVenue(venue Venue) error {
+	r.venues[venue.ID] = venue
+	return nil
+}
+
+func (r *inMemoryRepository) GetVenues() ([]Venue, error) {
+	var venues []Venue
+	for _, venue := range r.venues {
+		venues = append(venues, venue)
+	}
+	sort.Slice(venues, func(i, j int) bool {
+		return venues[i].ID < venues[j].ID
+	})
+	return venues, nil
+}
+
+func (r *inMemoryRepository) GetVenueByID(id string) (*Venue, error) {
+	venue, ok := r.venues[id]
+	if !ok {
+		return nil, fmt.Errorf("venue %q not found", id)
+	}
+	return &venue, nil
+}
+
+type trackTotal struct {
+	plays   int
+	revenue float64
+}
+
+// trackTotals sums plays and net revenue per track from raw logs and
+// retention rollups in [from, to).
+func (r *inMemoryRepository) trackTotals(from, to time.Time) map[int]*trackTotal {
+	totals := make(map[int]*trackTotal)
+	get := func(trackID int) *trackTotal {
+		total, ok := totals[trackID]
+		if !ok {
+			total = &trackTotal{}
+			totals[trackID] = total
+		}
+		return total
+	}
+
+	for _, log := range r.logs {
+		if log.PlayedAt.Before(from) || !log.PlayedAt.Before(to) {
+			continue
+		}
+		total := get(log.TrackID)
+		if log.VoidedAt == nil && !log.Quarantined {
+			total.plays++
+		}
+		if !log.Quarantined {
+			total.revenue += log.AmountPaid - log.RefundedAmount
+		}
+	}
+	for key, agg := range r.rollups {
+		if key.periodStart.Before(from) || !key.periodStart.Before(to) {
+			continue
+		}
+		total := get(key.trackID)
+		total.plays += agg.playCount
+		total.revenue += agg.revenue
+	}
+	return totals
+}
+
+func (r *inMemoryRepository) GetArtists() ([]Artist, error) {
+	var artists []Artist
+	for _, artist := range r.artists {
+		artists = append(artists, artist)
+	}
+	sort.Slice(artists, func(i, j int) bool {
+		return artists[i].Name < artists[j].Name
+	})
+	return artists, nil
+}
+
+func (r *inMemoryRepository) GetArtistByID(id int) (*Artist, error) {
+	artist, ok := r.artists[id]
+	if !ok {
+		return nil, fmt.Errorf("artist with id %d not found", id)
+	}
+	return &artist, nil
+}
+
+func (r *inMemoryRepository) GetArtistStats(from, to time.Time) ([]ArtistStat, error) {
+	byArtist := make(map[int]*ArtistStat)
+	for trackID, total := range r.trackTotals(from, to) {
+		track, ok := r.tracks[trackID]
+		if !ok {
+			continue
+		}
+		s, ok := byArtist[track.ArtistID]
+		if !ok {
+			s = &ArtistStat{ArtistID: track.ArtistID, Name: r.artists[track.ArtistID].Name}
+			byArtist[track.ArtistID] = s
+		}
+		s.Plays += total.plays
+		s.Revenue += total.revenue
+	}
+
+	var stats []ArtistStat
+	for _, s := range byArtist {
+		stats = append(stats, *s)
+	}
+	return stats, nil
+}
+
+func (r *inMemoryRepository) GetArtistTrackStats(artistID int, from, to time.Time) ([]TrackStat, error) {
+	totals := r.trackTotals(from, to)
+	tracks, _ := r.GetTracks()
+
+	var stats []TrackStat
+	for _, track := range tracks {
+		if track.ArtistID != artistID {
+			continue
+		}
+		s := TrackStat{TrackID: track.ID, Title: track.Title}
+		if total, ok := totals[track.ID]; ok {
+			s.Plays = total.plays
+			s.Revenue = total.revenue
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func (r *inMemoryRepository) GetPlayBuckets(filter HeatmapFilter) ([]PlayBucket, error) {
+	byStart := make(map[time.Time]*PlayBucket)
+	add := func(start time.Time, trackID, plays int, revenue float64) {
+		if filter.TrackID != 0 && trackID != filter.TrackID {
+			return
+		}
+		if filter.ArtistID != 0 && r.tracks[trackID].ArtistID != filter.ArtistID {
+			return
+		}
+		if filter.Tag != "" && !slices.Contains(r.tracks[trackID].Tags, filter.Tag) {
+			return
+		}
+		b, ok := byStart[start]
+		if !ok {
+			b = &PlayBucket{Start: start}
+			byStart[start] = b
+		}
+		b.Plays += plays
+		b.Revenue += revenue
+	}
+
+	for _, log := range r.logs {
+		if log.PlayedAt.Before(filter.From) || !log.PlayedAt.Before(filter.To) {
+			continue
+		}
+		if filter.VenueID != "" && r.devices[log.DeviceID].VenueID != filter.VenueID {
+			continue
+		}
+		if log.Quarantined {
+			continue
+		}
+		plays := 0
+		if log.VoidedAt == nil {
+			plays = 1
+		}
+		add(log.PlayedAt.UTC().Truncate(playBucket), log.TrackID, plays, log.AmountPaid-log.RefundedAmount)
+	}
+	// Rollups carry no device, so they are left out when filtering by venue.
+	if filter.VenueID == "" {
+		for key, agg := range r.rollups {
+			if key.periodStart.Before(filter.From) || !key.periodStart.Before(filter.To) {
+				continue
+			}
+			add(key.periodStart.UTC(), key.trackID, agg.playCount, agg.revenue)
+		}
+	}
+
+	var buckets []PlayBucket
+	for _, b := range byStart {
+		buckets = append(buckets, *b)
+	}
+	return buckets, nil
+}
+
+func (r *inMemoryRepository) GetTrackTotals(from, to time.Time) ([]TrackStat, error) {
+	var stats []TrackStat
+	for trackID, total := range r.trackTotals(from, to) {
+		s := TrackStat{TrackID: trackID, Plays: total.plays, Revenue: total.revenue}
+		if track, ok := r.tracks[trackID]; ok {
+			s.Title = track.Title
+		}
+		stats = append(stats, s)
+	}
+	return stats, nil
+}
+
+func (r *inMemoryRepository) GetChartHistory() ([]ChartEntry, error) {
+	last := make(map[int]ChartEntry)
+	for _, snapshot := range r.charts {
+		for _, entry := range snapshot.Entries {
+			entry.TrackID = r.canonicalTrackID(entry.TrackID)
+			// Entries are in position order, so a merged duplicate charted
+			// lower in the same snapshot does not replace the track's entry.
+			if prev, ok := last[entry.TrackID]; ok && prev.SnapshotID == entry.SnapshotID {
+				continue
+			}
+			last[entry.TrackID] = entry
+		}
+	}
+
+	var history []ChartEntry
+	for _, entry := range last {
+		history = append(history, entry)
+	}
+	return history, nil
+}
+
+func (r *inMemoryRepository) SaveChartSnapshot(snapshot ChartSnapshot) (*ChartSnapshot, error) {
+	for _, existing := range r.charts {
+		if existing.PeriodStart.Equal(snapshot.PeriodStart) {
+			return nil, fmt.Errorf("chart snapshot for %s already exists", snapshot.PeriodStart)
+		}
+	}
+	snapshot.ID = len(r.charts) + 1
+	entries := make([]ChartEntry, len(snapshot.Entries))
+	for i, entry := range snapshot.Entries {
+		entry.SnapshotID = snapshot.ID
+		entries[i] = entry
+	}
+	snapshot.Entries = entries
+	r.charts = append(r.charts, snapshot)
+	return &snapshot, nil
+}
+
+func (r *inMemoryRepository) GetChartSnapshots() ([]ChartSnapshot, error) {
+	var snapshots []ChartSnapshot
+	for i := len(r.charts) - 1; i >= 0; i-- {
+		snapshot := r.charts[i]
+		snapshot.Entries = nil
+		snapshots = append(snapshots, snapshot)
+	}
+	return snapshots, nil
+}
+
+func (r *inMemoryRepository) GetChartSnapshot(id int) (*ChartSnapshot, error) {
+	if id < 1 || id > len(r.charts) {
+		return nil, fmt.Errorf("chart snapshot with id %d not found", id)
+	}
+	snapshot := r.charts[id-1]
+	entries := make([]ChartEntry, len(snapshot.Entries))
+	for i, entry := range snapshot.Entries {
+		entry.TrackID = r.canonicalTrackID(entry.TrackID)
+		if track, ok := r.tracks[entry.TrackID]; ok {
+			entry.Title, entry.Artist = track.Title, track.Artist
+		}
+		entries[i] = entry
+	}
+	snapshot.Entries = entries
+	return &snapshot, nil
+}
+
+func (r *inMemoryRepository) GetCustomer(id string) (*Customer, error) {
+	stored, ok := r.customers[id]
+	if !ok {
+		return nil, nil
+	}
+	customer := *stored
+	return &customer, nil
+}
+
+func (r *inMemoryRepository) ensureCustomer(customerID string, at time.Time) *Customer {
+	customer, ok := r.customers[customerID]
+	if !ok {
+		customer = &Customer{ID: customerID, CreatedAt: at}
+		r.customers[customerID] = customer
+	}
+	return customer
+}
+
+func (r *inMemoryRepository) TopUpCredit(customerID string, amount float64, at time.Time) (*Customer, error) {
+	customer := r.ensureCustomer(customerID, at)
+	customer.Balance += amount
+	r.wallet = append(r.wallet, WalletTransaction{
+		ID:         len(r.wallet) + 1,
+		CustomerID: customerID,
+		Kind:       WalletTopUp,
+		Amount:     amount,
+		CreatedAt:  at,
+	})
+	return r.GetCustomer(customerID)
+}
+
+func (r *inMemoryRepository) CreateCustomerLog(log PlaybackLog, wallet []WalletTransaction) (*PlaybackLog, error) {
+	customer := r.ensureCustomer(log.CustomerID, log.PlayedAt)
+	balance := customer.Balance
+	for _, t := range wallet {
+		balance += t.Amount
+		if t.Kind == WalletDebit && balance < -paymentTolerance {
+			return nil, fmt.Errorf("insufficient credit for customer %s", log.CustomerID)
+		}
+	}
+
+	created, err := r.CreateLog(log)
+	if err != nil {
+		return nil, err
+	}
+	customer.Balance = balance
+	customer.Plays++
+	for _, t := range wallet {
+		t.ID = len(r.wallet) + 1
+		t.LogID = created.ID
+		r.wallet = append(r.wallet, t)
+	}
+	return created, nil
+}
+
+// voidCustomerPlay takes a voided play off the customer's count, refunds
+// what was paid with credit and reverses the loyalty reward it earned.
+func (r *inMemoryRepository) voidCustomerPlay(customer *Customer, log PlaybackLog, at time.Time) {
+	customer.Plays--
+
+	var transactions []WalletTransaction
+	if log.PaidWithCredit && log.RefundedAmount > 0 {
+		transactions = append(transactions, WalletTransaction{Kind: WalletRefund, Amount: log.RefundedAmount})
+	}
+	reward := 0.0
+	for _, t := range r.wallet {
+		if t.LogID == log.ID && t.Kind == WalletLoyalty {
+			reward += t.Amount
+		}
+	}
+	if reward > 0 {
+		transactions = append(transactions, WalletTransaction{Kind: WalletLoyaltyReversal, Amount: -reward})
+	}
+
+	for _, t := range transactions {
+		t.ID = len(r.wallet) + 1
+		t.CustomerID = customer.ID
+		t.LogID = log.ID
+		t.CreatedAt = at
+		customer.Balance += t.Amount
+		r.wallet = append(r.wallet, t)
+	}
+}
+
+func (r *inMemoryRepository) GetCustomerLogs(customerID string, limit, offset int) ([]PlaybackLog, error) {
+	var logs []PlaybackLog
+	for i := len(r.logs) - 1; i >= 0; i-- {
+		if r.logs[i].CustomerID == customerID {
+			logs = append(logs, r.logs[i])
+		}
+	}
+	return page(logs, limit, offset), nil
+}
+
+func (r *inMemoryRepository) GetWalletTransactions(customerID string, limit, offset int) ([]WalletTransaction, error) {
+	var transactions []WalletTransaction
+	for i := len(r.wallet) - 1; i >= 0; i-- {
+		if r.wallet[i].CustomerID == customerID {
+			transactions = append(transactions, r.wallet[i])
+		}
+	}
+	return page(transactions, limit, offset), nil
+}
+
+func (r *inMemoryRepository) SaveRoyaltyRate(rate RoyaltyRate) (*RoyaltyRate, error) {
+	for i, existing := range r.royaltyRates {
+		if existing.ArtistID == rate.ArtistID && existing.TrackID == rate.TrackID {
+			r.royaltyRates[i].Rate = rate.Rate
+			rate.ID = existing.ID
+			return &rate, nil
+		}
+	}
+	r.nextRoyaltyRateID++
+	rate.ID = r.nextRoyaltyRateID
+	r.royaltyRates = append(r.royaltyRates, rate)
+	return &rate, nil
+}
+
+func (r *inMemoryRepository) GetRoyaltyRates() ([]RoyaltyRate, error) {
+	return slices.Clone(r.royaltyRates), nil
+}
+
+func (r *inMemoryRepository) DeleteRoyaltyRate(id int) error {
+	for i, rate := range r.royaltyRates {
+		if rate.ID == id {
+			r.royaltyRates = slices.Delete(r.royaltyRates, i, i+1)
+			return nil
+		}
+	}
+	return fmt.Errorf("royalty rate with id %d not found", id)
+}
+
+func (r *inMemoryRepository) SaveRoyaltyStatement(statement RoyaltyStatement) (*RoyaltyStatement, error) {
+	for _, existing := range r.statements {
+		if existing.ArtistID == statement.ArtistID &&
+			existing.PeriodStart.Before(statement.PeriodEnd) && existing.PeriodEnd.After(statement.PeriodStart) {
+			return nil, fmt.Errorf("%w: statement %d", StatementOverlap, existing.ID)
+		}
+	}
+	statement.ID = len(r.statements) + 1
+	statement.Lines = slices.Clone(statement.Lines)
+	r.statements = append(r.statements, statement)
+	return &statement, nil
+}
+
+func (r *inMemoryRepository) GetRoyaltyStatements(artistID int) ([]RoyaltyStatement, error) {
+	var statements []RoyaltyStatement
+	for _, statement := range r.statements {
+		if artistID != 0 && statement.ArtistID != artistID {
+			continue
+		}
+		statement.Lines = nil
+		statements = append(statements, statement)
+	}
+	sort.Slice(statements, func(i, j int) bool {
+		a, b := statements[i], statements[j]
+		if !a.PeriodStart.Equal(b.PeriodStart) {
+			return a.PeriodStart.After(b.PeriodStart)
+		}
+		return a.ID > b.ID
+	})
+	return statements, nil
+}
+
+func (r *inMemoryRepository) GetRoyaltyStatement(id int) (*RoyaltyStatement, error) {
+	if id < 1 || id > len(r.statements) {
+		return nil, fmt.Errorf("royalty statement with id %d not found", id)
+	}
+	statement := r.statements[id-1]
+	statement.Lines = slices.Clone(statement.Lines)
+	return &statement, nil
+}
+
+func (r *inMemoryRepository) GetVenueTrackPlays(venueID string, from, to time.Time) ([]TrackPlayBucket, error) {
+	type key struct {
+		trackID int
+		start   time.Time
+	}
+	counts := make(map[key]int)
+	for _, log := range r.logs {
+		if log.PlayedAt.Before(from) || !log.PlayedAt.Before(to) || log.VoidedAt != nil {
+			continue
+		}
+		if log.DeviceID == "" || r.devices[log.DeviceID].VenueID != venueID {
+			continue
+		}
+		counts[key{log.TrackID, log.PlayedAt.UTC().Truncate(playBucket)}]++
+	}
+
+	var plays []TrackPlayBucket
+	for k, count := range counts {
+		plays = append(plays, TrackPlayBucket{TrackID: k.trackID, Start: k.start, Plays: count})
+	}
+	return plays, nil
+}
+
+func (r *inMemoryRepository) ImportCatalog(upserts []CatalogUpsert, at time.Time) ([]int, error) {
+	for _, upsert := range upserts {
+		if err := r.checkCatalogUpsert(upsert); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, len(upserts))
+	for i, upsert := range upserts {
+		track := upsert.Track
+		track.ArtistID = r.artistID(track.Artist)
+		track.Artist = r.artists[track.ArtistID].Name
+		track.Tags = slices.Clone(track.Tags)
+		r.ensureTags(track.Tags)
+
+		if track.ID == 0 {
+			for id := range r.tracks {
+				track.ID = max(track.ID, id)
+			}
+			for id := range r.redirects {
+				track.ID = max(track.ID, id)
+			}
+			track.ID++
+			r.tracks[track.ID] = &track
+		} else {
+			existing, err := r.GetTrackByID(track.ID)
+			if err != nil {
+				return nil, err
+			}
+			if existing.Price != track.Price {
+				r.addOutboxEvent(EventPriceUpdated, PriceUpdatedEvent{TrackID: track.ID, OldPrice: existing.Price, NewPrice: track.Price})
+				r.RecordPriceChange(PriceChange{
+					TrackID:   track.ID,
+					Source:    PriceSourceImport,
+					OldPrice:  existing.Price,
+					NewPrice:  track.Price,
+					Reason:    "catalog import",
+					ChangedAt: at,
+				})
+			}
+			*existing = track
+		}
+
+		for _, id := range upsert.ExternalIDs {
+			id.TrackID = track.ID
+			r.SaveExternalID(id)
+		}
+		ids[i] = track.ID
+	}
+	return ids, nil
+}
+
+// checkCatalogUpsert returns CatalogChanged when the catalog no longer
+// matches the one the upsert was planned against.
+func (r *inMemoryRepository) checkCatalogUpsert(upsert CatalogUpsert) error {
+	track := upsert.Track
+	if track.ID != 0 {
+		current, ok := r.tracks[track.ID]
+		if !ok || len(diffTrack(upsert.Previous, *current)) > 0 {
+			return fmt.Errorf("%w: row %d: track %d was changed", CatalogChanged, upsert.Row, track.ID)
+		}
+	}
+	for _, other := range r.tracks {
+		if other.ID == track.ID {
+			continue
+		}
+		if track.ISRC != "" && other.ISRC == track.ISRC {
+			return fmt.Errorf("%w: row %d: isrc %s was given to track %d", CatalogChanged, upsert.Row, track.ISRC, other.ID)
+		}
+		if track.ID == 0 && other.ID > upsert.LastTrackID && catalogKey(other.Title, other.Artist) == catalogKey(track.Title, track.Artist) {
+			return fmt.Errorf("%w: row %d: track %d was added", CatalogChanged, upsert.Row, other.ID)
+		}
+	}
+	for _, id := range upsert.ExternalIDs {
+		for _, existing := range r.externalIDs {
+			if existing.Source == id.Source && existing.ExternalID == id.ExternalID && existing.TrackID != track.ID {
+				return fmt.Errorf("%w: row %d: %s id %s was given to track %d", CatalogChanged, upsert.Row, id.Source, id.ExternalID, existing.TrackID)
+			}
+		}
+	}
+	return nil
+}
+
+// artistID returns the id of the artist with the name, ignoring case and
+// surrounding spaces, adding the artist when it is new.
+func (r *inMemoryRepository) artistID(name string) int {
+	name = strings.TrimSpace(name)
+	id := 0
+	for _, artist := range r.artists {
+		if strings.EqualFold(artist.Name, name) {
+			return artist.ID
+		}
+		id = max(id, artist.ID)
+	}
+	r.artists[id+1] = Artist{ID: id + 1, Name: name}
+	return id + 1
+}
+
+// ensureTags adds the tags that do not exist yet.
+func (r *inMemoryRepository) ensureTags(names []string) {
+	for _, name := range names {
+		if !slices.ContainsFunc(r.tags, func(t Tag) bool { return t.Name == name }) {
+			r.nextTagID++
+			r.tags = append(r.tags, Tag{ID: r.nextTagID, Name: name})
+		}
+	}
+}
+
+func (r *inMemoryRepository) tagByID(id int) (*Tag, error) {
+	for i := range r.tags {
+		if r.tags[i].ID == id {
+			return &r.tags[i], nil
+		}
+	}
+	return nil, fmt.Errorf("tag with id %d not found", id)
+}
+
+func (r *inMemoryRepository) GetTags() ([]Tag, error) {
+	tags := slices.Clone(r.tags)
+	for i := range tags {
+		for _, track := range r.tracks {
+			if slices.Contains(track.Tags, tags[i].Name) {
+				tags[i].Tracks++
+			}
+		}
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		return tags[i].Name < tags[j].Name
+	})
+	return tags, nil
+}
+
+func (r *inMemoryRepository) GetTagByName(name string) (*Tag, error) {
+	tags, _ := r.GetTags()
+	for _, tag := range tags {
+		if tag.Name == name {
+			return &tag, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *inMemoryRepository) CreateTag(name string) (*Tag, error) {
+	if slices.ContainsFunc(r.tags, func(t Tag) bool { return t.Name == name }) {
+		return nil, fmt.Errorf("tag %q already exists", name)
+	}
+	r.ensureTags([]string{name})
+	tag := r.tags[len(r.tags)-1]
+	return &tag, nil
+}
+
+func (r *inMemoryRepository) RenameTag(id int, name string) error {
+	tag, err := r.tagByID(id)
+	if err != nil {
+		return err
+	}
+	for _, track := range r.tracks {
+		if i := slices.Index(track.Tags, tag.Name); i >= 0 {
+			track.Tags[i] = name
+			slices.Sort(track.Tags)
+		}
+	}
+	tag.Name = name
+	return nil
+}
+
+func (r *inMemoryRepository) DeleteTag(id int) error {
+	tag, err := r.tagByID(id)
+	if err != nil {
+		return err
+	}
+	for _, track := range r.tracks {
+		track.Tags = slices.DeleteFunc(track.Tags, func(name string) bool { return name == tag.Name })
+	}
+	r.tags = slices.DeleteFunc(r.tags, func(t Tag) bool { return t.ID == id })
+	return nil
+}
+
+func (r *inMemoryRepository) TagTrack(trackID int, name string) error {
+	track, err := r.GetTrackByID(trackID)
+	if err != nil {
+		return err
+	}
+	r.ensureTags([]string{name})
+	if !slices.Contains(track.Tags, name) {
+		track.Tags = append(track.Tags, name)
+		slices.Sort(track.Tags)
+	}
+	return nil
+}
+
+func (r *inMemoryRepository) UntagTrack(trackID int, name string) error {
+	track, err := r.GetTrackByID(trackID)
+	if err != nil {
+		return err
+	}
+	i := slices.Index(track.Tags, name)
+	if i < 0 {
+		return fmt.Errorf("track %d has no tag %q", trackID, name)
+	}
+	track.Tags = slices.Delete(track.Tags, i, i+1)
+	return nil
+}
+
+func (r *inMemoryRepository) GetTagPlayBuckets(filter HeatmapFilter) ([]TagPlayBucket, error) {
+	var plays []TagPlayBucket
+	for _, tag := range r.tags {
+		if filter.Tag != "" && tag.Name != filter.Tag {
+			continue
+		}
+		tagFilter := filter
+		tagFilter.Tag = tag.Name
+		buckets, err := r.GetPlayBuckets(tagFilter)
+		if err != nil {
+			return nil, err
+		}
+		for _, b := range buckets {
+			plays = append(plays, TagPlayBucket{TagID: tag.ID, Tag: tag.Name, Start: b.Start, Plays: b.Plays, Revenue: b.Revenue})
+		}
 	}
+	return plays, nil
 }
@@ -3,20 +3,53 @@ package main
 import (
 	"database/sql"
 	"embed"
+	"encoding/json"
 	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 
-	_ "github.com/mattn/go-sqlite3"
+	"github.com/mattn/go-sqlite3"
 )
 
 //go:embed migrations/*.sql
 var migrationFS embed.FS
 
+// ftsMigration creates the FTS5 search index. It is left unapplied when the
+// driver was built without FTS5, and applied once it is.
+const ftsMigration = "014_track_search.sql"
+
+// sqliteOptions lets readers run alongside the writer (WAL) and makes a
+// writer wait for the lock instead of failing with SQLITE_BUSY.
+const sqliteOptions = "_busy_timeout=5000&_journal_mode=WAL"
+
+// sqliteDriver is go-sqlite3 with foldText registered as fold(), which the
+// LIKE search uses to match without regard to case or diacritics.
+const sqliteDriver = "sqlite3_fold"
+
+func init() {
+	sql.Register(sqliteDriver, &sqlite3.SQLiteDriver{
+		ConnectHook: func(conn *sqlite3.SQLiteConn) error {
+			return conn.RegisterFunc("fold", foldText, true)
+		},
+	})
+}
+
 type sqliteRepository struct {
 	db *sql.DB
+	// fts is set when SQLite has FTS5; search falls back to LIKE otherwise.
+	fts bool
 }
 
 func NewSQLiteRepository(dbPath string) (IRepository, error) {
-	db, err := sql.Open("sqlite3", dbPath)
+	sep := "?"
+	if strings.Contains(dbPath, "?") {
+		sep = "&"
+	}
+	db, err := sql.Open(sqliteDriver, dbPath+sep+sqliteOptions)
 	if err != nil {
 		return nil, err
 	}
@@ -26,6 +59,9 @@ func NewSQLiteRepository(dbPath string) (IRepository, error) {
 	}
 
 	repo := &sqliteRepository{db: db}
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&repo.fts); err != nil {
+		return nil, err
+	}
 	if err := repo.migrate(); err != nil {
 		return nil, fmt.Errorf("migration failed: %w", err)
 	}
@@ -34,29 +70,192 @@ func NewSQLiteRepository(dbPath string) (IRepository, error) {
 }
 
 func (r *sqliteRepository) migrate() error {
-	migrationSQL, err := migrationFS.ReadFile("migrations/001_init.sql")
+	_, err := r.db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version TEXT PRIMARY KEY,
+		applied_at DATETIME NOT NULL
+	)`)
 	if err != nil {
-		return fmt.Errorf("failed to read migration file: %w", err)
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
 	}
 
-	_, err = r.db.Exec(string(migrationSQL))
-	return err
+	if !r.fts {
+		// Writes to tracks would fail in the index's triggers. Dropping them
+		// and forgetting the migration has an FTS5 build rebuild the index.
+		for _, stmt := range []string{
+			"DROP TRIGGER IF EXISTS tracks_fts_insert",
+			"DROP TRIGGER IF EXISTS tracks_fts_delete",
+			"DROP TRIGGER IF EXISTS tracks_fts_update",
+		} {
+			if _, err := r.db.Exec(stmt); err != nil {
+				return fmt.Errorf("failed to detach search index: %w", err)
+			}
+		}
+		if _, err := r.db.Exec("DELETE FROM schema_migrations WHERE version = ?", ftsMigration); err != nil {
+			return fmt.Errorf("failed to detach search index: %w", err)
+		}
+	}
+
+	files, err := fs.Glob(migrationFS, "migrations/*.sql")
+	if err != nil {
+		return fmt.Errorf("failed to list migration files: %w", err)
+	}
+	sort.Strings(files)
+
+	for _, file := range files {
+		version := path.Base(file)
+		if version == ftsMigration && !r.fts {
+			continue
+		}
+
+		var applied int
+		if err := r.db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", version).Scan(&applied); err != nil {
+			return err
+		}
+		if applied > 0 {
+			continue
+		}
+
+		migrationSQL, err := migrationFS.ReadFile(file)
+		if err != nil {
+			return fmt.Errorf("failed to read migration file: %w", err)
+		}
+
+		tx, err := r.db.Begin()
+		if err != nil {
+			return err
+		}
+		if _, err := tx.Exec(string(migrationSQL)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("%s: %w", version, err)
+		}
+		if _, err := tx.Exec("INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)", version, time.Now().UTC()); err != nil {
+			tx.Rollback()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (r *sqliteRepository) GetTrackByID(id int) (*Track, error) {
-	row := r.db.QueryRow("SELECT id, title, artist, price FROM tracks WHERE id = ?", id)
+// trackColumns selects a track from the tracks table, which must not be
+// aliased. Tags are folded into one comma-separated column.
+const trackColumns = `tracks.id, tracks.title, tracks.artist, COALESCE(tracks.artist_id, 0), tracks.price,
+	tracks.duration_seconds, tracks.isrc, tracks.album, tracks.release_year,
+	(SELECT COALESCE(GROUP_CONCAT(g.name), '') FROM track_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.track_id = tracks.id)`
+
+func scanTrack(scanner interface{ Scan(...any) error }) (*Track, error) {
 	var t Track
-	if err := row.Scan(&t.ID, &t.Title, &t.Artist, &t.Price); err != nil {
-		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("track with id %d not found", id)
-		}
+	var tags string
+	if err := scanner.Scan(&t.ID, &t.Title, &t.Artist, &t.ArtistID, &t.Price, &t.DurationSeconds, &t.ISRC, &t.Album, &t.ReleaseYear, &tags); err != nil {
 		return nil, err
 	}
+	if tags != "" {
+		t.Tags = strings.Split(tags, ",")
+		sort.Strings(t.Tags)
+	}
 	return &t, nil
 }
 
-func (r *sqliteRepository) UpdateTrackPrice(id int, newPrice float64) error {
-	res, err := r.db.Exec("UPDATE tracks SET price = ? WHERE id = ?", newPrice, id)
+func (r *sqliteRepository) GetTracks() ([]Track, error) {
+	rows, err := r.db.Query("SELECT " + trackColumns + " FROM tracks ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	return scanTracks(rows)
+}
+
+func (r *sqliteRepository) GetTrackByID(id int) (*Track, error) {
+	row := r.db.QueryRow(
+		"SELECT "+trackColumns+" FROM tracks WHERE id = COALESCE((SELECT canonical_id FROM track_redirects WHERE track_id = ?), ?)",
+		id, id,
+	)
+	t, err := scanTrack(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("track with id %d not found", id)
+	}
+	return t, err
+}
+
+// ftsQuery turns free text into an FTS5 query matching every word as a
+// prefix. Words are quoted so FTS5 operators in the input are not interpreted.
+func ftsQuery(query string) string {
+	var terms []string
+	for _, word := range strings.Fields(query) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+func (r *sqliteRepository) SearchTracks(query string, limit, offset int) ([]Track, int, error) {
+	match := ftsQuery(query)
+	if match == "" || !r.fts {
+		return r.searchTracksLike(query, limit, offset)
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM tracks_fts WHERE tracks_fts MATCH ?", match).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.Query(`
+		SELECT `+trackColumns+`
+		FROM tracks_fts f
+		JOIN tracks ON tracks.id = f.rowid
+		WHERE tracks_fts MATCH ?
+		ORDER BY f.rank, tracks.id
+		LIMIT ? OFFSET ?
+	`, match, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	tracks, err := scanTracks(rows)
+	return tracks, total, err
+}
+
+// searchTracksLike matches every word of the query as a substring of the
+// title or artist, ignoring case and diacritics like the in-memory
+// repository. An empty query lists every track.
+func (r *sqliteRepository) searchTracksLike(query string, limit, offset int) ([]Track, int, error) {
+	where := "1 = 1"
+	var args []any
+	escape := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`)
+	for _, word := range strings.Fields(foldText(query)) {
+		where += ` AND fold(tracks.title || ' ' || tracks.artist) LIKE ? ESCAPE '\'`
+		args = append(args, "%"+escape.Replace(word)+"%")
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM tracks WHERE "+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+	rows, err := r.db.Query(
+		"SELECT "+trackColumns+" FROM tracks WHERE "+where+" ORDER BY tracks.id LIMIT ? OFFSET ?",
+		append(args, limit, offset)...,
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+	tracks, err := scanTracks(rows)
+	return tracks, total, err
+}
+
+func scanTracks(rows *sql.Rows) ([]Track, error) {
+	defer rows.Close()
+
+	var tracks []Track
+	for rows.Next() {
+		t, err := scanTrack(rows)
+		if err != nil {
+			return nil, err
+		}
+		tracks = append(tracks, *t)
+	}
+	return tracks, rows.Err()
+}
+
+func (r *sqliteRepository) SetTrackDuration(id int, seconds int) error {
+	res, err := r.db.Exec("UPDATE tracks SET duration_seconds = ? WHERE id = ?", seconds, id)
 	if err != nil {
 		return err
 	}
@@ -70,39 +269,399 @@ func (r *sqliteRepository) UpdateTrackPrice(id int, newPrice float64) error {
 	return nil
 }
 
-func (r *sqliteRepository) CreateLog(log PlaybackLog) error {
-	_, err := r.db.Exec("INSERT INTO playback_logs (track_id, played_at, amount_paid) VALUES (?, ?, ?)",
-		log.TrackID, log.PlayedAt, log.AmountPaid)
+// UpdateTrackMetadata writes a track's optional metadata and replaces its
+// tags.
+func (r *sqliteRepository) UpdateTrackMetadata(track Track) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"UPDATE tracks SET isrc = ?, album = ?, release_year = ?, duration_seconds = ? WHERE id = ?",
+		track.ISRC, track.Album, track.ReleaseYear, track.DurationSeconds, track.ID,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("track with id %d not found", track.ID)
+	}
+
+	if err := replaceTrackTags(tx, track.ID, track.Tags); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// replaceTrackTags sets a track's tags, creating tags that do not exist yet.
+func replaceTrackTags(tx *sql.Tx, trackID int, tags []string) error {
+	if _, err := tx.Exec("DELETE FROM track_tags WHERE track_id = ?", trackID); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", tag); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(
+			"INSERT OR IGNORE INTO track_tags (track_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+			trackID, tag,
+		); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetTrackByISRC(isrc string) (*Track, error) {
+	t, err := scanTrack(r.db.QueryRow("SELECT "+trackColumns+" FROM tracks WHERE isrc = ?", isrc))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *sqliteRepository) GetTrackByExternalID(source, externalID string) (*Track, error) {
+	t, err := scanTrack(r.db.QueryRow(`
+		SELECT `+trackColumns+`
+		FROM track_external_ids e
+		JOIN tracks ON tracks.id = e.track_id
+		WHERE e.source = ? AND e.external_id = ?
+	`, source, externalID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return t, err
+}
+
+func (r *sqliteRepository) SaveExternalID(id ExternalID) error {
+	_, err := r.db.Exec(`
+		INSERT INTO track_external_ids (track_id, source, external_id) VALUES (?, ?, ?)
+		ON CONFLICT (track_id, source) DO UPDATE SET external_id = excluded.external_id
+	`, id.TrackID, id.Source, id.ExternalID)
 	return err
 }
 
-func (r *sqliteRepository) GetAllLogs() []PlaybackLog {
-	rows, err := r.db.Query("SELECT id, track_id, played_at, amount_paid FROM playback_logs")
+func (r *sqliteRepository) DeleteExternalID(trackID int, source string) error {
+	res, err := r.db.Exec("DELETE FROM track_external_ids WHERE track_id = ? AND source = ?", trackID, source)
 	if err != nil {
-		return nil
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("external id %q for track %d not found", source, trackID)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetExternalIDs(trackID int) ([]ExternalID, error) {
+	rows, err := r.db.Query(
+		"SELECT track_id, source, external_id FROM track_external_ids WHERE track_id = ? ORDER BY source",
+		trackID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []ExternalID
+	for rows.Next() {
+		var id ExternalID
+		if err := rows.Scan(&id.TrackID, &id.Source, &id.ExternalID); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// MergeTracks reassigns the duplicate's plays, rollups, price history,
+// queue items, price schedules and rules, co-occurrences, partner ids and
+// tags to the canonical track. Where both tracks have a row for the same
+// key the canonical track's wins and counters are added up. Chart entries
+// and royalty statement lines are immutable and keep the old id; chart
+// reads resolve it through the redirect.
+func (r *sqliteRepository) MergeTracks(canonicalID, duplicateID int, at time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var isrc string
+	if err := tx.QueryRow("SELECT isrc FROM tracks WHERE id = ?", duplicateID).Scan(&isrc); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("track with id %d not found", duplicateID)
+		}
+		return err
+	}
+
+	ids := []any{canonicalID, duplicateID}
+	statements := []struct {
+		query string
+		args  []any
+	}{
+		{"UPDATE playback_logs SET track_id = ? WHERE track_id = ?", ids},
+		{"UPDATE price_changes SET track_id = ? WHERE track_id = ?", ids},
+		{"UPDATE play_queue SET track_id = ? WHERE track_id = ?", ids},
+		{"UPDATE scheduled_prices SET track_id = ? WHERE track_id = ?", ids},
+		{"UPDATE time_price_rules SET track_id = ? WHERE track_id = ?", ids},
+		{`
+			INSERT INTO playback_rollups (track_id, period_start, play_count, revenue)
+			SELECT ?, period_start, play_count, revenue FROM playback_rollups WHERE track_id = ?
+			ON CONFLICT (track_id, period_start) DO UPDATE SET
+				play_count = play_count + excluded.play_count,
+				revenue = revenue + excluded.revenue
+		`, ids},
+		{"DELETE FROM playback_rollups WHERE track_id = ?", ids[1:]},
+		{`
+			INSERT INTO track_cooccurrences (track_id, related_track_id, count)
+			SELECT ?1, related_track_id, count FROM track_cooccurrences WHERE track_id = ?2 AND related_track_id != ?1
+			ON CONFLICT (track_id, related_track_id) DO UPDATE SET count = count + excluded.count
+		`, ids},
+		{`
+			INSERT INTO track_cooccurrences (track_id, related_track_id, count)
+			SELECT track_id, ?1, count FROM track_cooccurrences WHERE related_track_id = ?2 AND track_id != ?1
+			ON CONFLICT (track_id, related_track_id) DO UPDATE SET count = count + excluded.count
+		`, ids},
+		{"DELETE FROM track_cooccurrences WHERE track_id = ?1 OR related_track_id = ?1", ids[1:]},
+		{"UPDATE OR IGNORE price_bounds SET track_id = ? WHERE track_id = ?", ids},
+		{"DELETE FROM price_bounds WHERE track_id = ?", ids[1:]},
+		{"UPDATE OR IGNORE royalty_rates SET track_id = ? WHERE track_id = ?", ids},
+		{"DELETE FROM royalty_rates WHERE track_id = ?", ids[1:]},
+		{"UPDATE OR IGNORE track_external_ids SET track_id = ? WHERE track_id = ?", ids},
+		{"DELETE FROM track_external_ids WHERE track_id = ?", ids[1:]},
+		{"UPDATE OR IGNORE track_tags SET track_id = ? WHERE track_id = ?", ids},
+		{"DELETE FROM track_tags WHERE track_id = ?", ids[1:]},
+		{"UPDATE track_redirects SET canonical_id = ? WHERE canonical_id = ?", ids},
+		{"INSERT INTO track_redirects (track_id, canonical_id, merged_at) VALUES (?, ?, ?)", []any{duplicateID, canonicalID, at.UTC()}},
+		{"DELETE FROM tracks WHERE id = ?", ids[1:]},
+		{"UPDATE tracks SET isrc = ? WHERE id = ? AND isrc = ''", []any{isrc, canonicalID}},
+	}
+	for _, stmt := range statements {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) UpdateTrackPrice(id int, newPrice float64) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var oldPrice float64
+	if err := tx.QueryRow("SELECT price FROM tracks WHERE id = ?", id).Scan(&oldPrice); err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("track with id %d not found", id)
+		}
+		return err
+	}
+
+	if _, err := tx.Exec("UPDATE tracks SET price = ? WHERE id = ?", newPrice, id); err != nil {
+		return err
+	}
+
+	event := PriceUpdatedEvent{TrackID: id, OldPrice: oldPrice, NewPrice: newPrice}
+	if err := insertOutboxEvent(tx, EventPriceUpdated, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) CreateLog(log PlaybackLog) (*PlaybackLog, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertPlaybackLog(tx, &log); err != nil {
+		return nil, err
+	}
+	return &log, tx.Commit()
+}
+
+// insertPlaybackLog inserts the log, sets its ID and writes the matching
+// outbox event in the same transaction.
+func insertPlaybackLog(tx *sql.Tx, log *PlaybackLog) error {
+	res, err := tx.Exec(`
+		INSERT INTO playback_logs (track_id, played_at, amount_paid, device_id, session_id, list_price, payment_status,
+			customer_id, paid_with_credit)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, log.TrackID, log.PlayedAt.UTC(), log.AmountPaid, log.DeviceID, log.SessionID, log.ListPrice, log.PaymentStatus,
+		log.CustomerID, log.PaidWithCredit)
+	if err != nil {
+		return err
+	}
+	logID, err := res.LastInsertId()
+	if err != nil {
+		return err
+	}
+	log.ID = int(logID)
+
+	event := PlaybackLoggedEvent{
+		LogID:      log.ID,
+		TrackID:    log.TrackID,
+		PlayedAt:   log.PlayedAt.UTC(),
+		AmountPaid: log.AmountPaid,
+		DeviceID:   log.DeviceID,
+		SessionID:  log.SessionID,
+	}
+	return insertOutboxEvent(tx, EventPlaybackLogged, event)
+}
+
+const playbackLogColumns = `id, track_id, played_at, amount_paid, device_id, session_id, list_price, payment_status,
+	voided_at, void_reason, refunded_amount, completed_at, listened_seconds, skipped, quarantined,
+	customer_id, paid_with_credit`
+
+func scanPlaybackLog(scanner interface{ Scan(...any) error }) (*PlaybackLog, error) {
+	var l PlaybackLog
+	var voidedAt, completedAt sql.NullTime
+	if err := scanner.Scan(&l.ID, &l.TrackID, &l.PlayedAt, &l.AmountPaid, &l.DeviceID, &l.SessionID,
+		&l.ListPrice, &l.PaymentStatus, &voidedAt, &l.VoidReason, &l.RefundedAmount,
+		&completedAt, &l.ListenedSeconds, &l.Skipped, &l.Quarantined,
+		&l.CustomerID, &l.PaidWithCredit); err != nil {
+		return nil, err
 	}
+	if voidedAt.Valid {
+		l.VoidedAt = &voidedAt.Time
+	}
+	if completedAt.Valid {
+		l.CompletedAt = &completedAt.Time
+	}
+	return &l, nil
+}
+
+func scanPlaybackLogs(rows *sql.Rows) ([]PlaybackLog, error) {
 	defer rows.Close()
 
 	var logs []PlaybackLog
 	for rows.Next() {
-		var l PlaybackLog
-		if err := rows.Scan(&l.ID, &l.TrackID, &l.PlayedAt, &l.AmountPaid); err == nil {
-			logs = append(logs, l)
+		l, err := scanPlaybackLog(rows)
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, *l)
+	}
+	return logs, rows.Err()
+}
+
+func (r *sqliteRepository) GetLogByID(id int) (*PlaybackLog, error) {
+	row := r.db.QueryRow("SELECT "+playbackLogColumns+" FROM playback_logs WHERE id = ?", id)
+	l, err := scanPlaybackLog(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("playback log with id %d not found", id)
+	}
+	return l, err
+}
+
+func (r *sqliteRepository) VoidLog(id int, reason string, refundedAmount float64, voidedAt time.Time) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		UPDATE playback_logs SET voided_at = ?, void_reason = ?, refunded_amount = ?
+		WHERE id = ? AND voided_at IS NULL
+	`, voidedAt.UTC(), reason, refundedAmount, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("playback log with id %d not found or already voided", id)
+	}
+
+	var trackID int
+	var customerID string
+	var paidWithCredit bool
+	err = tx.QueryRow(
+		"SELECT track_id, customer_id, paid_with_credit FROM playback_logs WHERE id = ?", id,
+	).Scan(&trackID, &customerID, &paidWithCredit)
+	if err != nil {
+		return err
+	}
+	if customerID != "" {
+		if err := voidCustomerPlay(tx, id, customerID, paidWithCredit, refundedAmount, voidedAt); err != nil {
+			return err
 		}
 	}
+	event := PlaybackVoidedEvent{LogID: id, TrackID: trackID, Reason: reason, RefundedAmount: refundedAmount, VoidedAt: voidedAt.UTC()}
+	if err := insertOutboxEvent(tx, EventPlaybackVoided, event); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) CompleteLog(id int, listenedSeconds int, skipped bool, completedAt time.Time) error {
+	res, err := r.db.Exec(`
+		UPDATE playback_logs SET completed_at = ?, listened_seconds = ?, skipped = ?
+		WHERE id = ? AND completed_at IS NULL
+	`, completedAt.UTC(), listenedSeconds, skipped, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("playback log with id %d not found or already completed", id)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetAllLogs() []PlaybackLog {
+	rows, err := r.db.Query("SELECT " + playbackLogColumns + " FROM playback_logs")
+	if err != nil {
+		return nil
+	}
+	logs, _ := scanPlaybackLogs(rows)
 	return logs
 }
 
 func (r *sqliteRepository) GetTopTracks(limit int) ([]TopTrackStat, error) {
+	return r.topTracks(limit, "")
+}
+
+func (r *sqliteRepository) GetTagTopTracks(limit int, tag string) ([]TopTrackStat, error) {
+	return r.topTracks(limit, tag)
+}
+
+// topTracks ranks tracks by plays, only counting tracks with the tag unless
+// it is empty.
+func (r *sqliteRepository) topTracks(limit int, tag string) ([]TopTrackStat, error) {
 	query := `
-		SELECT t.title, COUNT(l.id) as play_count
-		FROM playback_logs l
-		JOIN tracks t ON l.track_id = t.id
+		SELECT t.title, SUM(p.plays) as play_count
+		FROM (
+			SELECT track_id, COUNT(id) as plays FROM playback_logs WHERE voided_at IS NULL AND quarantined = 0 GROUP BY track_id
+			UNION ALL
+			SELECT track_id, SUM(play_count) FROM playback_rollups GROUP BY track_id
+		) p
+		JOIN tracks t ON p.track_id = t.id
+		WHERE ? = '' OR t.id IN (` + taggedTracksQuery + `)
 		GROUP BY t.id
 		ORDER BY play_count DESC
 		LIMIT ?
 	`
-	rows, err := r.db.Query(query, limit)
+	rows, err := r.db.Query(query, tag, tag, limit)
 	if err != nil {
 		return nil, err
 	}
@@ -118,3 +677,2312 @@ func (r *sqliteRepository) GetTopTracks(limit int) ([]TopTrackStat, error) {
 	}
 	return stats, nil
 }
+
+func (r *sqliteRepository) GetTrackPlayCounts(from, to time.Time) ([]TrackPlayCount, error) {
+	query := `
+		SELECT t.id, t.title, COUNT(l.id)
+		FROM playback_logs l
+		JOIN tracks t ON l.track_id = t.id
+		WHERE l.played_at >= ? AND l.played_at < ? AND l.voided_at IS NULL AND l.quarantined = 0
+		GROUP BY t.id
+	`
+	rows, err := r.db.Query(query, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []TrackPlayCount
+	for rows.Next() {
+		var s TrackPlayCount
+		if err := rows.Scan(&s.TrackID, &s.Title, &s.Count); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *sqliteRepository) GetTrackPlayRange(trackID int) (*time.Time, *time.Time, error) {
+	var first, last sql.NullString
+	err := r.db.QueryRow(`
+		SELECT MIN(played_at), MAX(played_at) FROM (
+			SELECT strftime('%Y-%m-%d %H:%M:%S', played_at) AS played_at
+			FROM playback_logs
+			WHERE track_id = ? AND voided_at IS NULL AND quarantined = 0
+			UNION ALL
+			SELECT strftime('%Y-%m-%d %H:%M:%S', period_start)
+			FROM playback_rollups
+			WHERE track_id = ? AND play_count > 0
+		)
+	`, trackID, trackID).Scan(&first, &last)
+	if err != nil || !first.Valid {
+		return nil, nil, err
+	}
+
+	firstAt, err := time.Parse(time.DateTime, first.String)
+	if err != nil {
+		return nil, nil, err
+	}
+	lastAt, err := time.Parse(time.DateTime, last.String)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &firstAt, &lastAt, nil
+}
+
+func (r *sqliteRepository) GetSkipStats(from, to time.Time) ([]SkipStat, error) {
+	query := `
+		SELECT t.id, t.title, t.duration_seconds, COUNT(l.id), SUM(l.skipped),
+			AVG(CASE WHEN t.duration_seconds > 0
+				THEN MIN(CAST(l.listened_seconds AS REAL) / t.duration_seconds, 1.0) END)
+		FROM playback_logs l
+		JOIN tracks t ON l.track_id = t.id
+		WHERE l.played_at >= ? AND l.played_at < ? AND l.voided_at IS NULL AND l.quarantined = 0 AND l.completed_at IS NOT NULL
+		GROUP BY t.id
+		ORDER BY t.id
+	`
+	rows, err := r.db.Query(query, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []SkipStat
+	for rows.Next() {
+		var s SkipStat
+		var avgCompletion sql.NullFloat64
+		if err := rows.Scan(&s.TrackID, &s.Title, &s.DurationSeconds, &s.Plays, &s.Skips, &avgCompletion); err != nil {
+			return nil, err
+		}
+		s.SkipRate = float64(s.Skips) / float64(s.Plays)
+		s.AvgCompletion = avgCompletion.Float64
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *sqliteRepository) GetExpiredLogs(cutoff time.Time, limit int) ([]PlaybackLog, error) {
+	rows, err := r.db.Query(`
+		SELECT `+playbackLogColumns+`
+		FROM playback_logs
+		WHERE played_at < ?
+		ORDER BY id
+		LIMIT ?
+	`, cutoff.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlaybackLogs(rows)
+}
+
+func (r *sqliteRepository) PurgeExpiredLogs(cutoff time.Time, maxID int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO playback_rollups (track_id, period_start, play_count, revenue)
+		SELECT track_id, strftime('%Y-%m-%d %H:00:00+00:00', played_at),
+			SUM(voided_at IS NULL AND quarantined = 0), SUM((amount_paid - refunded_amount) * (quarantined = 0))
+		FROM playback_logs
+		WHERE played_at < ? AND id <= ?
+		GROUP BY track_id, strftime('%Y-%m-%d %H:00:00+00:00', played_at)
+		ON CONFLICT (track_id, period_start) DO UPDATE SET
+			play_count = play_count + excluded.play_count,
+			revenue = revenue + excluded.revenue
+	`, cutoff.UTC(), maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to roll up logs: %w", err)
+	}
+
+	res, err := tx.Exec("DELETE FROM playback_logs WHERE played_at < ? AND id <= ?", cutoff.UTC(), maxID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete logs: %w", err)
+	}
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), tx.Commit()
+}
+
+func (r *sqliteRepository) HasRollups(from, to time.Time) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(
+		"SELECT EXISTS (SELECT 1 FROM playback_rollups WHERE period_start >= ? AND period_start < ?)", from.UTC(), to.UTC(),
+	).Scan(&exists)
+	return exists, err
+}
+
+func (r *sqliteRepository) GetExpiredLogsSummary(cutoff time.Time) ([]RetentionMonth, error) {
+	rows, err := r.db.Query(`
+		SELECT strftime('%Y-%m', played_at) as month, COUNT(id), SUM(amount_paid - refunded_amount)
+		FROM playback_logs
+		WHERE played_at < ?
+		GROUP BY month
+		ORDER BY month
+	`, cutoff.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var months []RetentionMonth
+	for rows.Next() {
+		var m RetentionMonth
+		if err := rows.Scan(&m.Month, &m.Plays, &m.Revenue); err != nil {
+			return nil, err
+		}
+		months = append(months, m)
+	}
+	return months, rows.Err()
+}
+
+// insertOutboxEvent queues an event for the webhook dispatcher. Events
+// nobody subscribes to are dropped rather than left to pile up.
+func insertOutboxEvent(tx *sql.Tx, eventType string, data any) error {
+	var subscribed bool
+	err := tx.QueryRow("SELECT EXISTS (SELECT 1 FROM webhook_subscriptions WHERE instr(',' || event_types || ',', ',' || ? || ',') > 0)",
+		eventType).Scan(&subscribed)
+	if err != nil {
+		return fmt.Errorf("failed to check webhook subscriptions: %w", err)
+	}
+	if !subscribed {
+		return nil
+	}
+
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec("INSERT INTO webhook_outbox (event_type, payload, created_at) VALUES (?, ?, ?)",
+		eventType, string(payload), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to write outbox event: %w", err)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) CreateWebhook(sub WebhookSubscription) (*WebhookSubscription, error) {
+	res, err := r.db.Exec("INSERT INTO webhook_subscriptions (url, event_types, secret, created_at) VALUES (?, ?, ?, ?)",
+		sub.URL, strings.Join(sub.EventTypes, ","), sub.Secret, sub.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	sub.ID = int(id)
+	return &sub, nil
+}
+
+func (r *sqliteRepository) GetWebhooks() ([]WebhookSubscription, error) {
+	rows, err := r.db.Query("SELECT id, url, event_types, secret, created_at FROM webhook_subscriptions ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []WebhookSubscription
+	for rows.Next() {
+		var sub WebhookSubscription
+		var eventTypes string
+		if err := rows.Scan(&sub.ID, &sub.URL, &eventTypes, &sub.Secret, &sub.CreatedAt); err != nil {
+			return nil, err
+		}
+		sub.EventTypes = strings.Split(eventTypes, ",")
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+func (r *sqliteRepository) DeleteWebhook(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec("DELETE FROM webhook_subscriptions WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("webhook with id %d not found", id)
+	}
+
+	_, err = tx.Exec("UPDATE webhook_deliveries SET status = ?, last_error = ? WHERE subscription_id = ? AND status = ?",
+		DeliveryFailed, "subscription deleted", id, DeliveryPending)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) GetWebhookDeliveries(subscriptionID int, limit int) ([]WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		SELECT d.id, d.subscription_id, d.event_id, o.event_type, d.status, d.attempts,
+			d.next_attempt_at, d.last_status_code, d.last_error, d.delivered_at
+		FROM webhook_deliveries d
+		JOIN webhook_outbox o ON d.event_id = o.id
+		WHERE d.subscription_id = ?
+		ORDER BY d.id DESC
+		LIMIT ?
+	`, subscriptionID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var deliveredAt sql.NullTime
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.EventType, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &deliveredAt); err != nil {
+			return nil, err
+		}
+		if deliveredAt.Valid {
+			d.DeliveredAt = &deliveredAt.Time
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *sqliteRepository) DispatchOutbox(limit int) (int, error) {
+	subs, err := r.GetWebhooks()
+	if err != nil {
+		return 0, err
+	}
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query("SELECT id, event_type, created_at FROM webhook_outbox WHERE dispatched_at IS NULL ORDER BY id LIMIT ?", limit)
+	if err != nil {
+		return 0, err
+	}
+	var events []WebhookEvent
+	for rows.Next() {
+		var e WebhookEvent
+		if err := rows.Scan(&e.ID, &e.Type, &e.CreatedAt); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		events = append(events, e)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	now := time.Now().UTC()
+	for _, e := range events {
+		for _, sub := range subs {
+			if !sub.Subscribes(e.Type) {
+				continue
+			}
+			_, err := tx.Exec("INSERT INTO webhook_deliveries (subscription_id, event_id, status, next_attempt_at) VALUES (?, ?, ?, ?)",
+				sub.ID, e.ID, DeliveryPending, e.CreatedAt.UTC())
+			if err != nil {
+				return 0, err
+			}
+		}
+		if _, err := tx.Exec("UPDATE webhook_outbox SET dispatched_at = ? WHERE id = ?", now, e.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(events), tx.Commit()
+}
+
+func (r *sqliteRepository) PurgeWebhookHistory(cutoff time.Time) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		DELETE FROM webhook_deliveries
+		WHERE status != ? AND event_id IN (
+			SELECT id FROM webhook_outbox WHERE dispatched_at IS NOT NULL AND created_at < ?
+		)
+	`, DeliveryPending, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	res, err := tx.Exec(`
+		DELETE FROM webhook_outbox
+		WHERE dispatched_at IS NOT NULL AND created_at < ?
+			AND NOT EXISTS (SELECT 1 FROM webhook_deliveries d WHERE d.event_id = webhook_outbox.id)
+	`, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	purged, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+	return int(purged), tx.Commit()
+}
+
+func (r *sqliteRepository) GetDueDeliveries(now time.Time, limit int) ([]WebhookDelivery, error) {
+	rows, err := r.db.Query(`
+		SELECT d.id, d.subscription_id, d.event_id, d.status, d.attempts, d.next_attempt_at,
+			s.url, s.secret, o.event_type, o.payload, o.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_subscriptions s ON d.subscription_id = s.id
+		JOIN webhook_outbox o ON d.event_id = o.id
+		WHERE d.status = ? AND d.next_attempt_at <= ?
+		ORDER BY d.next_attempt_at
+		LIMIT ?
+	`, DeliveryPending, now.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		var d WebhookDelivery
+		var payload string
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.Status, &d.Attempts, &d.NextAttemptAt,
+			&d.URL, &d.Secret, &d.Event.Type, &payload, &d.Event.CreatedAt); err != nil {
+			return nil, err
+		}
+		d.Event.ID = d.EventID
+		d.Event.Data = []byte(payload)
+		d.EventType = d.Event.Type
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+func (r *sqliteRepository) UpdateDelivery(d WebhookDelivery) error {
+	var deliveredAt any
+	if d.DeliveredAt != nil {
+		deliveredAt = d.DeliveredAt.UTC()
+	}
+	_, err := r.db.Exec(`
+		UPDATE webhook_deliveries
+		SET status = ?, attempts = ?, next_attempt_at = ?, last_status_code = ?, last_error = ?, delivered_at = ?
+		WHERE id = ?
+	`, d.Status, d.Attempts, d.NextAttemptAt.UTC(), d.LastStatusCode, d.LastError, deliveredAt, d.ID)
+	return err
+}
+
+const cooccurrenceCursor = "cooccurrence"
+
+func (r *sqliteRepository) GetLogsAfter(id int, limit int) ([]PlaybackLog, error) {
+	rows, err := r.db.Query("SELECT "+playbackLogColumns+" FROM playback_logs WHERE id > ? ORDER BY id LIMIT ?", id, limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlaybackLogs(rows)
+}
+
+func (r *sqliteRepository) GetSessionLogsBefore(log PlaybackLog, since time.Time, limit int) ([]PlaybackLog, error) {
+	column, key := "session_id", log.SessionID
+	if key == "" {
+		column, key = "device_id", log.DeviceID
+	}
+	rows, err := r.db.Query(`
+		SELECT `+playbackLogColumns+`
+		FROM playback_logs
+		WHERE `+column+` = ? AND id < ? AND played_at >= ?
+		ORDER BY id DESC
+		LIMIT ?
+	`, key, log.ID, since.UTC(), limit)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlaybackLogs(rows)
+}
+
+func (r *sqliteRepository) GetCooccurrenceCursor() (int, error) {
+	var lastID int
+	err := r.db.QueryRow("SELECT last_id FROM job_cursors WHERE name = ?", cooccurrenceCursor).Scan(&lastID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return lastID, err
+}
+
+func (r *sqliteRepository) AddCooccurrences(pairs []TrackPair, lastLogID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, p := range pairs {
+		_, err := tx.Exec(`
+			INSERT INTO track_cooccurrences (track_id, related_track_id, count) VALUES (?, ?, 1)
+			ON CONFLICT (track_id, related_track_id) DO UPDATE SET count = count + 1
+		`, p.TrackID, p.RelatedTrackID)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO job_cursors (name, last_id) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_id = excluded.last_id
+	`, cooccurrenceCursor, lastLogID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) GetRelatedTracks(trackID int, limit int) ([]RelatedTrack, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.title, t.artist, c.count
+		FROM track_cooccurrences c
+		JOIN tracks t ON c.related_track_id = t.id
+		WHERE c.track_id = ?
+		ORDER BY c.count DESC, t.id
+		LIMIT ?
+	`, trackID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var related []RelatedTrack
+	for rows.Next() {
+		var rt RelatedTrack
+		if err := rows.Scan(&rt.TrackID, &rt.Title, &rt.Artist, &rt.Count); err != nil {
+			return nil, err
+		}
+		related = append(related, rt)
+	}
+	return related, rows.Err()
+}
+
+func (r *sqliteRepository) CreatePricingRule(rule PricingRule) (*PricingRule, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO pricing_rules (name, target, track_count, adjust_percent, window_hours, cooldown_hours, enabled, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, rule.Target, rule.TrackCount, rule.AdjustPercent, rule.WindowHours, rule.CooldownHours, rule.Enabled, rule.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = int(id)
+	return &rule, nil
+}
+
+func (r *sqliteRepository) GetPricingRules() ([]PricingRule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, target, track_count, adjust_percent, window_hours, cooldown_hours, enabled, created_at
+		FROM pricing_rules
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []PricingRule
+	for rows.Next() {
+		var rule PricingRule
+		if err := rows.Scan(&rule.ID, &rule.Name, &rule.Target, &rule.TrackCount, &rule.AdjustPercent,
+			&rule.WindowHours, &rule.CooldownHours, &rule.Enabled, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *sqliteRepository) DeletePricingRule(id int) error {
+	res, err := r.db.Exec("DELETE FROM pricing_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("pricing rule with id %d not found", id)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) SetPriceBounds(bounds PriceBounds) error {
+	_, err := r.db.Exec(`
+		INSERT INTO price_bounds (track_id, floor, ceiling) VALUES (?, ?, ?)
+		ON CONFLICT (track_id) DO UPDATE SET floor = excluded.floor, ceiling = excluded.ceiling
+	`, bounds.TrackID, bounds.Floor, bounds.Ceiling)
+	return err
+}
+
+func (r *sqliteRepository) GetPriceBounds() ([]PriceBounds, error) {
+	rows, err := r.db.Query("SELECT track_id, floor, ceiling FROM price_bounds")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var bounds []PriceBounds
+	for rows.Next() {
+		var b PriceBounds
+		if err := rows.Scan(&b.TrackID, &b.Floor, &b.Ceiling); err != nil {
+			return nil, err
+		}
+		bounds = append(bounds, b)
+	}
+	return bounds, rows.Err()
+}
+
+func (r *sqliteRepository) RecordPriceChange(change PriceChange) error {
+	var ruleID any
+	if change.RuleID != 0 {
+		ruleID = change.RuleID
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO price_changes (track_id, rule_id, source, old_price, new_price, reason, changed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, change.TrackID, ruleID, change.Source, change.OldPrice, change.NewPrice, change.Reason, change.ChangedAt.UTC())
+	return err
+}
+
+func (r *sqliteRepository) queryPriceChanges(query string, args ...any) ([]PriceChange, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var changes []PriceChange
+	for rows.Next() {
+		var c PriceChange
+		var ruleID sql.NullInt64
+		if err := rows.Scan(&c.ID, &c.TrackID, &ruleID, &c.Source, &c.OldPrice, &c.NewPrice, &c.Reason, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+		c.RuleID = int(ruleID.Int64)
+		changes = append(changes, c)
+	}
+	return changes, rows.Err()
+}
+
+func (r *sqliteRepository) GetPriceChanges(limit int) ([]PriceChange, error) {
+	return r.queryPriceChanges(`
+		SELECT id, track_id, rule_id, source, old_price, new_price, reason, changed_at
+		FROM price_changes
+		ORDER BY id DESC
+		LIMIT ?
+	`, limit)
+}
+
+func (r *sqliteRepository) GetLatestPriceChanges() ([]PriceChange, error) {
+	return r.queryPriceChanges(`
+		SELECT id, track_id, rule_id, source, old_price, new_price, reason, changed_at
+		FROM price_changes
+		WHERE id IN (SELECT MAX(id) FROM price_changes GROUP BY track_id)
+	`)
+}
+
+const scheduledPriceColumns = "id, track_id, price, starts_at, ends_at, status, previous_price, created_at"
+
+func scanScheduledPrice(scanner interface{ Scan(...any) error }) (*ScheduledPrice, error) {
+	var sp ScheduledPrice
+	var endsAt sql.NullTime
+	var previousPrice sql.NullFloat64
+	if err := scanner.Scan(&sp.ID, &sp.TrackID, &sp.Price, &sp.StartsAt, &endsAt, &sp.Status, &previousPrice, &sp.CreatedAt); err != nil {
+		return nil, err
+	}
+	if endsAt.Valid {
+		sp.EndsAt = &endsAt.Time
+	}
+	if previousPrice.Valid {
+		sp.PreviousPrice = &previousPrice.Float64
+	}
+	return &sp, nil
+}
+
+func nullableTime(t *time.Time) any {
+	if t == nil {
+		return nil
+	}
+	return t.UTC()
+}
+
+func (r *sqliteRepository) CreateScheduledPrice(sp ScheduledPrice) (*ScheduledPrice, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO scheduled_prices (track_id, price, starts_at, ends_at, status, created_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`, sp.TrackID, sp.Price, sp.StartsAt.UTC(), nullableTime(sp.EndsAt), sp.Status, sp.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	sp.ID = int(id)
+	return &sp, nil
+}
+
+func (r *sqliteRepository) GetScheduledPriceByID(id int) (*ScheduledPrice, error) {
+	row := r.db.QueryRow("SELECT "+scheduledPriceColumns+" FROM scheduled_prices WHERE id = ?", id)
+	sp, err := scanScheduledPrice(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("scheduled price with id %d not found", id)
+	}
+	return sp, err
+}
+
+func (r *sqliteRepository) GetScheduledPrices(status string) ([]ScheduledPrice, error) {
+	rows, err := r.db.Query(`
+		SELECT `+scheduledPriceColumns+`
+		FROM scheduled_prices
+		WHERE ? = '' OR status = ?
+		ORDER BY starts_at, id
+	`, status, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var prices []ScheduledPrice
+	for rows.Next() {
+		sp, err := scanScheduledPrice(rows)
+		if err != nil {
+			return nil, err
+		}
+		prices = append(prices, *sp)
+	}
+	return prices, rows.Err()
+}
+
+func (r *sqliteRepository) UpdateScheduledPrice(sp ScheduledPrice) error {
+	_, err := r.db.Exec("UPDATE scheduled_prices SET status = ?, previous_price = ? WHERE id = ?",
+		sp.Status, sp.PreviousPrice, sp.ID)
+	return err
+}
+
+func joinWeekdays(days []int) string {
+	weekdays := make([]string, len(days))
+	for i, d := range days {
+		weekdays[i] = strconv.Itoa(d)
+	}
+	return strings.Join(weekdays, ",")
+}
+
+func splitWeekdays(value string) ([]int, error) {
+	days := []int{}
+	if value == "" {
+		return days, nil
+	}
+	for _, d := range strings.Split(value, ",") {
+		day, err := strconv.Atoi(d)
+		if err != nil {
+			return nil, err
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+func (r *sqliteRepository) CreateTimePriceRule(rule TimePriceRule) (*TimePriceRule, error) {
+	var trackID any
+	if rule.TrackID != 0 {
+		trackID = rule.TrackID
+	}
+
+	res, err := r.db.Exec(`
+		INSERT INTO time_price_rules (name, track_id, artist, weekdays, start_time, end_time, price, adjust_percent, time_zone, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, rule.Name, trackID, rule.Artist, joinWeekdays(rule.Weekdays), rule.StartTime, rule.EndTime,
+		rule.Price, rule.AdjustPercent, rule.TimeZone, rule.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	rule.ID = int(id)
+	return &rule, nil
+}
+
+func (r *sqliteRepository) GetTimePriceRules() ([]TimePriceRule, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, track_id, artist, weekdays, start_time, end_time, price, adjust_percent, time_zone, created_at
+		FROM time_price_rules
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []TimePriceRule
+	for rows.Next() {
+		var rule TimePriceRule
+		var trackID sql.NullInt64
+		var weekdays string
+		if err := rows.Scan(&rule.ID, &rule.Name, &trackID, &rule.Artist, &weekdays, &rule.StartTime, &rule.EndTime,
+			&rule.Price, &rule.AdjustPercent, &rule.TimeZone, &rule.CreatedAt); err != nil {
+			return nil, err
+		}
+		rule.TrackID = int(trackID.Int64)
+		if rule.Weekdays, err = splitWeekdays(weekdays); err != nil {
+			return nil, err
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *sqliteRepository) DeleteTimePriceRule(id int) error {
+	res, err := r.db.Exec("DELETE FROM time_price_rules WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("time price rule with id %d not found", id)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetSetting(key string) (string, error) {
+	var value string
+	err := r.db.QueryRow("SELECT value FROM settings WHERE key = ?", key).Scan(&value)
+	if err == sql.ErrNoRows {
+		return "", nil
+	}
+	return value, err
+}
+
+func (r *sqliteRepository) SetSetting(key, value string) error {
+	_, err := r.db.Exec(`
+		INSERT INTO settings (key, value) VALUES (?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value
+	`, key, value)
+	return err
+}
+
+func (r *sqliteRepository) GetReconciliationBuckets(from, to time.Time) ([]ReconciliationBucket, error) {
+	rows, err := r.db.Query(`
+		SELECT `+playBucketExpr+` AS start, l.device_id, COUNT(l.id),
+			SUM(l.payment_status = ?), SUM(l.payment_status = ?),
+			SUM(l.list_price), SUM(l.amount_paid)
+		FROM playback_logs l
+		WHERE l.played_at >= ? AND l.played_at < ? AND l.payment_status != ''
+		GROUP BY start, l.device_id
+	`, PaymentUnderpaid, PaymentOverpaid, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []ReconciliationBucket
+	for rows.Next() {
+		var b ReconciliationBucket
+		var start string
+		if err := rows.Scan(&start, &b.DeviceID, &b.Plays, &b.Underpaid, &b.Overpaid,
+			&b.ExpectedTotal, &b.PaidTotal); err != nil {
+			return nil, err
+		}
+		if b.Start, err = time.Parse(time.DateTime, start); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (r *sqliteRepository) GetPaymentDiscrepancies(from, to time.Time, limit int) ([]PaymentDiscrepancy, error) {
+	rows, err := r.db.Query(`
+		SELECT id, track_id, device_id, played_at, list_price, amount_paid, payment_status
+		FROM playback_logs
+		WHERE played_at >= ? AND played_at < ? AND payment_status IN (?, ?)
+		ORDER BY id
+		LIMIT ?
+	`, from.UTC(), to.UTC(), PaymentUnderpaid, PaymentOverpaid, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var discrepancies []PaymentDiscrepancy
+	for rows.Next() {
+		var d PaymentDiscrepancy
+		if err := rows.Scan(&d.LogID, &d.TrackID, &d.DeviceID, &d.PlayedAt, &d.ListPrice, &d.AmountPaid, &d.PaymentStatus); err != nil {
+			return nil, err
+		}
+		discrepancies = append(discrepancies, d)
+	}
+	return discrepancies, rows.Err()
+}
+
+const queueItemColumns = `id, device_id, session_id, track_id, amount_paid, list_price, payment_status, status,
+	log_id, enqueued_at, started_at, finished_at`
+
+func scanQueueItem(scanner interface{ Scan(...any) error }) (*QueueItem, error) {
+	var item QueueItem
+	var logID sql.NullInt64
+	var startedAt, finishedAt sql.NullTime
+	if err := scanner.Scan(&item.ID, &item.DeviceID, &item.SessionID, &item.TrackID, &item.AmountPaid, &item.ListPrice,
+		&item.PaymentStatus, &item.Status, &logID, &item.EnqueuedAt, &startedAt, &finishedAt); err != nil {
+		return nil, err
+	}
+	item.LogID = int(logID.Int64)
+	if startedAt.Valid {
+		item.StartedAt = &startedAt.Time
+	}
+	if finishedAt.Valid {
+		item.FinishedAt = &finishedAt.Time
+	}
+	return &item, nil
+}
+
+func (r *sqliteRepository) EnqueueTrack(item QueueItem) (*QueueItem, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO play_queue (device_id, session_id, track_id, amount_paid, list_price, payment_status, status, enqueued_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, item.DeviceID, item.SessionID, item.TrackID, item.AmountPaid, item.ListPrice, item.PaymentStatus, item.Status, item.EnqueuedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	item.ID = int(id)
+	return &item, nil
+}
+
+func (r *sqliteRepository) GetQueue(deviceID string) ([]QueueItem, error) {
+	rows, err := r.db.Query(`
+		SELECT `+queueItemColumns+`
+		FROM play_queue
+		WHERE device_id = ? AND status IN (?, ?)
+		ORDER BY id
+	`, deviceID, QueueStatusQueued, QueueStatusPlaying)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var queue []QueueItem
+	for rows.Next() {
+		item, err := scanQueueItem(rows)
+		if err != nil {
+			return nil, err
+		}
+		queue = append(queue, *item)
+	}
+	return queue, rows.Err()
+}
+
+func (r *sqliteRepository) GetQueueItemByID(id int) (*QueueItem, error) {
+	row := r.db.QueryRow("SELECT "+queueItemColumns+" FROM play_queue WHERE id = ?", id)
+	item, err := scanQueueItem(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("queue item with id %d not found", id)
+	}
+	return item, err
+}
+
+func (r *sqliteRepository) StartQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertPlaybackLog(tx, &log); err != nil {
+		return nil, err
+	}
+
+	item.LogID = log.ID
+	res, err := tx.Exec("UPDATE play_queue SET status = ?, log_id = ?, started_at = ? WHERE id = ? AND status = ?",
+		item.Status, item.LogID, nullableTime(item.StartedAt), item.ID, QueueStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("queue item with id %d is no longer queued", item.ID)
+	}
+	return &item, tx.Commit()
+}
+
+func (r *sqliteRepository) CancelQueueItem(item QueueItem, log PlaybackLog) (*QueueItem, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertPlaybackLog(tx, &log); err != nil {
+		return nil, err
+	}
+	_, err = tx.Exec("UPDATE playback_logs SET voided_at = ?, void_reason = ?, refunded_amount = ? WHERE id = ?",
+		log.VoidedAt.UTC(), log.VoidReason, log.RefundedAmount, log.ID)
+	if err != nil {
+		return nil, err
+	}
+	event := PlaybackVoidedEvent{
+		LogID:          log.ID,
+		TrackID:        log.TrackID,
+		Reason:         log.VoidReason,
+		RefundedAmount: log.RefundedAmount,
+		VoidedAt:       log.VoidedAt.UTC(),
+	}
+	if err := insertOutboxEvent(tx, EventPlaybackVoided, event); err != nil {
+		return nil, err
+	}
+
+	item.LogID = log.ID
+	res, err := tx.Exec("UPDATE play_queue SET status = ?, log_id = ?, finished_at = ? WHERE id = ? AND status = ?",
+		item.Status, item.LogID, nullableTime(item.FinishedAt), item.ID, QueueStatusQueued)
+	if err != nil {
+		return nil, err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rowsAffected == 0 {
+		return nil, fmt.Errorf("queue item with id %d is no longer queued", item.ID)
+	}
+	return &item, tx.Commit()
+}
+
+func (r *sqliteRepository) UpdateQueueItem(item QueueItem) error {
+	_, err := r.db.Exec("UPDATE play_queue SET status = ?, finished_at = ? WHERE id = ?",
+		item.Status, nullableTime(item.FinishedAt), item.ID)
+	return err
+}
+
+const anomalyCursor = "anomaly_detector"
+
+func (r *sqliteRepository) GetAnomalyCursor() (int, error) {
+	var lastID int
+	err := r.db.QueryRow("SELECT last_id FROM job_cursors WHERE name = ?", anomalyCursor).Scan(&lastID)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return lastID, err
+}
+
+func (r *sqliteRepository) GetDeviceBaseline(deviceID string) (*DeviceBaseline, error) {
+	b := DeviceBaseline{DeviceID: deviceID}
+	err := r.db.QueryRow(`
+		SELECT plays, zero_plays, amount_mean, amount_m2, rate_mean FROM device_baselines WHERE device_id = ?
+	`, deviceID).Scan(&b.Plays, &b.ZeroPlays, &b.AmountMean, &b.AmountM2, &b.RateMean)
+	if err != nil && err != sql.ErrNoRows {
+		return nil, err
+	}
+	return &b, nil
+}
+
+func (r *sqliteRepository) GetDeviceActivity(deviceID string, since time.Time, maxLogID int) (int, int, error) {
+	var plays, zeroPlays int
+	err := r.db.QueryRow(`
+		SELECT COUNT(id), COALESCE(SUM(amount_paid = 0), 0)
+		FROM playback_logs
+		WHERE device_id = ? AND id <= ? AND played_at >= ? AND voided_at IS NULL
+	`, deviceID, maxLogID, since.UTC()).Scan(&plays, &zeroPlays)
+	return plays, zeroPlays, err
+}
+
+func (r *sqliteRepository) SaveAnomalyBatch(baselines []DeviceBaseline, alerts []Alert, lastLogID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, b := range baselines {
+		_, err := tx.Exec(`
+			INSERT INTO device_baselines (device_id, plays, zero_plays, amount_mean, amount_m2, rate_mean)
+			VALUES (?, ?, ?, ?, ?, ?)
+			ON CONFLICT (device_id) DO UPDATE SET
+				plays = excluded.plays,
+				zero_plays = excluded.zero_plays,
+				amount_mean = excluded.amount_mean,
+				amount_m2 = excluded.amount_m2,
+				rate_mean = excluded.rate_mean
+		`, b.DeviceID, b.Plays, b.ZeroPlays, b.AmountMean, b.AmountM2, b.RateMean)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, a := range alerts {
+		if a.ID == 0 {
+			_, err = tx.Exec(`
+				INSERT INTO alerts (device_id, kind, message, log_id, count, window_start, window_end, quarantined, created_at)
+				VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+			`, a.DeviceID, a.Kind, a.Message, nullableID(a.LogID), a.Count, a.WindowStart.UTC(), a.WindowEnd.UTC(), a.Quarantined, a.CreatedAt.UTC())
+		} else {
+			_, err = tx.Exec("UPDATE alerts SET count = ?, window_end = ? WHERE id = ?", a.Count, a.WindowEnd.UTC(), a.ID)
+		}
+		if err != nil {
+			return err
+		}
+		if a.Quarantined {
+			if err := setQuarantined(tx, a, true); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO job_cursors (name, last_id) VALUES (?, ?)
+		ON CONFLICT (name) DO UPDATE SET last_id = excluded.last_id
+	`, anomalyCursor, lastLogID)
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func setQuarantined(tx *sql.Tx, alert Alert, quarantined bool) error {
+	_, err := tx.Exec(`
+		UPDATE playback_logs SET quarantined = ?
+		WHERE device_id = ? AND played_at >= ? AND played_at <= ?
+	`, quarantined, alert.DeviceID, alert.WindowStart.UTC(), alert.WindowEnd.UTC())
+	return err
+}
+
+func nullableID(id int) any {
+	if id == 0 {
+		return nil
+	}
+	return id
+}
+
+const alertColumns = `id, device_id, kind, message, log_id, count, window_start, window_end, quarantined, created_at, resolved_at`
+
+func scanAlert(scanner interface{ Scan(...any) error }) (*Alert, error) {
+	var a Alert
+	var logID sql.NullInt64
+	var resolvedAt sql.NullTime
+	if err := scanner.Scan(&a.ID, &a.DeviceID, &a.Kind, &a.Message, &logID, &a.Count,
+		&a.WindowStart, &a.WindowEnd, &a.Quarantined, &a.CreatedAt, &resolvedAt); err != nil {
+		return nil, err
+	}
+	a.LogID = int(logID.Int64)
+	if resolvedAt.Valid {
+		a.ResolvedAt = &resolvedAt.Time
+	}
+	return &a, nil
+}
+
+func (r *sqliteRepository) GetOpenAlert(deviceID, kind string) (*Alert, error) {
+	row := r.db.QueryRow(`
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE device_id = ? AND kind = ? AND resolved_at IS NULL
+		ORDER BY id DESC
+		LIMIT 1
+	`, deviceID, kind)
+	a, err := scanAlert(row)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return a, err
+}
+
+func (r *sqliteRepository) GetAlerts(deviceID string, includeResolved bool) ([]Alert, error) {
+	rows, err := r.db.Query(`
+		SELECT `+alertColumns+`
+		FROM alerts
+		WHERE (? = '' OR device_id = ?) AND (? OR resolved_at IS NULL)
+		ORDER BY id DESC
+	`, deviceID, deviceID, includeResolved)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var alerts []Alert
+	for rows.Next() {
+		a, err := scanAlert(rows)
+		if err != nil {
+			return nil, err
+		}
+		alerts = append(alerts, *a)
+	}
+	return alerts, rows.Err()
+}
+
+func (r *sqliteRepository) GetAlertByID(id int) (*Alert, error) {
+	row := r.db.QueryRow("SELECT "+alertColumns+" FROM alerts WHERE id = ?", id)
+	a, err := scanAlert(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("alert with id %d not found", id)
+	}
+	return a, err
+}
+
+func (r *sqliteRepository) ResolveAlert(id int, resolvedAt time.Time, release bool) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	alert, err := scanAlert(tx.QueryRow("SELECT "+alertColumns+" FROM alerts WHERE id = ? AND resolved_at IS NULL", id))
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("alert with id %d not found or already resolved", id)
+	}
+	if err != nil {
+		return err
+	}
+
+	quarantined := alert.Quarantined && !release
+	if _, err := tx.Exec("UPDATE alerts SET resolved_at = ?, quarantined = ? WHERE id = ?", resolvedAt.UTC(), quarantined, id); err != nil {
+		return err
+	}
+	if alert.Quarantined && release {
+		if err := setQuarantined(tx, *alert, false); err != nil {
+			return err
+		}
+		// Plays may also be covered by other alerts that keep them quarantined.
+		rows, err := tx.Query(`
+			SELECT `+alertColumns+`
+			FROM alerts
+			WHERE device_id = ? AND quarantined = 1 AND resolved_at IS NULL AND id != ?
+		`, alert.DeviceID, id)
+		if err != nil {
+			return err
+		}
+		var others []Alert
+		for rows.Next() {
+			other, err := scanAlert(rows)
+			if err != nil {
+				rows.Close()
+				return err
+			}
+			others = append(others, *other)
+		}
+		rows.Close()
+		for _, other := range others {
+			if err := setQuarantined(tx, other, true); err != nil {
+				return err
+			}
+		}
+	}
+	return tx.Commit()
+}
+
+const deviceColumns = `device_id, venue_id, firmware_version, first_seen_at, last_seen_at`
+
+func scanDevice(scanner interface{ Scan(...any) error }) (*Device, error) {
+	var d Device
+	if err := scanner.Scan(&d.DeviceID, &d.VenueID, &d.FirmwareVersion, &d.FirstSeenAt, &d.LastSeenAt); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r *sqliteRepository) RecordHeartbeat(device Device) (*Device, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO devices (device_id, venue_id, firmware_version, first_seen_at, last_seen_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT (device_id) DO UPDATE SET
+			venue_id = CASE WHEN excluded.venue_id != '' THEN excluded.venue_id ELSE venue_id END,
+			firmware_version = CASE WHEN excluded.firmware_version != '' THEN excluded.firmware_version ELSE firmware_version END,
+			last_seen_at = excluded.last_seen_at
+	`, device.DeviceID, device.VenueID, device.FirmwareVersion, device.LastSeenAt.UTC(), device.LastSeenAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return r.GetDeviceByID(device.DeviceID)
+}
+
+func (r *sqliteRepository) GetDevices() ([]Device, error) {
+	rows, err := r.db.Query("SELECT " + deviceColumns + " FROM devices ORDER BY device_id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var devices []Device
+	for rows.Next() {
+		d, err := scanDevice(rows)
+		if err != nil {
+			return nil, err
+		}
+		devices = append(devices, *d)
+	}
+	return devices, rows.Err()
+}
+
+func (r *sqliteRepository) GetDeviceByID(id string) (*Device, error) {
+	d, err := scanDevice(r.db.QueryRow("SELECT "+deviceColumns+" FROM devices WHERE device_id = ?", id))
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("device %q not found", id)
+	}
+	return d, err
+}
+
+func (r *sqliteRepository) CreateOfflineAlert(alert Alert, event DeviceOfflineEvent) (*Alert, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+		INSERT INTO alerts (device_id, kind, message, count, window_start, window_end, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, alert.DeviceID, alert.Kind, alert.Message, alert.Count, alert.WindowStart.UTC(), alert.WindowEnd.UTC(), alert.CreatedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	alert.ID = int(id)
+
+	event.AlertID = alert.ID
+	event.LastSeenAt = event.LastSeenAt.UTC()
+	if err := insertOutboxEvent(tx, EventDeviceOffline, event); err != nil {
+		return nil, err
+	}
+	return &alert, tx.Commit()
+}
+
+func (r *sqliteRepository) SaveVenue(venue Venue) error {
+	_, err := r.db.Exec(`
+		INSERT INTO venues (id, name, time_zone, weekdays, opens_at, closes_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT (id) DO UPDATE SET
+			name = excluded.name,
+			time_zone = excluded.time_zone,
+			weekdays = excluded.weekdays,
+			opens_at = excluded.opens_at,
+			closes_at = excluded.closes_at
+	`, venue.ID, venue.Name, venue.TimeZone, joinWeekdays(venue.Weekdays), venue.OpensAt, venue.ClosesAt)
+	return err
+}
+
+func scanVenue(scanner interface{ Scan(...any) error }) (*Venue, error) {
+	var v Venue
+	var weekdays string
+	if err := scanner.Scan(&v.ID, &v.Name, &v.TimeZone, &weekdays, &v.OpensAt, &v.ClosesAt); err != nil {
+		return nil, err
+	}
+	var err error
+	if v.Weekdays, err = splitWeekdays(weekdays); err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+func (r *sqliteRepository) GetVenues() ([]Venue, error) {
+	rows, err := r.db.Query("SELECT id, name, time_zone, weekdays, opens_at, closes_at FROM venues ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var venues []Venue
+	for rows.Next() {
+		v, err := scanVenue(rows)
+		if err != nil {
+			return nil, err
+		}
+		venues = append(venues, *v)
+	}
+	return venues, rows.Err()
+}
+
+func (r *sqliteRepository) GetVenueByID(id string) (*Venue, error) {
+	row := r.db.QueryRow("SELECT id, name, time_zone, weekdays, opens_at, closes_at FROM venues WHERE id = ?", id)
+	v, err := scanVenue(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("venue %q not found", id)
+	}
+	return v, err
+}
+
+func (r *sqliteRepository) GetArtists() ([]Artist, error) {
+	rows, err := r.db.Query("SELECT id, name FROM artists ORDER BY name")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var artists []Artist
+	for rows.Next() {
+		var a Artist
+		if err := rows.Scan(&a.ID, &a.Name); err != nil {
+			return nil, err
+		}
+		artists = append(artists, a)
+	}
+	return artists, rows.Err()
+}
+
+func (r *sqliteRepository) GetArtistByID(id int) (*Artist, error) {
+	var a Artist
+	err := r.db.QueryRow("SELECT id, name FROM artists WHERE id = ?", id).Scan(&a.ID, &a.Name)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("artist with id %d not found", id)
+	}
+	return &a, err
+}
+
+// trackTotalsQuery sums plays and net revenue per track from raw logs and
+// retention rollups in [from, to). It takes from and to twice.
+const trackTotalsQuery = `
+	SELECT track_id, SUM(plays) AS plays, SUM(revenue) AS revenue FROM (
+		SELECT track_id, SUM(voided_at IS NULL AND quarantined = 0) AS plays,
+			SUM((amount_paid - refunded_amount) * (quarantined = 0)) AS revenue
+		FROM playback_logs
+		WHERE played_at >= ? AND played_at < ?
+		GROUP BY track_id
+		UNION ALL
+		SELECT track_id, SUM(play_count), SUM(revenue)
+		FROM playback_rollups
+		WHERE period_start >= ? AND period_start < ?
+		GROUP BY track_id
+	)
+	GROUP BY track_id
+`
+
+func (r *sqliteRepository) GetArtistStats(from, to time.Time) ([]ArtistStat, error) {
+	rows, err := r.db.Query(`
+		SELECT a.id, a.name, COALESCE(SUM(p.plays), 0), COALESCE(SUM(p.revenue), 0)
+		FROM artists a
+		JOIN tracks t ON t.artist_id = a.id
+		JOIN (`+trackTotalsQuery+`) p ON p.track_id = t.id
+		GROUP BY a.id
+	`, from.UTC(), to.UTC(), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []ArtistStat
+	for rows.Next() {
+		var s ArtistStat
+		if err := rows.Scan(&s.ArtistID, &s.Name, &s.Plays, &s.Revenue); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+func (r *sqliteRepository) GetArtistTrackStats(artistID int, from, to time.Time) ([]TrackStat, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.title, COALESCE(p.plays, 0), COALESCE(p.revenue, 0)
+		FROM tracks t
+		LEFT JOIN (`+trackTotalsQuery+`) p ON p.track_id = t.id
+		WHERE t.artist_id = ?
+		ORDER BY t.id
+	`, from.UTC(), to.UTC(), from.UTC(), to.UTC(), artistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []TrackStat
+	for rows.Next() {
+		var s TrackStat
+		if err := rows.Scan(&s.TrackID, &s.Title, &s.Plays, &s.Revenue); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// playBucketExpr truncates l.played_at to its UTC quarter hour, matching
+// playBucket.
+const playBucketExpr = `strftime('%Y-%m-%d %H:', l.played_at) ||
+	printf('%02d:00', CAST(strftime('%M', l.played_at) AS INTEGER) / 15 * 15)`
+
+func (r *sqliteRepository) GetPlayBuckets(filter HeatmapFilter) ([]PlayBucket, error) {
+	from, to := filter.From.UTC(), filter.To.UTC()
+	// Rollups carry no device, so they are left out when filtering by venue.
+	rows, err := r.db.Query(`
+		SELECT start, SUM(plays), SUM(revenue) FROM (
+			SELECT `+playBucketExpr+` AS start,
+				SUM(l.voided_at IS NULL AND l.quarantined = 0) AS plays,
+				SUM((l.amount_paid - l.refunded_amount) * (l.quarantined = 0)) AS revenue
+			FROM playback_logs l
+			JOIN tracks t ON t.id = l.track_id
+			LEFT JOIN devices d ON d.device_id = l.device_id
+			WHERE l.played_at >= ? AND l.played_at < ?
+				AND (? = '' OR d.venue_id = ?)
+				AND (? = 0 OR l.track_id = ?)
+				AND (? = 0 OR t.artist_id = ?)
+				AND (? = '' OR l.track_id IN (`+taggedTracksQuery+`))
+			GROUP BY start
+			UNION ALL
+			SELECT strftime('%Y-%m-%d %H:00:00', p.period_start), SUM(p.play_count), SUM(p.revenue)
+			FROM playback_rollups p
+			JOIN tracks t ON t.id = p.track_id
+			WHERE ? = '' AND p.period_start >= ? AND p.period_start < ?
+				AND (? = 0 OR p.track_id = ?)
+				AND (? = 0 OR t.artist_id = ?)
+				AND (? = '' OR p.track_id IN (`+taggedTracksQuery+`))
+			GROUP BY p.period_start
+		)
+		GROUP BY start
+	`, from, to, filter.VenueID, filter.VenueID, filter.TrackID, filter.TrackID, filter.ArtistID, filter.ArtistID, filter.Tag, filter.Tag,
+		filter.VenueID, from, to, filter.TrackID, filter.TrackID, filter.ArtistID, filter.ArtistID, filter.Tag, filter.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []PlayBucket
+	for rows.Next() {
+		var b PlayBucket
+		var start string
+		if err := rows.Scan(&start, &b.Plays, &b.Revenue); err != nil {
+			return nil, err
+		}
+		if b.Start, err = time.Parse(time.DateTime, start); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
+
+func (r *sqliteRepository) GetTrackTotals(from, to time.Time) ([]TrackStat, error) {
+	rows, err := r.db.Query(`
+		SELECT t.id, t.title, p.plays, p.revenue
+		FROM tracks t
+		JOIN (`+trackTotalsQuery+`) p ON p.track_id = t.id
+	`, from.UTC(), to.UTC(), from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []TrackStat
+	for rows.Next() {
+		var s TrackStat
+		if err := rows.Scan(&s.TrackID, &s.Title, &s.Plays, &s.Revenue); err != nil {
+			return nil, err
+		}
+		stats = append(stats, s)
+	}
+	return stats, rows.Err()
+}
+
+// Chart entries keep the track id they were charted with; chartEntriesFrom
+// resolves merged tracks to their canonical track.
+const (
+	chartEntryColumns = `e.snapshot_id, e.position, COALESCE(r.canonical_id, e.track_id), COALESCE(t.title, ''),
+	COALESCE(t.artist, ''), e.plays, e.revenue, e.previous_position, e.movement, e.weeks_on_chart, e.peak_position`
+	chartEntriesFrom = `chart_entries e
+		LEFT JOIN track_redirects r ON r.track_id = e.track_id
+		LEFT JOIN tracks t ON t.id = COALESCE(r.canonical_id, e.track_id)`
+)
+
+func scanChartEntries(rows *sql.Rows) ([]ChartEntry, error) {
+	var entries []ChartEntry
+	for rows.Next() {
+		var e ChartEntry
+		if err := rows.Scan(&e.SnapshotID, &e.Position, &e.TrackID, &e.Title, &e.Artist,
+			&e.Plays, &e.Revenue, &e.PreviousPosition, &e.Movement, &e.WeeksOnChart, &e.PeakPosition); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
+
+// GetChartHistory keeps the higher placed entry where a snapshot charted
+// both a track and a duplicate merged into it.
+func (r *sqliteRepository) GetChartHistory() ([]ChartEntry, error) {
+	rows, err := r.db.Query(`
+		SELECT ` + chartEntryColumns + `
+		FROM ` + chartEntriesFrom + `
+		WHERE NOT EXISTS (
+			SELECT 1 FROM chart_entries o
+			LEFT JOIN track_redirects ro ON ro.track_id = o.track_id
+			WHERE COALESCE(ro.canonical_id, o.track_id) = COALESCE(r.canonical_id, e.track_id)
+				AND (o.snapshot_id > e.snapshot_id OR (o.snapshot_id = e.snapshot_id AND o.position < e.position))
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanChartEntries(rows)
+}
+
+func (r *sqliteRepository) SaveChartSnapshot(snapshot ChartSnapshot) (*ChartSnapshot, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(
+		"INSERT INTO chart_snapshots (period_start, period_end, created_at) VALUES (?, ?, ?)",
+		snapshot.PeriodStart.UTC(), snapshot.PeriodEnd.UTC(), snapshot.CreatedAt.UTC(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	snapshot.ID = int(id)
+
+	for i := range snapshot.Entries {
+		e := &snapshot.Entries[i]
+		e.SnapshotID = snapshot.ID
+		_, err := tx.Exec(`
+			INSERT INTO chart_entries (snapshot_id, position, track_id, plays, revenue,
+				previous_position, movement, weeks_on_chart, peak_position)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, e.SnapshotID, e.Position, e.TrackID, e.Plays, e.Revenue,
+			e.PreviousPosition, e.Movement, e.WeeksOnChart, e.PeakPosition)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &snapshot, tx.Commit()
+}
+
+func (r *sqliteRepository) GetChartSnapshots() ([]ChartSnapshot, error) {
+	rows, err := r.db.Query("SELECT id, period_start, period_end, created_at FROM chart_snapshots ORDER BY period_start DESC")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snapshots []ChartSnapshot
+	for rows.Next() {
+		var s ChartSnapshot
+		if err := rows.Scan(&s.ID, &s.PeriodStart, &s.PeriodEnd, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, s)
+	}
+	return snapshots, rows.Err()
+}
+
+func (r *sqliteRepository) GetChartSnapshot(id int) (*ChartSnapshot, error) {
+	var s ChartSnapshot
+	err := r.db.QueryRow(
+		"SELECT id, period_start, period_end, created_at FROM chart_snapshots WHERE id = ?", id,
+	).Scan(&s.ID, &s.PeriodStart, &s.PeriodEnd, &s.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT `+chartEntryColumns+`
+		FROM `+chartEntriesFrom+`
+		WHERE e.snapshot_id = ?
+		ORDER BY e.position
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	if s.Entries, err = scanChartEntries(rows); err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sqliteRepository) GetCustomer(id string) (*Customer, error) {
+	var c Customer
+	err := r.db.QueryRow(
+		"SELECT id, balance, plays, created_at FROM customers WHERE id = ?", id,
+	).Scan(&c.ID, &c.Balance, &c.Plays, &c.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// voidCustomerPlay takes a voided play off the customer's count, refunds
+// what was paid with credit and reverses the loyalty reward it earned.
+func voidCustomerPlay(tx *sql.Tx, logID int, customerID string, paidWithCredit bool, refundedAmount float64, at time.Time) error {
+	if _, err := tx.Exec("UPDATE customers SET plays = plays - 1 WHERE id = ?", customerID); err != nil {
+		return err
+	}
+	if paidWithCredit && refundedAmount > 0 {
+		refund := WalletTransaction{CustomerID: customerID, Kind: WalletRefund, Amount: refundedAmount, LogID: logID, CreatedAt: at}
+		if err := applyWalletTransaction(tx, refund); err != nil {
+			return err
+		}
+	}
+
+	var reward float64
+	err := tx.QueryRow(
+		"SELECT COALESCE(SUM(amount), 0) FROM wallet_transactions WHERE log_id = ? AND kind = ?", logID, WalletLoyalty,
+	).Scan(&reward)
+	if err != nil {
+		return err
+	}
+	if reward > 0 {
+		reversal := WalletTransaction{CustomerID: customerID, Kind: WalletLoyaltyReversal, Amount: -reward, LogID: logID, CreatedAt: at}
+		if err := applyWalletTransaction(tx, reversal); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func ensureCustomer(tx *sql.Tx, customerID string, at time.Time) error {
+	_, err := tx.Exec("INSERT OR IGNORE INTO customers (id, balance, created_at) VALUES (?, 0, ?)", customerID, at.UTC())
+	return err
+}
+
+// applyWalletTransaction records the transaction and moves the balance,
+// refusing debits that would take it below zero.
+func applyWalletTransaction(tx *sql.Tx, t WalletTransaction) error {
+	res, err := tx.Exec(
+		"UPDATE customers SET balance = balance + ? WHERE id = ? AND (? != ? OR balance + ? >= ?)",
+		t.Amount, t.CustomerID, t.Kind, WalletDebit, t.Amount, -paymentTolerance,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("insufficient credit for customer %s", t.CustomerID)
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO wallet_transactions (customer_id, kind, amount, log_id, created_at)
+		VALUES (?, ?, ?, ?, ?)
+	`, t.CustomerID, t.Kind, t.Amount, nullableID(t.LogID), t.CreatedAt.UTC())
+	return err
+}
+
+func (r *sqliteRepository) TopUpCredit(customerID string, amount float64, at time.Time) (*Customer, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureCustomer(tx, customerID, at); err != nil {
+		return nil, err
+	}
+	err = applyWalletTransaction(tx, WalletTransaction{CustomerID: customerID, Kind: WalletTopUp, Amount: amount, CreatedAt: at})
+	if err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return r.GetCustomer(customerID)
+}
+
+func (r *sqliteRepository) CreateCustomerLog(log PlaybackLog, wallet []WalletTransaction) (*PlaybackLog, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := ensureCustomer(tx, log.CustomerID, log.PlayedAt); err != nil {
+		return nil, err
+	}
+	if err := insertPlaybackLog(tx, &log); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec("UPDATE customers SET plays = plays + 1 WHERE id = ?", log.CustomerID); err != nil {
+		return nil, err
+	}
+	for _, t := range wallet {
+		t.LogID = log.ID
+		if err := applyWalletTransaction(tx, t); err != nil {
+			return nil, err
+		}
+	}
+	return &log, tx.Commit()
+}
+
+func (r *sqliteRepository) GetCustomerLogs(customerID string, limit, offset int) ([]PlaybackLog, error) {
+	rows, err := r.db.Query(
+		"SELECT "+playbackLogColumns+" FROM playback_logs WHERE customer_id = ? ORDER BY id DESC LIMIT ? OFFSET ?",
+		customerID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return scanPlaybackLogs(rows)
+}
+
+func (r *sqliteRepository) GetWalletTransactions(customerID string, limit, offset int) ([]WalletTransaction, error) {
+	rows, err := r.db.Query(`
+		SELECT id, customer_id, kind, amount, COALESCE(log_id, 0), created_at
+		FROM wallet_transactions
+		WHERE customer_id = ?
+		ORDER BY id DESC
+		LIMIT ? OFFSET ?
+	`, customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var transactions []WalletTransaction
+	for rows.Next() {
+		var t WalletTransaction
+		if err := rows.Scan(&t.ID, &t.CustomerID, &t.Kind, &t.Amount, &t.LogID, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, t)
+	}
+	return transactions, rows.Err()
+}
+
+func (r *sqliteRepository) SaveRoyaltyRate(rate RoyaltyRate) (*RoyaltyRate, error) {
+	_, err := r.db.Exec(`
+		INSERT INTO royalty_rates (artist_id, track_id, rate) VALUES (?, ?, ?)
+		ON CONFLICT (artist_id, track_id) DO UPDATE SET rate = excluded.rate
+	`, rate.ArtistID, rate.TrackID, rate.Rate)
+	if err != nil {
+		return nil, err
+	}
+	err = r.db.QueryRow(
+		"SELECT id FROM royalty_rates WHERE artist_id = ? AND track_id = ?", rate.ArtistID, rate.TrackID,
+	).Scan(&rate.ID)
+	if err != nil {
+		return nil, err
+	}
+	return &rate, nil
+}
+
+func (r *sqliteRepository) GetRoyaltyRates() ([]RoyaltyRate, error) {
+	rows, err := r.db.Query("SELECT id, artist_id, track_id, rate FROM royalty_rates ORDER BY id")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rates []RoyaltyRate
+	for rows.Next() {
+		var rate RoyaltyRate
+		if err := rows.Scan(&rate.ID, &rate.ArtistID, &rate.TrackID, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+	return rates, rows.Err()
+}
+
+func (r *sqliteRepository) DeleteRoyaltyRate(id int) error {
+	res, err := r.db.Exec("DELETE FROM royalty_rates WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("royalty rate with id %d not found", id)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) SaveRoyaltyStatement(statement RoyaltyStatement) (*RoyaltyStatement, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Checked in the transaction, a statement closed concurrently fails the
+	// insert rather than slipping past the check.
+	var overlapping int
+	err = tx.QueryRow(`
+		SELECT id FROM royalty_statements
+		WHERE artist_id = ? AND period_start < ? AND period_end > ?
+	`, statement.ArtistID, statement.PeriodEnd.UTC(), statement.PeriodStart.UTC()).Scan(&overlapping)
+	if err == nil {
+		return nil, fmt.Errorf("%w: statement %d", StatementOverlap, overlapping)
+	}
+	if err != sql.ErrNoRows {
+		return nil, err
+	}
+
+	res, err := tx.Exec(`
+		INSERT INTO royalty_statements (artist_id, artist_name, period_start, period_end, revenue, owed, closed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, statement.ArtistID, statement.ArtistName, statement.PeriodStart.UTC(), statement.PeriodEnd.UTC(),
+		statement.Revenue, statement.Owed, statement.ClosedAt.UTC())
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	statement.ID = int(id)
+
+	for _, line := range statement.Lines {
+		_, err := tx.Exec(`
+			INSERT INTO royalty_statement_lines (statement_id, track_id, title, plays, revenue, rate, owed)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, statement.ID, line.TrackID, line.Title, line.Plays, line.Revenue, line.Rate, line.Owed)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &statement, tx.Commit()
+}
+
+const royaltyStatementColumns = `id, artist_id, artist_name, period_start, period_end, revenue, owed, closed_at`
+
+func scanRoyaltyStatement(scanner interface{ Scan(...any) error }) (*RoyaltyStatement, error) {
+	var s RoyaltyStatement
+	var closedAt time.Time
+	if err := scanner.Scan(&s.ID, &s.ArtistID, &s.ArtistName, &s.PeriodStart, &s.PeriodEnd,
+		&s.Revenue, &s.Owed, &closedAt); err != nil {
+		return nil, err
+	}
+	s.ClosedAt = &closedAt
+	return &s, nil
+}
+
+func (r *sqliteRepository) GetRoyaltyStatements(artistID int) ([]RoyaltyStatement, error) {
+	rows, err := r.db.Query(
+		"SELECT "+royaltyStatementColumns+" FROM royalty_statements WHERE ? = 0 OR artist_id = ? ORDER BY period_start DESC, id DESC",
+		artistID, artistID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var statements []RoyaltyStatement
+	for rows.Next() {
+		s, err := scanRoyaltyStatement(rows)
+		if err != nil {
+			return nil, err
+		}
+		statements = append(statements, *s)
+	}
+	return statements, rows.Err()
+}
+
+func (r *sqliteRepository) GetRoyaltyStatement(id int) (*RoyaltyStatement, error) {
+	row := r.db.QueryRow("SELECT "+royaltyStatementColumns+" FROM royalty_statements WHERE id = ?", id)
+	s, err := scanRoyaltyStatement(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("royalty statement with id %d not found", id)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT track_id, title, plays, revenue, rate, owed
+		FROM royalty_statement_lines
+		WHERE statement_id = ?
+		ORDER BY owed DESC, track_id
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	s.Lines = []RoyaltyLine{}
+	for rows.Next() {
+		var line RoyaltyLine
+		if err := rows.Scan(&line.TrackID, &line.Title, &line.Plays, &line.Revenue, &line.Rate, &line.Owed); err != nil {
+			return nil, err
+		}
+		s.Lines = append(s.Lines, line)
+	}
+	return s, rows.Err()
+}
+
+func (r *sqliteRepository) GetVenueTrackPlays(venueID string, from, to time.Time) ([]TrackPlayBucket, error) {
+	rows, err := r.db.Query(`
+		SELECT l.track_id, `+playBucketExpr+` AS start, COUNT(*)
+		FROM playback_logs l
+		JOIN devices d ON d.device_id = l.device_id
+		WHERE d.venue_id = ? AND l.played_at >= ? AND l.played_at < ? AND l.voided_at IS NULL
+		GROUP BY l.track_id, start
+	`, venueID, from.UTC(), to.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var plays []TrackPlayBucket
+	for rows.Next() {
+		var p TrackPlayBucket
+		var start string
+		if err := rows.Scan(&p.TrackID, &start, &p.Plays); err != nil {
+			return nil, err
+		}
+		if p.Start, err = time.Parse(time.DateTime, start); err != nil {
+			return nil, err
+		}
+		plays = append(plays, p)
+	}
+	return plays, rows.Err()
+}
+
+func (r *sqliteRepository) ImportCatalog(upserts []CatalogUpsert, at time.Time) ([]int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, upsert := range upserts {
+		if err := checkCatalogUpsert(tx, upsert); err != nil {
+			return nil, err
+		}
+	}
+
+	ids := make([]int, len(upserts))
+	for i, upsert := range upserts {
+		track := upsert.Track
+		if track.ID == 0 {
+			res, err := tx.Exec(`
+				INSERT INTO tracks (title, artist, price, duration_seconds, isrc, album, release_year)
+				VALUES (?, ?, ?, ?, ?, ?, ?)
+			`, track.Title, track.Artist, track.Price, track.DurationSeconds, track.ISRC, track.Album, track.ReleaseYear)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %w", upsert.Row, err)
+			}
+			id, err := res.LastInsertId()
+			if err != nil {
+				return nil, err
+			}
+			track.ID = int(id)
+		} else {
+			var oldPrice float64
+			if err := tx.QueryRow("SELECT price FROM tracks WHERE id = ?", track.ID).Scan(&oldPrice); err != nil {
+				return nil, fmt.Errorf("row %d: %w", upsert.Row, err)
+			}
+			if _, err := tx.Exec(`
+				UPDATE tracks SET title = ?, artist = ?, price = ?, duration_seconds = ?, isrc = ?, album = ?, release_year = ?
+				WHERE id = ?
+			`, track.Title, track.Artist, track.Price, track.DurationSeconds, track.ISRC, track.Album, track.ReleaseYear, track.ID); err != nil {
+				return nil, fmt.Errorf("row %d: %w", upsert.Row, err)
+			}
+			if oldPrice != track.Price {
+				if _, err := tx.Exec(`
+					INSERT INTO price_changes (track_id, source, old_price, new_price, reason, changed_at)
+					VALUES (?, ?, ?, ?, ?, ?)
+				`, track.ID, PriceSourceImport, oldPrice, track.Price, "catalog import", at.UTC()); err != nil {
+					return nil, err
+				}
+				event := PriceUpdatedEvent{TrackID: track.ID, OldPrice: oldPrice, NewPrice: track.Price}
+				if err := insertOutboxEvent(tx, EventPriceUpdated, event); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		if err := replaceTrackTags(tx, track.ID, track.Tags); err != nil {
+			return nil, err
+		}
+		for _, id := range upsert.ExternalIDs {
+			if _, err := tx.Exec(`
+				INSERT INTO track_external_ids (track_id, source, external_id) VALUES (?, ?, ?)
+				ON CONFLICT (track_id, source) DO UPDATE SET external_id = excluded.external_id
+			`, track.ID, id.Source, id.ExternalID); err != nil {
+				return nil, fmt.Errorf("row %d: %w", upsert.Row, err)
+			}
+		}
+		ids[i] = track.ID
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// checkCatalogUpsert returns CatalogChanged when the catalog no longer
+// matches the one the upsert was planned against.
+func checkCatalogUpsert(tx *sql.Tx, upsert CatalogUpsert) error {
+	track := upsert.Track
+	if track.ID != 0 {
+		current, err := scanTrack(tx.QueryRow("SELECT "+trackColumns+" FROM tracks WHERE id = ?", track.ID))
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+		if err == sql.ErrNoRows || len(diffTrack(upsert.Previous, *current)) > 0 {
+			return fmt.Errorf("%w: row %d: track %d was changed", CatalogChanged, upsert.Row, track.ID)
+		}
+	}
+
+	var owner int
+	if track.ISRC != "" {
+		err := tx.QueryRow("SELECT id FROM tracks WHERE isrc = ? AND id != ?", track.ISRC, track.ID).Scan(&owner)
+		if err != sql.ErrNoRows {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: row %d: isrc %s was given to track %d", CatalogChanged, upsert.Row, track.ISRC, owner)
+		}
+	}
+	for _, id := range upsert.ExternalIDs {
+		err := tx.QueryRow("SELECT track_id FROM track_external_ids WHERE source = ? AND external_id = ? AND track_id != ?",
+			id.Source, id.ExternalID, track.ID).Scan(&owner)
+		if err != sql.ErrNoRows {
+			if err != nil {
+				return err
+			}
+			return fmt.Errorf("%w: row %d: %s id %s was given to track %d", CatalogChanged, upsert.Row, id.Source, id.ExternalID, owner)
+		}
+	}
+
+	if track.ID != 0 {
+		return nil
+	}
+	rows, err := tx.Query("SELECT id, title, artist FROM tracks WHERE id > ?", upsert.LastTrackID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var id int
+		var title, artist string
+		if err := rows.Scan(&id, &title, &artist); err != nil {
+			return err
+		}
+		if catalogKey(title, artist) == catalogKey(track.Title, track.Artist) {
+			return fmt.Errorf("%w: row %d: track %d was added", CatalogChanged, upsert.Row, id)
+		}
+	}
+	return rows.Err()
+}
+
+// taggedTracksQuery selects the ids of the tracks with the tag named by its
+// parameter.
+const taggedTracksQuery = `SELECT tt.track_id FROM track_tags tt JOIN tags g ON g.id = tt.tag_id WHERE g.name = ?`
+
+func (r *sqliteRepository) GetTags() ([]Tag, error) {
+	rows, err := r.db.Query(`
+		SELECT g.id, g.name, COUNT(tt.track_id)
+		FROM tags g
+		LEFT JOIN track_tags tt ON tt.tag_id = g.id
+		GROUP BY g.id
+		ORDER BY g.name
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var tag Tag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.Tracks); err != nil {
+			return nil, err
+		}
+		tags = append(tags, tag)
+	}
+	return tags, rows.Err()
+}
+
+func (r *sqliteRepository) GetTagByName(name string) (*Tag, error) {
+	var tag Tag
+	err := r.db.QueryRow(`
+		SELECT g.id, g.name, (SELECT COUNT(*) FROM track_tags WHERE tag_id = g.id)
+		FROM tags g
+		WHERE g.name = ?
+	`, name).Scan(&tag.ID, &tag.Name, &tag.Tracks)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *sqliteRepository) CreateTag(name string) (*Tag, error) {
+	res, err := r.db.Exec("INSERT INTO tags (name) VALUES (?)", name)
+	if err != nil {
+		return nil, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, err
+	}
+	return &Tag{ID: int(id), Name: name}, nil
+}
+
+func (r *sqliteRepository) RenameTag(id int, name string) error {
+	res, err := r.db.Exec("UPDATE tags SET name = ? WHERE id = ?", name, id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag with id %d not found", id)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) DeleteTag(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("DELETE FROM track_tags WHERE tag_id = ?", id); err != nil {
+		return err
+	}
+	res, err := tx.Exec("DELETE FROM tags WHERE id = ?", id)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("tag with id %d not found", id)
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) TagTrack(trackID int, name string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec("INSERT OR IGNORE INTO tags (name) VALUES (?)", name); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(
+		"INSERT OR IGNORE INTO track_tags (track_id, tag_id) SELECT ?, id FROM tags WHERE name = ?",
+		trackID, name,
+	); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func (r *sqliteRepository) UntagTrack(trackID int, name string) error {
+	res, err := r.db.Exec(
+		"DELETE FROM track_tags WHERE track_id = ? AND tag_id = (SELECT id FROM tags WHERE name = ?)",
+		trackID, name,
+	)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("track %d has no tag %q", trackID, name)
+	}
+	return nil
+}
+
+func (r *sqliteRepository) GetTagPlayBuckets(filter HeatmapFilter) ([]TagPlayBucket, error) {
+	from, to := filter.From.UTC(), filter.To.UTC()
+	// Rollups carry no device, so they are left out when filtering by venue.
+	rows, err := r.db.Query(`
+		SELECT tag_id, name, start, SUM(plays), SUM(revenue) FROM (
+			SELECT g.id AS tag_id, g.name AS name, `+playBucketExpr+` AS start,
+				SUM(l.voided_at IS NULL AND l.quarantined = 0) AS plays,
+				SUM((l.amount_paid - l.refunded_amount) * (l.quarantined = 0)) AS revenue
+			FROM playback_logs l
+			JOIN tracks t ON t.id = l.track_id
+			JOIN track_tags tt ON tt.track_id = l.track_id
+			JOIN tags g ON g.id = tt.tag_id
+			LEFT JOIN devices d ON d.device_id = l.device_id
+			WHERE l.played_at >= ? AND l.played_at < ?
+				AND (? = '' OR d.venue_id = ?)
+				AND (? = 0 OR l.track_id = ?)
+				AND (? = 0 OR t.artist_id = ?)
+				AND (? = '' OR g.name = ?)
+			GROUP BY g.id, start
+			UNION ALL
+			SELECT g.id, g.name, strftime('%Y-%m-%d %H:00:00', p.period_start), SUM(p.play_count), SUM(p.revenue)
+			FROM playback_rollups p
+			JOIN tracks t ON t.id = p.track_id
+			JOIN track_tags tt ON tt.track_id = p.track_id
+			JOIN tags g ON g.id = tt.tag_id
+			WHERE ? = '' AND p.period_start >= ? AND p.period_start < ?
+				AND (? = 0 OR p.track_id = ?)
+				AND (? = 0 OR t.artist_id = ?)
+				AND (? = '' OR g.name = ?)
+			GROUP BY g.id, p.period_start
+		)
+		GROUP BY tag_id, start
+	`, from, to, filter.VenueID, filter.VenueID, filter.TrackID, filter.TrackID, filter.ArtistID, filter.ArtistID, filter.Tag, filter.Tag,
+		filter.VenueID, from, to, filter.TrackID, filter.TrackID, filter.ArtistID, filter.ArtistID, filter.Tag, filter.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var buckets []TagPlayBucket
+	for rows.Next() {
+		var b TagPlayBucket
+		var start string
+		if err := rows.Scan(&b.TagID, &b.Tag, &start, &b.Plays, &b.Revenue); err != nil {
+			return nil, err
+		}
+		if b.Start, err = time.Parse(time.DateTime, start); err != nil {
+			return nil, err
+		}
+		buckets = append(buckets, b)
+	}
+	return buckets, rows.Err()
+}
@@ -0,0 +1,13 @@
+//go:build sqlite_fts5
+
+package main
+
+import "testing"
+
+func TestSQLiteFTS5SearchDiacritics(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	if !repo.fts {
+		t.Fatal("Expected SQLite built with sqlite_fts5 to have FTS5")
+	}
+	testSearchDiacritics(t, repo)
+}
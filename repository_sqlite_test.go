@@ -0,0 +1,330 @@
+package main
+
+import (
+	"database/sql"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestSQLiteRepository opens a migrated database in a temporary
+// directory, seeded with the three tracks of the initial migration.
+func newTestSQLiteRepository(t *testing.T) *sqliteRepository {
+	t.Helper()
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "analytics.db"))
+	if err != nil {
+		t.Fatalf("NewSQLiteRepository failed: %v", err)
+	}
+	r := repo.(*sqliteRepository)
+	t.Cleanup(func() { r.db.Close() })
+	return r
+}
+
+func TestSQLiteConnectionOptions(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	var mode string
+	var timeout int
+	if err := repo.db.QueryRow("PRAGMA journal_mode").Scan(&mode); err != nil || mode != "wal" {
+		t.Errorf("Expected WAL journal mode, got %q, %v", mode, err)
+	}
+	if err := repo.db.QueryRow("PRAGMA busy_timeout").Scan(&timeout); err != nil || timeout != 5000 {
+		t.Errorf("Expected a 5000ms busy timeout, got %d, %v", timeout, err)
+	}
+}
+
+func TestSQLiteSearchTracks(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+
+	tracks, total, err := repo.SearchTracks("numb PINK", 10, 0)
+	if err != nil {
+		t.Fatalf("SearchTracks failed: %v", err)
+	}
+	if total != 1 || len(tracks) != 1 || tracks[0].ID != 2 {
+		t.Errorf("Expected Comfortably Numb, got %d %+v", total, tracks)
+	}
+
+	if tracks, total, _ := repo.SearchTracks("zeppelin", 10, 0); total != 0 || len(tracks) != 0 {
+		t.Errorf("Expected no matches, got %d %+v", total, tracks)
+	}
+
+	tracks, total, _ = repo.SearchTracks("", 1, 1)
+	if total != 3 || len(tracks) != 1 || tracks[0].ID != 2 {
+		t.Errorf("Expected the second of three tracks, got %d %+v", total, tracks)
+	}
+
+	// Renames are searchable whichever way search is backed.
+	if _, err := repo.db.Exec("UPDATE tracks SET title = 'Heroes' WHERE id = 3"); err != nil {
+		t.Fatal(err)
+	}
+	if tracks, _, _ := repo.SearchTracks("hero", 10, 0); len(tracks) != 1 || tracks[0].ID != 3 {
+		t.Errorf("Expected the renamed track, got %+v", tracks)
+	}
+}
+
+func TestSQLiteOpensIndexedDatabaseWithoutFTS5(t *testing.T) {
+	if newTestSQLiteRepository(t).fts {
+		t.Skip("SQLite was built with FTS5")
+	}
+
+	path := filepath.Join(t.TempDir(), "indexed.db")
+	repo, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	repo.(*sqliteRepository).db.Close()
+
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Stands in for the index an FTS5 build would have created; its trigger
+	// fails every insert into tracks as the real one would without FTS5.
+	for _, stmt := range []string{
+		"CREATE TABLE tracks_fts (title TEXT)",
+		"CREATE TRIGGER tracks_fts_insert AFTER INSERT ON tracks BEGIN INSERT INTO tracks_fts(rowid, title, artist) VALUES (new.id, new.title, new.artist); END",
+		"INSERT INTO schema_migrations (version, applied_at) VALUES ('" + ftsMigration + "', CURRENT_TIMESTAMP)",
+	} {
+		if _, err := db.Exec(stmt); err != nil {
+			t.Fatal(err)
+		}
+	}
+	db.Close()
+
+	reopened, err := NewSQLiteRepository(path)
+	if err != nil {
+		t.Fatalf("Expected an FTS5-indexed database to open without FTS5, got %v", err)
+	}
+	r := reopened.(*sqliteRepository)
+	t.Cleanup(func() { r.db.Close() })
+	testSearchDiacritics(t, r)
+
+	var applied int
+	r.db.QueryRow("SELECT COUNT(*) FROM schema_migrations WHERE version = ?", ftsMigration).Scan(&applied)
+	if applied != 0 {
+		t.Error("Expected the index migration to be left for an FTS5 build to rerun")
+	}
+}
+
+func TestSQLiteSearchDiacritics(t *testing.T) {
+	testSearchDiacritics(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteCustomerPlaysSurviveRetention(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	old := now.Add(-2 * logRetention)
+
+	if _, err := repo.TopUpCredit("card-42", 5, old); err != nil {
+		t.Fatalf("TopUpCredit failed: %v", err)
+	}
+	for i := 0; i < 2; i++ {
+		log := PlaybackLog{TrackID: 1, PlayedAt: old, AmountPaid: 1.25, ListPrice: 1.25, CustomerID: "card-42", PaidWithCredit: true}
+		debit := WalletTransaction{CustomerID: "card-42", Kind: WalletDebit, Amount: -1.25, CreatedAt: old}
+		if _, err := repo.CreateCustomerLog(log, []WalletTransaction{debit}); err != nil {
+			t.Fatalf("CreateCustomerLog failed: %v", err)
+		}
+	}
+
+	archiver, err := NewFileArchiver(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if purged, err := NewRetentionJob(repo, archiver, logRetention).RunOnce(now); err != nil || purged != 2 {
+		t.Fatalf("Expected 2 purged logs, got %d, %v", purged, err)
+	}
+
+	customer, err := repo.GetCustomer("card-42")
+	if err != nil {
+		t.Fatalf("GetCustomer failed: %v", err)
+	}
+	if customer.Plays != 2 || customer.Balance != 2.5 {
+		t.Errorf("Expected 2 plays and a balance of 2.50 after retention, got %+v", customer)
+	}
+}
+
+func TestSQLiteVoidCreditPlay(t *testing.T) {
+	testVoidCreditPlay(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHeatmapHalfHourZone(t *testing.T) {
+	testHeatmapHalfHourZone(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteTagStatsHalfHourZone(t *testing.T) {
+	testTagStatsHalfHourZone(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteTrackStatsHalfHourZone(t *testing.T) {
+	testTrackStatsHalfHourZone(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteUsageReportHalfHourZone(t *testing.T) {
+	testUsageReportHalfHourZone(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteChartsAfterMerge(t *testing.T) {
+	testChartsAfterMerge(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteRetentionJob(t *testing.T) {
+	testRetentionJob(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteRollupAtRangeBoundary(t *testing.T) {
+	testRollupAtRangeBoundary(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteQuarantinedRevenue(t *testing.T) {
+	testQuarantinedRevenue(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHandleGetRetentionReport(t *testing.T) {
+	testHandleGetRetentionReport(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteWebhookDispatcher(t *testing.T) {
+	testWebhookDispatcher(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteWebhookHistoryRetention(t *testing.T) {
+	testWebhookHistoryRetention(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHandleGetTrendingTracks(t *testing.T) {
+	testHandleGetTrendingTracks(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHandleGetRelatedTracks(t *testing.T) {
+	testHandleGetRelatedTracks(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLitePricingRules(t *testing.T) {
+	testPricingRules(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteScheduledPrices(t *testing.T) {
+	testScheduledPrices(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteEffectivePrice(t *testing.T) {
+	testEffectivePrice(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteVenueTimePricing(t *testing.T) {
+	testVenueTimePricing(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteReconciliationReport(t *testing.T) {
+	testReconciliationReport(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteReconciliationVenueDays(t *testing.T) {
+	testReconciliationVenueDays(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHandleLogPlaybackBelowPrice(t *testing.T) {
+	testHandleLogPlaybackBelowPrice(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHandleVoidLog(t *testing.T) {
+	testHandleVoidLog(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLitePlayQueue(t *testing.T) {
+	testPlayQueue(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteSkipStats(t *testing.T) {
+	testSkipStats(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteAnomalyDetector(t *testing.T) {
+	testAnomalyDetector(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteDeviceMonitor(t *testing.T) {
+	testDeviceMonitor(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteArtistLeaderboards(t *testing.T) {
+	testArtistLeaderboards(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteHeatmap(t *testing.T) {
+	testHeatmap(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteChartSnapshots(t *testing.T) {
+	testChartSnapshots(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteChartSnapshotAcrossPurgedBoundary(t *testing.T) {
+	testChartSnapshotAcrossPurgedBoundary(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteCustomerWallet(t *testing.T) {
+	testCustomerWallet(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteRoyaltyStatements(t *testing.T) {
+	testRoyaltyStatements(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteConcurrentStatementsDoNotOverlap(t *testing.T) {
+	repo := newTestSQLiteRepository(t)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	closedAt := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := from.AddDate(0, 0, i)
+			repo.SaveRoyaltyStatement(RoyaltyStatement{ArtistID: 1, ArtistName: "Michael Jackson",
+				PeriodStart: start, PeriodEnd: start.AddDate(0, 1, 0), ClosedAt: &closedAt})
+		}()
+	}
+	wg.Wait()
+
+	if statements, err := repo.GetRoyaltyStatements(1); err != nil || len(statements) != 1 {
+		t.Errorf("Expected exactly one of the overlapping statements to be saved, got %+v, %v", statements, err)
+	}
+}
+
+func TestSQLiteRoyaltyStatementFromRollup(t *testing.T) {
+	testRoyaltyStatementFromRollup(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteUsageReport(t *testing.T) {
+	testUsageReport(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteUsageReportPurgedFirstHour(t *testing.T) {
+	testUsageReportPurgedFirstHour(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteTrackExternalIDs(t *testing.T) {
+	testTrackExternalIDs(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteImportCatalog(t *testing.T) {
+	testImportCatalog(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteImportCatalogRace(t *testing.T) {
+	testImportCatalogRace(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteDuplicateTracksMerge(t *testing.T) {
+	testDuplicateTracksMerge(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteTagAnalytics(t *testing.T) {
+	testTagAnalytics(t, newTestSQLiteRepository(t))
+}
+
+func TestSQLiteTrackStats(t *testing.T) {
+	testTrackStats(t, newTestSQLiteRepository(t))
+}
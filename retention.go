@@ -0,0 +1,253 @@
+package main
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	retentionBatchSize = 1000
+
+	// archiveVersion names the column layout of archive files. Bump it with
+	// every change to archiveColumns so rows never land under the header of
+	// another layout; files of older layouts are left as they are.
+	archiveVersion = 2
+)
+
+var archiveColumns = []string{
+	"id", "track_id", "played_at", "amount_paid", "device_id", "session_id",
+	"list_price", "payment_status", "voided_at", "void_reason", "refunded_amount",
+	"completed_at", "listened_seconds", "skipped", "quarantined", "customer_id", "paid_with_credit",
+}
+
+// LogArchiver stores playback logs before they are removed from the database.
+// Archive writes a batch and Commit keeps it once the batch is purged; a batch
+// archived again without a Commit replaces the previous attempt, so a failed
+// purge never leaves the same logs in the archive twice.
+type LogArchiver interface {
+	Archive(logs []PlaybackLog) error
+	Commit() error
+}
+
+// fileArchiver appends logs to gzip-compressed CSV files, one file per month
+// and layout version. Each call appends a new gzip member, which gzip readers
+// treat as one stream. The committed length of each file is kept next to it
+// in a ".committed" file; anything past it is cut off by the next Archive.
+type fileArchiver struct {
+	dir string
+	// pending holds the files written since the last Commit.
+	pending map[string]bool
+}
+
+func NewFileArchiver(dir string) (LogArchiver, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &fileArchiver{dir: dir, pending: make(map[string]bool)}, nil
+}
+
+func (a *fileArchiver) Archive(logs []PlaybackLog) error {
+	// Files still pending belong to an attempt whose purge never committed.
+	for path := range a.pending {
+		size, err := committedSize(path)
+		if err != nil {
+			return err
+		}
+		if err := os.Truncate(path, size); err != nil {
+			return err
+		}
+		delete(a.pending, path)
+	}
+
+	byMonth := make(map[string][]PlaybackLog)
+	for _, log := range logs {
+		month := log.PlayedAt.UTC().Format("2006-01")
+		byMonth[month] = append(byMonth[month], log)
+	}
+
+	for month, monthLogs := range byMonth {
+		if err := a.appendMonth(month, monthLogs); err != nil {
+			return fmt.Errorf("failed to archive %s: %w", month, err)
+		}
+	}
+	return nil
+}
+
+// Commit records the current length of every file written since the last
+// Commit as its committed length.
+func (a *fileArchiver) Commit() error {
+	for path := range a.pending {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if err := setCommittedSize(path, info.Size()); err != nil {
+			return err
+		}
+		delete(a.pending, path)
+	}
+	return nil
+}
+
+func committedSize(path string) (int64, error) {
+	data, err := os.ReadFile(path + ".committed")
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(string(data), 10, 64)
+}
+
+func setCommittedSize(path string, size int64) error {
+	tmp := path + ".committed.tmp"
+	if err := os.WriteFile(tmp, []byte(strconv.FormatInt(size, 10)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path+".committed")
+}
+
+func (a *fileArchiver) appendMonth(month string, logs []PlaybackLog) error {
+	path := filepath.Join(a.dir, fmt.Sprintf("playback_logs_%s.v%d.csv.gz", month, archiveVersion))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	size, err := committedSize(path)
+	if os.IsNotExist(err) {
+		// New files, and files that predate commits, are committed as they are.
+		var info os.FileInfo
+		if info, err = f.Stat(); err != nil {
+			return err
+		}
+		size = info.Size()
+		err = setCommittedSize(path, size)
+	}
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(size); err != nil {
+		return err
+	}
+	if _, err := f.Seek(size, io.SeekStart); err != nil {
+		return err
+	}
+	a.pending[path] = true
+
+	zw := gzip.NewWriter(f)
+	w := csv.NewWriter(zw)
+	if size == 0 {
+		w.Write(archiveColumns)
+	}
+	for _, log := range logs {
+		var voidedAt string
+		if log.VoidedAt != nil {
+			voidedAt = log.VoidedAt.UTC().Format(time.RFC3339Nano)
+		}
+		var completedAt string
+		if log.CompletedAt != nil {
+			completedAt = log.CompletedAt.UTC().Format(time.RFC3339Nano)
+		}
+		w.Write([]string{
+			strconv.Itoa(log.ID),
+			strconv.Itoa(log.TrackID),
+			log.PlayedAt.UTC().Format(time.RFC3339Nano),
+			strconv.FormatFloat(log.AmountPaid, 'f', -1, 64),
+			log.DeviceID,
+			log.SessionID,
+			strconv.FormatFloat(log.ListPrice, 'f', -1, 64),
+			log.PaymentStatus,
+			voidedAt,
+			log.VoidReason,
+			strconv.FormatFloat(log.RefundedAmount, 'f', -1, 64),
+			completedAt,
+			strconv.Itoa(log.ListenedSeconds),
+			strconv.FormatBool(log.Skipped),
+			strconv.FormatBool(log.Quarantined),
+			log.CustomerID,
+			strconv.FormatBool(log.PaidWithCredit),
+		})
+	}
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return err
+	}
+	if err := zw.Close(); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// RetentionJob archives playback logs older than the retention period and
+// replaces them with hourly rollups so aggregate stats stay intact.
+type RetentionJob struct {
+	repo      IRepository
+	archiver  LogArchiver
+	retention time.Duration
+}
+
+func NewRetentionJob(repo IRepository, archiver LogArchiver, retention time.Duration) *RetentionJob {
+	return &RetentionJob{repo: repo, archiver: archiver, retention: retention}
+}
+
+func (j *RetentionJob) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := j.RunOnce(time.Now())
+		if err != nil {
+			slog.Error("retention job failed", "error", err)
+		} else if purged > 0 {
+			slog.Info("retention job archived logs", "count", purged)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce archives and purges every log older than the retention period,
+// one batch at a time, and returns the number of purged logs. Webhook events
+// past the retention period are dropped along with their deliveries.
+func (j *RetentionJob) RunOnce(now time.Time) (int, error) {
+	cutoff := now.Add(-j.retention)
+	if _, err := j.repo.PurgeWebhookHistory(cutoff); err != nil {
+		return 0, fmt.Errorf("failed to purge webhook history: %w", err)
+	}
+
+	total := 0
+	for {
+		logs, err := j.repo.GetExpiredLogs(cutoff, retentionBatchSize)
+		if err != nil {
+			return total, err
+		}
+		if len(logs) == 0 {
+			return total, nil
+		}
+
+		if err := j.archiver.Archive(logs); err != nil {
+			return total, err
+		}
+
+		purged, err := j.repo.PurgeExpiredLogs(cutoff, logs[len(logs)-1].ID)
+		if err != nil {
+			return total, err
+		}
+		if err := j.archiver.Commit(); err != nil {
+			return total, fmt.Errorf("failed to commit archive: %w", err)
+		}
+		total += purged
+	}
+}
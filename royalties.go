@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/csv"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// royaltyRate resolves the rate for a track: a track rate overrides its
+// artist's rate, and tracks with neither owe nothing.
+func royaltyRate(rates []RoyaltyRate, trackID, artistID int) float64 {
+	rate := 0.0
+	for _, r := range rates {
+		if r.TrackID == trackID {
+			return r.Rate
+		}
+		if r.TrackID == 0 && r.ArtistID == artistID {
+			rate = r.Rate
+		}
+	}
+	return rate
+}
+
+// roundCents rounds an amount of money to whole cents.
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// buildStatement computes what is owed on each of the artist's tracks, largest
+// amount first. Each line is rounded to cents and the totals are the sum of
+// the lines.
+func buildStatement(artist Artist, tracks []TrackStat, rates []RoyaltyRate, from, to time.Time) *RoyaltyStatement {
+	statement := &RoyaltyStatement{
+		ArtistID:    artist.ID,
+		ArtistName:  artist.Name,
+		PeriodStart: from,
+		PeriodEnd:   to,
+		Lines:       []RoyaltyLine{},
+	}
+	for _, track := range tracks {
+		if track.Plays == 0 && track.Revenue == 0 {
+			continue
+		}
+		line := RoyaltyLine{
+			TrackID: track.TrackID,
+			Title:   track.Title,
+			Plays:   track.Plays,
+			Revenue: roundCents(track.Revenue),
+			Rate:    royaltyRate(rates, track.TrackID, artist.ID),
+		}
+		line.Owed = roundCents(track.Revenue * line.Rate)
+		statement.Lines = append(statement.Lines, line)
+		statement.Revenue += line.Revenue
+		statement.Owed += line.Owed
+	}
+	sort.SliceStable(statement.Lines, func(i, j int) bool {
+		a, b := statement.Lines[i], statement.Lines[j]
+		if a.Owed != b.Owed {
+			return a.Owed > b.Owed
+		}
+		return a.TrackID < b.TrackID
+	})
+	statement.Revenue = roundCents(statement.Revenue)
+	statement.Owed = roundCents(statement.Owed)
+	return statement
+}
+
+// writeStatementCSV writes one row per statement line.
+func writeStatementCSV(w io.Writer, statement *RoyaltyStatement) error {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{
+		"statement_id", "artist_id", "artist", "period_start", "period_end",
+		"track_id", "title", "plays", "revenue", "rate", "owed",
+	})
+	for _, line := range statement.Lines {
+		cw.Write([]string{
+			strconv.Itoa(statement.ID),
+			strconv.Itoa(statement.ArtistID),
+			statement.ArtistName,
+			statement.PeriodStart.UTC().Format(time.RFC3339),
+			statement.PeriodEnd.UTC().Format(time.RFC3339),
+			strconv.Itoa(line.TrackID),
+			line.Title,
+			strconv.Itoa(line.Plays),
+			strconv.FormatFloat(line.Revenue, 'f', 2, 64),
+			strconv.FormatFloat(line.Rate, 'f', -1, 64),
+			strconv.FormatFloat(line.Owed, 'f', 2, 64),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
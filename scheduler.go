@@ -0,0 +1,44 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+const (
+	ScheduleStatusPending   = "pending"
+	ScheduleStatusActive    = "active"
+	ScheduleStatusCompleted = "completed"
+	ScheduleStatusCancelled = "cancelled"
+)
+
+// PriceScheduler starts and ends scheduled price changes once their
+// effective times pass.
+type PriceScheduler struct {
+	service *Service
+}
+
+func NewPriceScheduler(service *Service) *PriceScheduler {
+	return &PriceScheduler{service: service}
+}
+
+func (p *PriceScheduler) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		applied, err := p.service.ApplyScheduledPrices(time.Now())
+		if err != nil {
+			slog.Error("price scheduler failed", "error", err)
+		} else if applied > 0 {
+			slog.Info("price scheduler applied scheduled prices", "count", applied)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
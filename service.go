@@ -1,17 +1,112 @@
 package main
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"maps"
+	"math"
+	"net/url"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
-const topTracks = 3
+const (
+	topTracks         = 3
+	playBucket        = 15 * time.Minute
+	logRetention      = 90 * 24 * time.Hour
+	webhookDeliveries = 50
+	priceChanges      = 100
+	discrepancies     = 1000
+
+	relatedTracks    = 10
+	trendingTracks   = 10
+	trendingWindow   = 24 * time.Hour
+	trendingBaseline = 7 * 24 * time.Hour
+
+	skipStatsWindow = 30 * 24 * time.Hour
+
+	defaultSearchLimit = 20
+	maxSearchLimit     = 100
+
+	topArtists = 10
+
+	chartPeriod = 7 * 24 * time.Hour
+	chartSize   = 20
+
+	duplicateScore = 0.85
+)
 
 var TrackNotFoundError = errors.New("track not found")
 var FailedToCreateLog = errors.New("failed to create log")
 var PriceMustBeGreater = errors.New("price must be greater than 0")
 var FailedToGetStats = errors.New("failed to get stats")
+var WebhookNotFoundError = errors.New("webhook not found")
+var InvalidWebhookURL = errors.New("webhook url must be an absolute http(s) url")
+var UnknownEventType = errors.New("unknown event type")
+var PricingRuleNotFoundError = errors.New("pricing rule not found")
+var InvalidPricingRule = errors.New("invalid pricing rule")
+var InvalidPriceBounds = errors.New("invalid price bounds")
+var InvalidSchedule = errors.New("invalid price schedule")
+var ScheduledPriceNotFoundError = errors.New("scheduled price not found")
+var ScheduledPriceNotCancellable = errors.New("scheduled price is already finished")
+var InvalidTimePriceRule = errors.New("invalid time price rule")
+var TimePriceRuleNotFoundError = errors.New("time price rule not found")
+var AmountBelowPrice = errors.New("amount paid is below the current price")
+var NegativeAmount = errors.New("amount paid must not be negative")
+var InvalidPaymentPolicy = errors.New("invalid payment policy")
+var PlaybackNotFoundError = errors.New("playback log not found")
+var PlaybackAlreadyVoided = errors.New("playback log is already voided")
+var FailedToVoidLog = errors.New("failed to void playback log")
+var InvalidRefund = errors.New("refunded amount must be between 0 and the amount paid")
+var QueueItemNotFoundError = errors.New("queue item not found")
+var InvalidQueueTransition = errors.New("invalid queue transition")
+var MissingDeviceID = errors.New("device id is required")
+var InvalidDuration = errors.New("duration must not be negative")
+var PlaybackAlreadyCompleted = errors.New("playback log is already completed")
+var AlertNotFoundError = errors.New("alert not found")
+var AlertAlreadyResolved = errors.New("alert is already resolved")
+var DeviceNotFoundError = errors.New("device not found")
+var VenueNotFoundError = errors.New("venue not found")
+var InvalidVenue = errors.New("invalid venue")
+var InvalidPagination = errors.New("limit must be between 1 and 100 and offset must not be negative")
+var ArtistNotFoundError = errors.New("artist not found")
+var InvalidSortOrder = errors.New("sort must be \"plays\" or \"revenue\"")
+var InvalidTimeZone = errors.New("unknown time zone")
+var ChartNotFoundError = errors.New("chart not found")
+var CustomerNotFoundError = errors.New("customer not found")
+var MissingCustomerID = errors.New("customer id is required to pay with credit")
+var InsufficientCredit = errors.New("insufficient credit")
+var InvalidTopUp = errors.New("top-up amount must be positive")
+var InvalidLoyaltyRule = errors.New("free_every must not be negative")
+var InvalidRoyaltyRate = errors.New("invalid royalty rate")
+var RoyaltyRateNotFoundError = errors.New("royalty rate not found")
+var InvalidStatementPeriod = errors.New("statement period must have ended and period_start must be before period_end")
+var StatementOverlap = errors.New("a closed statement already covers part of this period")
+var StatementNotFoundError = errors.New("royalty statement not found")
+var MissingVenueID = errors.New("venue id is required")
+var InvalidUsagePeriod = errors.New("from and to must be dates (YYYY-MM-DD) with from not after to")
+var UsagePeriodPurged = errors.New("plays in the period are past the retention period and only in the log archive")
+var InvalidUsageFormat = errors.New("invalid usage report format")
+var InvalidISRC = errors.New("isrc must be a 12 character code such as USRC17607839")
+var InvalidReleaseYear = errors.New("release year must not be negative or in the future")
+var InvalidTag = errors.New("tags must be non-empty, at most 50 characters and must not contain commas")
+var InvalidExternalID = errors.New("source and external id are required")
+var ExternalIDInUse = errors.New("external id is already assigned to another track")
+var ExternalIDNotFoundError = errors.New("external id not found")
+var MissingTitleOrArtist = errors.New("title and artist are required")
+var InvalidCatalogFile = errors.New("invalid catalog file")
+var InvalidCatalog = errors.New("catalog has invalid rows")
+var CatalogChanged = errors.New("catalog changed during the import")
+var InvalidDuplicateScore = errors.New("min_score must be greater than 0 and at most 1")
+var InvalidMerge = errors.New("a track can only be merged into a different track that has not been merged")
+var TagNotFoundError = errors.New("tag not found")
+var TagExists = errors.New("a tag with this name already exists")
+var InvalidWeekdayOrHour = errors.New("weekday must be between 0 (Sunday) and 6 and hour between 0 and 23")
 
 type Service struct {
 	repo IRepository
@@ -21,34 +116,83 @@ func NewService(repo IRepository) *Service {
 	return &Service{repo: repo}
 }
 
-func (s *Service) CreateLog(trackID int, amountPaid float64) error {
-	_, err := s.repo.GetTrackByID(trackID)
+func (s *Service) CreateLog(log PlaybackLog) error {
+	log, err := s.preparePlayback(log, time.Now())
 	if err != nil {
-		return TrackNotFoundError
+		return err
 	}
 
-	log := PlaybackLog{
-		TrackID:    trackID,
-		AmountPaid: amountPaid,
-		PlayedAt:   time.Now(),
+	if log.CustomerID != "" {
+		wallet, err := s.walletTransactions(log)
+		if err != nil {
+			return err
+		}
+		if _, err := s.repo.CreateCustomerLog(log, wallet); err != nil {
+			return fmt.Errorf("%w: %v", FailedToCreateLog, err)
+		}
+		return nil
 	}
 
-	if err := s.repo.CreateLog(log); err != nil {
+	if _, err := s.repo.CreateLog(log); err != nil {
 		return fmt.Errorf("%w: %v", FailedToCreateLog, err)
 	}
 
 	return nil
 }
 
+// preparePlayback validates the payment against the track's effective price
+// at the given time and fills in the list price and payment status.
+func (s *Service) preparePlayback(log PlaybackLog, at time.Time) (PlaybackLog, error) {
+	if log.AmountPaid < 0 {
+		return log, NegativeAmount
+	}
+
+	track, err := s.repo.GetTrackByID(log.TrackID)
+	if err != nil {
+		return log, TrackNotFoundError
+	}
+
+	// The id may be one of a merged track's.
+	log.TrackID = track.ID
+	log.PlayedAt = at
+
+	rules, err := s.repo.GetTimePriceRules()
+	if err != nil {
+		return log, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+	}
+	policy, err := s.GetPaymentPolicy()
+	if err != nil {
+		return log, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+	}
+
+	log.ListPrice = effectivePrice(*track, rules, log.PlayedAt, s.deviceTimeZone(log.DeviceID)).Price
+	if log.PaidWithCredit {
+		if log.CustomerID == "" {
+			return log, MissingCustomerID
+		}
+		log.AmountPaid = log.ListPrice
+	}
+	log.PaymentStatus = classifyPayment(log.AmountPaid, log.ListPrice)
+	if log.PaymentStatus == PaymentUnderpaid && policy == PaymentPolicyReject {
+		return log, fmt.Errorf("%w: paid %.2f, price %.2f", AmountBelowPrice, log.AmountPaid, log.ListPrice)
+	}
+
+	return log, nil
+}
+
 func (s *Service) UpdatePrice(trackID int, newPrice float64) error {
 	if newPrice <= 0 {
 		return PriceMustBeGreater
 	}
 
-	err := s.repo.UpdateTrackPrice(trackID, newPrice)
+	// The id may be one of a merged track's.
+	track, err := s.repo.GetTrackByID(trackID)
 	if err != nil {
 		return TrackNotFoundError
 	}
+	if err := s.repo.UpdateTrackPrice(track.ID, newPrice); err != nil {
+		return TrackNotFoundError
+	}
 
 	return nil
 }
@@ -61,3 +205,2067 @@ func (s *Service) GetTopTracks() ([]TopTrackStat, error) {
 
 	return top3, nil
 }
+
+// GetTrendingTracks ranks tracks played in the recent window by how much
+// their play rate grew compared to the baseline window right before it.
+func (s *Service) GetTrendingTracks() ([]TrendingTrackStat, error) {
+	now := time.Now()
+	windowStart := now.Add(-trendingWindow)
+
+	recent, err := s.repo.GetTrackPlayCounts(windowStart, now)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+	baseline, err := s.repo.GetTrackPlayCounts(windowStart.Add(-trendingBaseline), windowStart)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+
+	baselineCounts := make(map[int]int)
+	for _, b := range baseline {
+		baselineCounts[b.TrackID] = b.Count
+	}
+
+	scale := float64(trendingWindow) / float64(trendingBaseline)
+	stats := make([]TrendingTrackStat, 0, len(recent))
+	for _, r := range recent {
+		expected := float64(baselineCounts[r.TrackID]) * scale
+		stats = append(stats, TrendingTrackStat{
+			TrackID:       r.TrackID,
+			Title:         r.Title,
+			RecentCount:   r.Count,
+			BaselineCount: baselineCounts[r.TrackID],
+			Score:         (float64(r.Count) + 1) / (expected + 1),
+		})
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Score != stats[j].Score {
+			return stats[i].Score > stats[j].Score
+		}
+		return stats[i].RecentCount > stats[j].RecentCount
+	})
+
+	if len(stats) > trendingTracks {
+		stats = stats[:trendingTracks]
+	}
+	return stats, nil
+}
+
+func (s *Service) GetRetentionReport() (*RetentionReport, error) {
+	cutoff := time.Now().Add(-logRetention)
+	months, err := s.repo.GetExpiredLogsSummary(cutoff)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+
+	report := &RetentionReport{Cutoff: cutoff, Months: months}
+	for _, m := range months {
+		report.TotalPlays += m.Plays
+	}
+	return report, nil
+}
+
+func (s *Service) CreateWebhook(rawURL string, eventTypes []string, secret string) (*WebhookSubscription, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, InvalidWebhookURL
+	}
+	if len(eventTypes) == 0 {
+		return nil, UnknownEventType
+	}
+	for _, eventType := range eventTypes {
+		if !slices.Contains(webhookEventTypes, eventType) {
+			return nil, fmt.Errorf("%w: %s", UnknownEventType, eventType)
+		}
+	}
+
+	if secret == "" {
+		buf := make([]byte, 32)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, err
+		}
+		secret = hex.EncodeToString(buf)
+	}
+
+	return s.repo.CreateWebhook(WebhookSubscription{
+		URL:        rawURL,
+		EventTypes: eventTypes,
+		Secret:     secret,
+		CreatedAt:  time.Now(),
+	})
+}
+
+func (s *Service) GetWebhooks() ([]WebhookSubscription, error) {
+	subs, err := s.repo.GetWebhooks()
+	if err != nil {
+		return nil, err
+	}
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, nil
+}
+
+func (s *Service) DeleteWebhook(id int) error {
+	if err := s.repo.DeleteWebhook(id); err != nil {
+		return WebhookNotFoundError
+	}
+	return nil
+}
+
+func (s *Service) GetWebhookDeliveries(id int) ([]WebhookDelivery, error) {
+	return s.repo.GetWebhookDeliveries(id, webhookDeliveries)
+}
+
+func (s *Service) GetRelatedTracks(trackID int) ([]RelatedTrack, error) {
+	if _, err := s.repo.GetTrackByID(trackID); err != nil {
+		return nil, TrackNotFoundError
+	}
+
+	related, err := s.repo.GetRelatedTracks(trackID, relatedTracks)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+	return related, nil
+}
+
+func (s *Service) CreatePricingRule(rule PricingRule) (*PricingRule, error) {
+	switch {
+	case rule.Target != PricingTargetTop && rule.Target != PricingTargetBottom:
+		return nil, fmt.Errorf("%w: target must be %q or %q", InvalidPricingRule, PricingTargetTop, PricingTargetBottom)
+	case rule.TrackCount <= 0:
+		return nil, fmt.Errorf("%w: track_count must be greater than 0", InvalidPricingRule)
+	case rule.AdjustPercent == 0 || rule.AdjustPercent <= -100:
+		return nil, fmt.Errorf("%w: adjust_percent must be non-zero and greater than -100", InvalidPricingRule)
+	case rule.WindowHours <= 0:
+		return nil, fmt.Errorf("%w: window_hours must be greater than 0", InvalidPricingRule)
+	case rule.CooldownHours < 0:
+		return nil, fmt.Errorf("%w: cooldown_hours must not be negative", InvalidPricingRule)
+	}
+
+	rule.CreatedAt = time.Now()
+	return s.repo.CreatePricingRule(rule)
+}
+
+func (s *Service) GetPricingRules() ([]PricingRule, error) {
+	return s.repo.GetPricingRules()
+}
+
+func (s *Service) DeletePricingRule(id int) error {
+	if err := s.repo.DeletePricingRule(id); err != nil {
+		return PricingRuleNotFoundError
+	}
+	return nil
+}
+
+func (s *Service) SetPriceBounds(bounds PriceBounds) error {
+	if bounds.Floor < 0 || bounds.Ceiling < 0 || (bounds.Ceiling > 0 && bounds.Ceiling < bounds.Floor) {
+		return InvalidPriceBounds
+	}
+	if _, err := s.repo.GetTrackByID(bounds.TrackID); err != nil {
+		return TrackNotFoundError
+	}
+	return s.repo.SetPriceBounds(bounds)
+}
+
+func (s *Service) GetPriceChanges() ([]PriceChange, error) {
+	return s.repo.GetPriceChanges(priceChanges)
+}
+
+// PreviewPricing returns the price changes the pricing rules would make now
+// without applying them.
+func (s *Service) PreviewPricing(now time.Time) ([]PriceChange, error) {
+	rules, err := s.repo.GetPricingRules()
+	if err != nil {
+		return nil, err
+	}
+	tracks, err := s.repo.GetTracks()
+	if err != nil {
+		return nil, err
+	}
+
+	plays := make(map[int]map[int]int)
+	for _, rule := range rules {
+		if _, ok := plays[rule.WindowHours]; ok || !rule.Enabled {
+			continue
+		}
+		counts, err := s.repo.GetTrackPlayCounts(now.Add(-time.Duration(rule.WindowHours)*time.Hour), now)
+		if err != nil {
+			return nil, err
+		}
+		plays[rule.WindowHours] = make(map[int]int)
+		for _, c := range counts {
+			plays[rule.WindowHours][c.TrackID] = c.Count
+		}
+	}
+
+	boundList, err := s.repo.GetPriceBounds()
+	if err != nil {
+		return nil, err
+	}
+	bounds := make(map[int]PriceBounds)
+	for _, b := range boundList {
+		bounds[b.TrackID] = b
+	}
+
+	latest, err := s.repo.GetLatestPriceChanges()
+	if err != nil {
+		return nil, err
+	}
+	lastChanged := make(map[int]time.Time)
+	for _, c := range latest {
+		lastChanged[c.TrackID] = c.ChangedAt
+	}
+
+	return planPriceChanges(now, rules, tracks, plays, bounds, lastChanged), nil
+}
+
+// ApplyPricing applies the changes from PreviewPricing through UpdatePrice
+// and records each applied change in the price change audit.
+func (s *Service) ApplyPricing(now time.Time) ([]PriceChange, error) {
+	planned, err := s.PreviewPricing(now)
+	if err != nil {
+		return nil, err
+	}
+
+	var applied []PriceChange
+	for _, change := range planned {
+		if err := s.applyPriceChange(change); err != nil {
+			return applied, fmt.Errorf("failed to change price of track %d: %w", change.TrackID, err)
+		}
+		applied = append(applied, change)
+	}
+	return applied, nil
+}
+
+func (s *Service) SchedulePrice(trackID int, price float64, startsAt time.Time, endsAt *time.Time) (*ScheduledPrice, error) {
+	if price <= 0 {
+		return nil, PriceMustBeGreater
+	}
+	if startsAt.IsZero() {
+		return nil, fmt.Errorf("%w: starts_at is required", InvalidSchedule)
+	}
+	if endsAt != nil && !endsAt.After(startsAt) {
+		return nil, fmt.Errorf("%w: ends_at must be after starts_at", InvalidSchedule)
+	}
+	if _, err := s.repo.GetTrackByID(trackID); err != nil {
+		return nil, TrackNotFoundError
+	}
+
+	return s.repo.CreateScheduledPrice(ScheduledPrice{
+		TrackID:   trackID,
+		Price:     price,
+		StartsAt:  startsAt,
+		EndsAt:    endsAt,
+		Status:    ScheduleStatusPending,
+		CreatedAt: time.Now(),
+	})
+}
+
+func (s *Service) GetScheduledPrices(status string) ([]ScheduledPrice, error) {
+	return s.repo.GetScheduledPrices(status)
+}
+
+// CancelScheduledPrice cancels a pending schedule, or ends an active one early
+// by restoring the price it replaced.
+func (s *Service) CancelScheduledPrice(id int) error {
+	sp, err := s.repo.GetScheduledPriceByID(id)
+	if err != nil {
+		return ScheduledPriceNotFoundError
+	}
+
+	switch sp.Status {
+	case ScheduleStatusPending:
+	case ScheduleStatusActive:
+		if err := s.applyPriceChange(PriceChange{
+			TrackID:   sp.TrackID,
+			Source:    PriceSourceSchedule,
+			NewPrice:  *sp.PreviousPrice,
+			Reason:    fmt.Sprintf("scheduled price %d cancelled", sp.ID),
+			ChangedAt: time.Now(),
+		}); err != nil {
+			return err
+		}
+	default:
+		return ScheduledPriceNotCancellable
+	}
+
+	sp.Status = ScheduleStatusCancelled
+	return s.repo.UpdateScheduledPrice(*sp)
+}
+
+// ApplyScheduledPrices starts pending schedules whose start time has passed
+// and reverts active schedules whose end time has passed.
+func (s *Service) ApplyScheduledPrices(now time.Time) (int, error) {
+	applied := 0
+
+	active, err := s.repo.GetScheduledPrices(ScheduleStatusActive)
+	if err != nil {
+		return applied, err
+	}
+	for _, sp := range active {
+		if sp.EndsAt.After(now) {
+			continue
+		}
+		if err := s.applyPriceChange(PriceChange{
+			TrackID:   sp.TrackID,
+			Source:    PriceSourceSchedule,
+			NewPrice:  *sp.PreviousPrice,
+			Reason:    fmt.Sprintf("scheduled price %d ended", sp.ID),
+			ChangedAt: now,
+		}); err != nil {
+			return applied, err
+		}
+		sp.Status = ScheduleStatusCompleted
+		if err := s.repo.UpdateScheduledPrice(sp); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+
+	pending, err := s.repo.GetScheduledPrices(ScheduleStatusPending)
+	if err != nil {
+		return applied, err
+	}
+	for _, sp := range pending {
+		if sp.StartsAt.After(now) {
+			continue
+		}
+		if sp.EndsAt != nil && !sp.EndsAt.After(now) {
+			sp.Status = ScheduleStatusCompleted
+			if err := s.repo.UpdateScheduledPrice(sp); err != nil {
+				return applied, err
+			}
+			continue
+		}
+
+		track, err := s.repo.GetTrackByID(sp.TrackID)
+		if err != nil {
+			return applied, TrackNotFoundError
+		}
+		previous := track.Price
+		if err := s.applyPriceChange(PriceChange{
+			TrackID:   sp.TrackID,
+			Source:    PriceSourceSchedule,
+			NewPrice:  sp.Price,
+			Reason:    fmt.Sprintf("scheduled price %d started", sp.ID),
+			ChangedAt: now,
+		}); err != nil {
+			return applied, err
+		}
+
+		sp.Status = ScheduleStatusCompleted
+		if sp.EndsAt != nil {
+			sp.Status = ScheduleStatusActive
+			sp.PreviousPrice = &previous
+		}
+		if err := s.repo.UpdateScheduledPrice(sp); err != nil {
+			return applied, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// applyPriceChange updates a track price through UpdatePrice and records the
+// change, with the price it replaced, in the price change audit.
+func (s *Service) applyPriceChange(change PriceChange) error {
+	track, err := s.repo.GetTrackByID(change.TrackID)
+	if err != nil {
+		return TrackNotFoundError
+	}
+	change.OldPrice = track.Price
+
+	if err := s.UpdatePrice(change.TrackID, change.NewPrice); err != nil {
+		return err
+	}
+	return s.repo.RecordPriceChange(change)
+}
+
+func (s *Service) CreateTimePriceRule(rule TimePriceRule) (*TimePriceRule, error) {
+	if rule.TimeZone == "" {
+		rule.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(rule.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", InvalidTimePriceRule, rule.TimeZone)
+	}
+
+	start, err := parseClock(rule.StartTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", InvalidTimePriceRule, err)
+	}
+	end, err := parseClock(rule.EndTime)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", InvalidTimePriceRule, err)
+	}
+	if start == end {
+		return nil, fmt.Errorf("%w: start_time and end_time must differ", InvalidTimePriceRule)
+	}
+
+	for _, day := range rule.Weekdays {
+		if day < 0 || day > 6 {
+			return nil, fmt.Errorf("%w: weekdays must be between 0 (Sunday) and 6 (Saturday)", InvalidTimePriceRule)
+		}
+	}
+	if rule.Weekdays == nil {
+		rule.Weekdays = []int{}
+	}
+
+	if (rule.Price > 0) == (rule.AdjustPercent != 0) || rule.Price < 0 || rule.AdjustPercent <= -100 {
+		return nil, fmt.Errorf("%w: set either price or adjust_percent (greater than -100)", InvalidTimePriceRule)
+	}
+	if rule.TrackID != 0 && rule.Artist != "" {
+		return nil, fmt.Errorf("%w: set either track_id or artist", InvalidTimePriceRule)
+	}
+	if rule.TrackID != 0 {
+		if _, err := s.repo.GetTrackByID(rule.TrackID); err != nil {
+			return nil, TrackNotFoundError
+		}
+	}
+
+	rule.CreatedAt = time.Now()
+	return s.repo.CreateTimePriceRule(rule)
+}
+
+func (s *Service) GetTimePriceRules() ([]TimePriceRule, error) {
+	return s.repo.GetTimePriceRules()
+}
+
+func (s *Service) DeleteTimePriceRule(id int) error {
+	if err := s.repo.DeleteTimePriceRule(id); err != nil {
+		return TimePriceRuleNotFoundError
+	}
+	return nil
+}
+
+// deviceLocations maps every device placed at a venue to the venue's time
+// zone.
+func (s *Service) deviceLocations() (map[string]*time.Location, error) {
+	devices, err := s.repo.GetDevices()
+	if err != nil {
+		return nil, err
+	}
+	venues, err := s.repo.GetVenues()
+	if err != nil {
+		return nil, err
+	}
+	byVenue := make(map[string]*time.Location)
+	for _, venue := range venues {
+		if loc, err := time.LoadLocation(venue.TimeZone); err == nil {
+			byVenue[venue.ID] = loc
+		}
+	}
+	locations := make(map[string]*time.Location)
+	for _, device := range devices {
+		if loc, ok := byVenue[device.VenueID]; ok {
+			locations[device.DeviceID] = loc
+		}
+	}
+	return locations, nil
+}
+
+// deviceTimeZone returns the time zone of the venue the device is placed at,
+// or "" for devices that are unknown or have no venue.
+func (s *Service) deviceTimeZone(deviceID string) string {
+	if deviceID == "" {
+		return ""
+	}
+	device, err := s.repo.GetDeviceByID(deviceID)
+	if err != nil || device.VenueID == "" {
+		return ""
+	}
+	venue, err := s.repo.GetVenueByID(device.VenueID)
+	if err != nil {
+		return ""
+	}
+	return venue.TimeZone
+}
+
+// GetEffectivePrice returns the price of the track at the given time on the
+// device, whose venue decides the time zone of time-of-day rules. deviceID
+// may be empty.
+func (s *Service) GetEffectivePrice(trackID int, at time.Time, deviceID string) (*EffectivePrice, error) {
+	track, err := s.repo.GetTrackByID(trackID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	rules, err := s.repo.GetTimePriceRules()
+	if err != nil {
+		return nil, err
+	}
+
+	price := effectivePrice(*track, rules, at, s.deviceTimeZone(deviceID))
+	return &price, nil
+}
+
+// GetPaymentPolicy defaults to accepting and flagging underpayments, which
+// keeps plays logging as they did before payments were validated.
+func (s *Service) GetPaymentPolicy() (string, error) {
+	policy, err := s.repo.GetSetting(paymentPolicySetting)
+	if err != nil {
+		return "", err
+	}
+	if policy == "" {
+		return PaymentPolicyFlag, nil
+	}
+	return policy, nil
+}
+
+func (s *Service) SetPaymentPolicy(policy string) error {
+	if policy != PaymentPolicyReject && policy != PaymentPolicyFlag {
+		return fmt.Errorf("%w: must be %q or %q", InvalidPaymentPolicy, PaymentPolicyReject, PaymentPolicyFlag)
+	}
+	return s.repo.SetSetting(paymentPolicySetting, policy)
+}
+
+// GetReconciliationReport sums each device's plays per day of its venue.
+func (s *Service) GetReconciliationReport(from, to time.Time) (*ReconciliationReport, error) {
+	buckets, err := s.repo.GetReconciliationBuckets(from, to)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+	locations, err := s.deviceLocations()
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+
+	type rowKey struct{ day, deviceID string }
+	byKey := make(map[rowKey]*ReconciliationRow)
+	var keys []rowKey
+	for _, b := range buckets {
+		loc := time.UTC
+		if l, ok := locations[b.DeviceID]; ok {
+			loc = l
+		}
+		key := rowKey{day: b.Start.In(loc).Format(time.DateOnly), deviceID: b.DeviceID}
+		row, ok := byKey[key]
+		if !ok {
+			row = &ReconciliationRow{Day: key.day, TimeZone: loc.String(), DeviceID: key.deviceID}
+			byKey[key] = row
+			keys = append(keys, key)
+		}
+		row.Plays += b.Plays
+		row.Underpaid += b.Underpaid
+		row.Overpaid += b.Overpaid
+		row.ExpectedTotal += b.ExpectedTotal
+		row.PaidTotal += b.PaidTotal
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].day != keys[j].day {
+			return keys[i].day < keys[j].day
+		}
+		return keys[i].deviceID < keys[j].deviceID
+	})
+
+	var rows []ReconciliationRow
+	for _, key := range keys {
+		row := byKey[key]
+		row.ExpectedTotal = roundPrice(row.ExpectedTotal)
+		row.PaidTotal = roundPrice(row.PaidTotal)
+		row.Difference = roundPrice(row.PaidTotal - row.ExpectedTotal)
+		rows = append(rows, *row)
+	}
+	discrepancies, err := s.repo.GetPaymentDiscrepancies(from, to, discrepancies)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+
+	return &ReconciliationReport{From: from, To: to, Rows: rows, Discrepancies: discrepancies}, nil
+}
+
+// VoidLog marks a playback as voided without deleting it. A nil refunded
+// amount refunds everything that was paid.
+func (s *Service) VoidLog(logID int, reason string, refundedAmount *float64) (*PlaybackLog, error) {
+	log, err := s.repo.GetLogByID(logID)
+	if err != nil {
+		return nil, PlaybackNotFoundError
+	}
+	if log.VoidedAt != nil {
+		return nil, PlaybackAlreadyVoided
+	}
+
+	refund := log.AmountPaid
+	if refundedAmount != nil {
+		refund = *refundedAmount
+	}
+	if refund < 0 || refund > log.AmountPaid+paymentTolerance {
+		return nil, InvalidRefund
+	}
+
+	now := time.Now()
+	if err := s.repo.VoidLog(logID, reason, refund, now); err != nil {
+		// Another request may have voided the log since it was read.
+		if current, getErr := s.repo.GetLogByID(logID); getErr == nil && current.VoidedAt != nil {
+			return nil, PlaybackAlreadyVoided
+		}
+		return nil, fmt.Errorf("%w: %v", FailedToVoidLog, err)
+	}
+
+	log.VoidedAt = &now
+	log.VoidReason = reason
+	log.RefundedAmount = refund
+	return log, nil
+}
+
+// EnqueueTrack validates the payment when the request is made; the playback
+// log itself is only created once the item starts playing.
+func (s *Service) EnqueueTrack(deviceID, sessionID string, trackID int, amountPaid float64) (*QueueItem, error) {
+	if deviceID == "" {
+		return nil, MissingDeviceID
+	}
+
+	now := time.Now()
+	log, err := s.preparePlayback(PlaybackLog{
+		TrackID:    trackID,
+		AmountPaid: amountPaid,
+		DeviceID:   deviceID,
+		SessionID:  sessionID,
+	}, now)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.repo.EnqueueTrack(QueueItem{
+		DeviceID:      deviceID,
+		SessionID:     sessionID,
+		TrackID:       log.TrackID,
+		AmountPaid:    amountPaid,
+		ListPrice:     log.ListPrice,
+		PaymentStatus: log.PaymentStatus,
+		Status:        QueueStatusQueued,
+		EnqueuedAt:    now,
+	})
+}
+
+func (s *Service) GetQueue(deviceID string) ([]QueueItem, error) {
+	if deviceID == "" {
+		return nil, MissingDeviceID
+	}
+	return s.repo.GetQueue(deviceID)
+}
+
+// StartQueueItem marks the item as playing and records its playback log.
+// A device plays one item at a time.
+func (s *Service) StartQueueItem(id int) (*QueueItem, error) {
+	item, err := s.repo.GetQueueItemByID(id)
+	if err != nil {
+		return nil, QueueItemNotFoundError
+	}
+	if item.Status != QueueStatusQueued {
+		return nil, fmt.Errorf("%w: item is %s", InvalidQueueTransition, item.Status)
+	}
+
+	queue, err := s.repo.GetQueue(item.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range queue {
+		if other.Status == QueueStatusPlaying {
+			return nil, fmt.Errorf("%w: item %d is already playing", InvalidQueueTransition, other.ID)
+		}
+	}
+
+	now := time.Now()
+	item.Status = QueueStatusPlaying
+	item.StartedAt = &now
+	log := PlaybackLog{
+		TrackID:       item.TrackID,
+		PlayedAt:      now,
+		AmountPaid:    item.AmountPaid,
+		DeviceID:      item.DeviceID,
+		SessionID:     item.SessionID,
+		ListPrice:     item.ListPrice,
+		PaymentStatus: item.PaymentStatus,
+	}
+	started, err := s.repo.StartQueueItem(*item, log)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+	}
+	return started, nil
+}
+
+func (s *Service) FinishQueueItem(id int) (*QueueItem, error) {
+	return s.endQueueItem(id, QueueStatusFinished)
+}
+
+// SkipQueueItem removes a queued item without logging a play, or ends the
+// item that is currently playing. A paid item skipped before it played is
+// recorded as a voided, fully refunded log.
+func (s *Service) SkipQueueItem(id int) (*QueueItem, error) {
+	return s.endQueueItem(id, QueueStatusSkipped)
+}
+
+func (s *Service) endQueueItem(id int, status string) (*QueueItem, error) {
+	item, err := s.repo.GetQueueItemByID(id)
+	if err != nil {
+		return nil, QueueItemNotFoundError
+	}
+	if item.Status != QueueStatusPlaying && !(status == QueueStatusSkipped && item.Status == QueueStatusQueued) {
+		return nil, fmt.Errorf("%w: cannot mark %s item as %s", InvalidQueueTransition, item.Status, status)
+	}
+
+	now := time.Now()
+	if item.Status == QueueStatusQueued && item.AmountPaid > 0 {
+		item.Status = status
+		item.FinishedAt = &now
+		log := PlaybackLog{
+			TrackID:        item.TrackID,
+			PlayedAt:       now,
+			AmountPaid:     item.AmountPaid,
+			DeviceID:       item.DeviceID,
+			SessionID:      item.SessionID,
+			ListPrice:      item.ListPrice,
+			PaymentStatus:  item.PaymentStatus,
+			VoidedAt:       &now,
+			VoidReason:     skippedBeforePlayingReason,
+			RefundedAmount: item.AmountPaid,
+		}
+		cancelled, err := s.repo.CancelQueueItem(*item, log)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+		}
+		return cancelled, nil
+	}
+	if item.Status == QueueStatusPlaying {
+		listened := int(now.Sub(*item.StartedAt).Seconds())
+		if status == QueueStatusFinished {
+			if track, err := s.repo.GetTrackByID(item.TrackID); err == nil && track.DurationSeconds > 0 {
+				listened = track.DurationSeconds
+			}
+		}
+		if _, err := s.CompleteLog(item.LogID, listened, status == QueueStatusSkipped); err != nil {
+			return nil, err
+		}
+	}
+
+	item.Status = status
+	item.FinishedAt = &now
+	if err := s.repo.UpdateQueueItem(*item); err != nil {
+		return nil, err
+	}
+	return item, nil
+}
+
+func (s *Service) SetTrackDuration(trackID int, seconds int) error {
+	if seconds < 0 {
+		return InvalidDuration
+	}
+	if err := s.repo.SetTrackDuration(trackID, seconds); err != nil {
+		return TrackNotFoundError
+	}
+	return nil
+}
+
+// UpdateTrackMetadata applies a partial metadata update. An ISRC identifies
+// one recording, so it cannot be given to two tracks.
+func (s *Service) UpdateTrackMetadata(trackID int, update TrackMetadata) (*Track, error) {
+	track, err := s.repo.GetTrackByID(trackID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	// The id may be one of a merged track's.
+	trackID = track.ID
+	updated := *track
+
+	if update.ISRC != nil {
+		isrc := normalizeISRC(*update.ISRC)
+		if isrc != "" {
+			if !isrcPattern.MatchString(isrc) {
+				return nil, InvalidISRC
+			}
+			other, err := s.repo.GetTrackByISRC(isrc)
+			if err != nil {
+				return nil, err
+			}
+			if other != nil && other.ID != trackID {
+				return nil, ExternalIDInUse
+			}
+		}
+		updated.ISRC = isrc
+	}
+	if update.Album != nil {
+		updated.Album = strings.TrimSpace(*update.Album)
+	}
+	if update.ReleaseYear != nil {
+		if *update.ReleaseYear < 0 || *update.ReleaseYear > time.Now().Year()+1 {
+			return nil, InvalidReleaseYear
+		}
+		updated.ReleaseYear = *update.ReleaseYear
+	}
+	if update.DurationSeconds != nil {
+		if *update.DurationSeconds < 0 {
+			return nil, InvalidDuration
+		}
+		updated.DurationSeconds = *update.DurationSeconds
+	}
+	if update.Tags != nil {
+		tags, err := normalizeTags(*update.Tags)
+		if err != nil {
+			return nil, err
+		}
+		updated.Tags = tags
+	}
+
+	if err := s.repo.UpdateTrackMetadata(updated); err != nil {
+		return nil, err
+	}
+	return &updated, nil
+}
+
+// LookupTrack finds a track by an identifier from outside the catalog: its
+// ISRC when source is "isrc", otherwise a partner id mapped to it.
+func (s *Service) LookupTrack(source, externalID string) (*Track, error) {
+	source = normalizeSource(source)
+	externalID = strings.TrimSpace(externalID)
+	if source == "" || externalID == "" {
+		return nil, InvalidExternalID
+	}
+
+	var track *Track
+	var err error
+	if source == isrcSource {
+		track, err = s.repo.GetTrackByISRC(normalizeISRC(externalID))
+	} else {
+		track, err = s.repo.GetTrackByExternalID(source, externalID)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if track == nil {
+		return nil, TrackNotFoundError
+	}
+	return track, nil
+}
+
+// SetExternalID maps a partner id to a track, replacing the track's
+// previous id from the same source.
+func (s *Service) SetExternalID(trackID int, source, externalID string) (*ExternalID, error) {
+	id := ExternalID{TrackID: trackID, Source: normalizeSource(source), ExternalID: strings.TrimSpace(externalID)}
+	if id.Source == "" || id.Source == isrcSource || id.ExternalID == "" {
+		return nil, InvalidExternalID
+	}
+	if _, err := s.repo.GetTrackByID(trackID); err != nil {
+		return nil, TrackNotFoundError
+	}
+
+	other, err := s.repo.GetTrackByExternalID(id.Source, id.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	if other != nil && other.ID != trackID {
+		return nil, ExternalIDInUse
+	}
+
+	if err := s.repo.SaveExternalID(id); err != nil {
+		return nil, err
+	}
+	return &id, nil
+}
+
+func (s *Service) DeleteExternalID(trackID int, source string) error {
+	if err := s.repo.DeleteExternalID(trackID, normalizeSource(source)); err != nil {
+		return ExternalIDNotFoundError
+	}
+	return nil
+}
+
+func (s *Service) GetExternalIDs(trackID int) ([]ExternalID, error) {
+	if _, err := s.repo.GetTrackByID(trackID); err != nil {
+		return nil, TrackNotFoundError
+	}
+	return s.repo.GetExternalIDs(trackID)
+}
+
+// GetTrackStats returns a track's all-time plays, revenue and rank with its
+// plays per day in the time zone, UTC when empty. Days are split at local
+// midnight like GetHeatmap splits hours.
+func (s *Service) GetTrackStats(trackID int, timeZone string) (*TrackStats, error) {
+	track, err := s.repo.GetTrackByID(trackID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", InvalidTimeZone, timeZone)
+	}
+
+	now := time.Now()
+	totals, err := s.repo.GetTrackTotals(time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	buckets, err := s.repo.GetPlayBuckets(HeatmapFilter{TrackID: track.ID, To: now})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	first, last, err := s.repo.GetTrackPlayRange(track.ID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+
+	stats := &TrackStats{Track: *track, TimeZone: loc.String(), FirstPlayedAt: first, LastPlayedAt: last, Days: []DailyPlays{}}
+	for _, total := range totals {
+		if total.TrackID == track.ID {
+			stats.Plays, stats.Revenue = total.Plays, total.Revenue
+		}
+	}
+	if stats.Plays > 0 {
+		// Ranked like GetTopTracks, by all-time plays.
+		rank := 1
+		for _, total := range totals {
+			if total.Plays > stats.Plays {
+				rank++
+			}
+		}
+		stats.Rank = &rank
+		stats.AveragePaid = math.Round(stats.Revenue/float64(stats.Plays)*100) / 100
+	}
+
+	byDay := make(map[string]*DailyPlays)
+	var firstDay, lastDay time.Time
+	for _, h := range buckets {
+		local := h.Start.In(loc)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+		if firstDay.IsZero() || day.Before(firstDay) {
+			firstDay = day
+		}
+		if day.After(lastDay) {
+			lastDay = day
+		}
+		date := day.Format(time.DateOnly)
+		d, ok := byDay[date]
+		if !ok {
+			d = &DailyPlays{Date: date}
+			byDay[date] = d
+		}
+		d.Plays += h.Plays
+		d.Revenue += h.Revenue
+	}
+	for day := firstDay; len(byDay) > 0 && !day.After(lastDay); day = day.AddDate(0, 0, 1) {
+		date := day.Format(time.DateOnly)
+		if d, ok := byDay[date]; ok {
+			stats.Days = append(stats.Days, *d)
+		} else {
+			stats.Days = append(stats.Days, DailyPlays{Date: date})
+		}
+	}
+	return stats, nil
+}
+
+// FindDuplicateTracks lists pairs of tracks that look like the same song,
+// most similar first. minScore 0 uses the default threshold.
+func (s *Service) FindDuplicateTracks(minScore float64) ([]DuplicateTrack, error) {
+	if minScore == 0 {
+		minScore = duplicateScore
+	}
+	if minScore < 0 || minScore > 1 {
+		return nil, InvalidDuplicateScore
+	}
+
+	tracks, err := s.repo.GetTracks()
+	if err != nil {
+		return nil, err
+	}
+	totals, err := s.repo.GetTrackTotals(time.Time{}, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	plays := make(map[int]int, len(totals))
+	for _, total := range totals {
+		plays[total.TrackID] = total.Plays
+	}
+	return findDuplicates(tracks, plays, minScore), nil
+}
+
+// MergeTracks folds a duplicate into the canonical track and returns the
+// canonical track. The duplicate's id keeps resolving to it.
+func (s *Service) MergeTracks(canonicalID, duplicateID int) (*Track, error) {
+	canonical, err := s.repo.GetTrackByID(canonicalID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	duplicate, err := s.repo.GetTrackByID(duplicateID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	if duplicate.ID != duplicateID || duplicate.ID == canonical.ID {
+		return nil, InvalidMerge
+	}
+
+	if err := s.repo.MergeTracks(canonical.ID, duplicate.ID, time.Now()); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTrackByID(canonical.ID)
+}
+
+// ImportCatalog creates or updates a track for every catalog entry. Nothing
+// is written when any row is invalid or dryRun is set; otherwise all rows
+// are applied in one transaction.
+func (s *Service) ImportCatalog(entries []CatalogEntry, dryRun bool) (*CatalogImport, error) {
+	// A write racing the import invalidates its plan; the second plan sees it.
+	for attempt := 1; ; attempt++ {
+		report, err := s.importCatalog(entries, dryRun)
+		if errors.Is(err, CatalogChanged) && attempt < catalogImportAttempts {
+			continue
+		}
+		return report, err
+	}
+}
+
+func (s *Service) importCatalog(entries []CatalogEntry, dryRun bool) (*CatalogImport, error) {
+	report, upserts, err := s.planCatalogImport(entries, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	report.DryRun = dryRun
+	if len(report.Errors) > 0 {
+		return report, InvalidCatalog
+	}
+	if dryRun || len(upserts) == 0 {
+		return report, nil
+	}
+
+	ids, err := s.repo.ImportCatalog(upserts, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	trackIDs := make(map[int]int, len(ids))
+	for i, upsert := range upserts {
+		trackIDs[upsert.Row] = ids[i]
+	}
+	for i := range report.Changes {
+		report.Changes[i].TrackID = trackIDs[report.Changes[i].Row]
+	}
+	return report, nil
+}
+
+// planCatalogImport matches each entry to a track by ISRC or partner id,
+// falling back to title and artist, and works out the resulting writes.
+// Rows that cannot be matched unambiguously, or that match the same track
+// or identifier as an earlier row, are reported as errors.
+func (s *Service) planCatalogImport(entries []CatalogEntry, now time.Time) (*CatalogImport, []CatalogUpsert, error) {
+	tracks, err := s.repo.GetTracks()
+	if err != nil {
+		return nil, nil, err
+	}
+	byID := make(map[int]Track, len(tracks))
+	byISRC := make(map[string]int)
+	byKey := make(map[string][]int)
+	lastTrackID := 0
+	for _, track := range tracks {
+		byID[track.ID] = track
+		lastTrackID = max(lastTrackID, track.ID)
+		if track.ISRC != "" {
+			byISRC[track.ISRC] = track.ID
+		}
+		key := catalogKey(track.Title, track.Artist)
+		byKey[key] = append(byKey[key], track.ID)
+	}
+
+	report := &CatalogImport{Changes: []CatalogChange{}}
+	var upserts []CatalogUpsert
+	claimed := make(map[string]int)
+	for i, entry := range entries {
+		row := i + 1
+		fail := func(err error) {
+			report.Errors = append(report.Errors, CatalogImportError{Row: row, Message: err.Error()})
+		}
+
+		entry, err := normalizeCatalogEntry(entry, now)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		sources := slices.Sorted(maps.Keys(entry.ExternalIDs))
+
+		// Identifiers decide the match; title and artist only match tracks
+		// whose identifiers do not contradict the row's.
+		matches := make(map[int]string)
+		if id, ok := byISRC[entry.ISRC]; ok && entry.ISRC != "" {
+			matches[id] = isrcSource
+		}
+		for _, source := range sources {
+			track, err := s.repo.GetTrackByExternalID(source, entry.ExternalIDs[source])
+			if err != nil {
+				return nil, nil, err
+			}
+			if track == nil {
+				continue
+			}
+			if _, ok := matches[track.ID]; !ok {
+				matches[track.ID] = source
+			}
+		}
+		if len(matches) > 1 {
+			fail(fmt.Errorf("identifiers belong to %d different tracks", len(matches)))
+			continue
+		}
+
+		var existingIDs map[string]string
+		if len(matches) == 0 {
+			var candidates []int
+			for _, id := range byKey[catalogKey(entry.Title, entry.Artist)] {
+				ids, err := s.externalIDMap(id)
+				if err != nil {
+					return nil, nil, err
+				}
+				if catalogCompatible(byID[id], ids, entry) {
+					candidates = append(candidates, id)
+					existingIDs = ids
+				}
+			}
+			if len(candidates) > 1 {
+				fail(fmt.Errorf("title and artist match %d tracks", len(candidates)))
+				continue
+			}
+			if len(candidates) == 1 {
+				matches[candidates[0]] = matchedByTitleArtist
+			}
+		}
+
+		var trackID int
+		var matchedBy string
+		for id, by := range matches {
+			trackID, matchedBy = id, by
+		}
+		if trackID != 0 && existingIDs == nil {
+			if existingIDs, err = s.externalIDMap(trackID); err != nil {
+				return nil, nil, err
+			}
+		}
+
+		var keys []string
+		if trackID != 0 {
+			keys = append(keys, "track:"+strconv.Itoa(trackID))
+		}
+		if entry.ISRC != "" {
+			keys = append(keys, "isrc:"+entry.ISRC)
+		}
+		for _, source := range sources {
+			keys = append(keys, "id:"+source+":"+entry.ExternalIDs[source])
+		}
+		if len(keys) == 0 {
+			keys = append(keys, "key:"+catalogKey(entry.Title, entry.Artist))
+		}
+		duplicate := 0
+		for _, key := range keys {
+			if earlier, ok := claimed[key]; ok && duplicate == 0 {
+				duplicate = earlier
+			}
+		}
+		if duplicate != 0 {
+			fail(fmt.Errorf("matches the same track as row %d", duplicate))
+			continue
+		}
+		for _, key := range keys {
+			claimed[key] = row
+		}
+
+		old := byID[trackID]
+		updated := applyCatalogEntry(old, entry)
+		if trackID == 0 && updated.Price <= 0 {
+			fail(fmt.Errorf("new tracks need a price: %w", PriceMustBeGreater))
+			continue
+		}
+		fields := diffTrack(old, updated)
+		upsert := CatalogUpsert{Row: row, Track: updated, Previous: old, LastTrackID: lastTrackID}
+		for _, source := range sources {
+			if existingIDs[source] == entry.ExternalIDs[source] {
+				continue
+			}
+			fields = append(fields, FieldChange{Field: "id:" + source, Old: existingIDs[source], New: entry.ExternalIDs[source]})
+			upsert.ExternalIDs = append(upsert.ExternalIDs, ExternalID{TrackID: trackID, Source: source, ExternalID: entry.ExternalIDs[source]})
+		}
+
+		change := CatalogChange{Row: row, TrackID: trackID, MatchedBy: matchedBy, Title: updated.Title, Artist: updated.Artist, Fields: fields}
+		switch {
+		case trackID == 0:
+			change.Action = CatalogCreate
+			report.Created++
+		case len(fields) > 0:
+			change.Action = CatalogUpdate
+			report.Updated++
+		default:
+			report.Unchanged++
+			continue
+		}
+		report.Changes = append(report.Changes, change)
+		upserts = append(upserts, upsert)
+	}
+	return report, upserts, nil
+}
+
+func (s *Service) externalIDMap(trackID int) (map[string]string, error) {
+	ids, err := s.repo.GetExternalIDs(trackID)
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[string]string, len(ids))
+	for _, id := range ids {
+		m[id.Source] = id.ExternalID
+	}
+	return m, nil
+}
+
+// CompleteLog records how long a playback was listened to. Listened time is
+// capped at the track duration when the duration is known.
+func (s *Service) CompleteLog(logID int, listenedSeconds int, skipped bool) (*PlaybackLog, error) {
+	if listenedSeconds < 0 {
+		return nil, InvalidDuration
+	}
+	log, err := s.repo.GetLogByID(logID)
+	if err != nil {
+		return nil, PlaybackNotFoundError
+	}
+	if log.CompletedAt != nil {
+		return nil, PlaybackAlreadyCompleted
+	}
+
+	if track, err := s.repo.GetTrackByID(log.TrackID); err == nil && track.DurationSeconds > 0 {
+		listenedSeconds = min(listenedSeconds, track.DurationSeconds)
+	}
+
+	now := time.Now()
+	if err := s.repo.CompleteLog(logID, listenedSeconds, skipped, now); err != nil {
+		return nil, PlaybackAlreadyCompleted
+	}
+
+	log.CompletedAt = &now
+	log.ListenedSeconds = listenedSeconds
+	log.Skipped = skipped
+	return log, nil
+}
+
+func (s *Service) GetSkipStats(from, to time.Time) ([]SkipStat, error) {
+	stats, err := s.repo.GetSkipStats(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].SkipRate > stats[j].SkipRate
+	})
+	return stats, nil
+}
+
+func (s *Service) GetAlerts(deviceID string, includeResolved bool) ([]Alert, error) {
+	return s.repo.GetAlerts(deviceID, includeResolved)
+}
+
+// ResolveAlert closes an alert. With release set, plays quarantined by the
+// alert are counted in statistics again.
+func (s *Service) ResolveAlert(id int, release bool) (*Alert, error) {
+	alert, err := s.repo.GetAlertByID(id)
+	if err != nil {
+		return nil, AlertNotFoundError
+	}
+	if alert.ResolvedAt != nil {
+		return nil, AlertAlreadyResolved
+	}
+
+	now := time.Now()
+	if err := s.repo.ResolveAlert(id, now, release); err != nil {
+		return nil, AlertAlreadyResolved
+	}
+
+	alert.ResolvedAt = &now
+	if release {
+		alert.Quarantined = false
+	}
+	return alert, nil
+}
+
+func (s *Service) GetAnomalyQuarantine() (bool, error) {
+	value, err := s.repo.GetSetting(anomalyQuarantineSetting)
+	if err != nil {
+		return false, err
+	}
+	return value == "true", nil
+}
+
+func (s *Service) SetAnomalyQuarantine(enabled bool) error {
+	return s.repo.SetSetting(anomalyQuarantineSetting, strconv.FormatBool(enabled))
+}
+
+// Heartbeat registers the device on first contact and records when it was
+// last seen. An open offline alert for the device is resolved.
+func (s *Service) Heartbeat(deviceID, firmwareVersion, venueID string) (*Device, error) {
+	if deviceID == "" {
+		return nil, MissingDeviceID
+	}
+	if venueID != "" {
+		if _, err := s.repo.GetVenueByID(venueID); err != nil {
+			return nil, VenueNotFoundError
+		}
+	}
+
+	now := time.Now()
+	device, err := s.repo.RecordHeartbeat(Device{
+		DeviceID:        deviceID,
+		VenueID:         venueID,
+		FirmwareVersion: firmwareVersion,
+		LastSeenAt:      now,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	open, err := s.repo.GetOpenAlert(deviceID, AlertDeviceOffline)
+	if err != nil {
+		return nil, err
+	}
+	if open != nil {
+		if err := s.repo.ResolveAlert(open.ID, now, false); err != nil {
+			return nil, err
+		}
+	}
+
+	device.Online = true
+	return device, nil
+}
+
+func (s *Service) GetDevices() ([]Device, error) {
+	devices, err := s.repo.GetDevices()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for i := range devices {
+		devices[i].Online = now.Sub(devices[i].LastSeenAt) < deviceOfflineAfter
+	}
+	return devices, nil
+}
+
+func (s *Service) GetDevice(deviceID string) (*Device, error) {
+	device, err := s.repo.GetDeviceByID(deviceID)
+	if err != nil {
+		return nil, DeviceNotFoundError
+	}
+	device.Online = time.Since(device.LastSeenAt) < deviceOfflineAfter
+	return device, nil
+}
+
+func (s *Service) SaveVenue(venue Venue) (*Venue, error) {
+	if venue.ID == "" {
+		return nil, fmt.Errorf("%w: id is required", InvalidVenue)
+	}
+	if venue.TimeZone == "" {
+		venue.TimeZone = "UTC"
+	}
+	if _, err := time.LoadLocation(venue.TimeZone); err != nil {
+		return nil, fmt.Errorf("%w: unknown time zone %q", InvalidVenue, venue.TimeZone)
+	}
+
+	if (venue.OpensAt == "") != (venue.ClosesAt == "") {
+		return nil, fmt.Errorf("%w: set both opens_at and closes_at, or neither", InvalidVenue)
+	}
+	if venue.OpensAt != "" {
+		opens, err := parseClock(venue.OpensAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", InvalidVenue, err)
+		}
+		closes, err := parseClock(venue.ClosesAt)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", InvalidVenue, err)
+		}
+		if opens == closes {
+			return nil, fmt.Errorf("%w: opens_at and closes_at must differ", InvalidVenue)
+		}
+	}
+
+	for _, day := range venue.Weekdays {
+		if day < 0 || day > 6 {
+			return nil, fmt.Errorf("%w: weekdays must be between 0 (Sunday) and 6 (Saturday)", InvalidVenue)
+		}
+	}
+	if venue.Weekdays == nil {
+		venue.Weekdays = []int{}
+	}
+
+	if err := s.repo.SaveVenue(venue); err != nil {
+		return nil, err
+	}
+	return &venue, nil
+}
+
+func (s *Service) GetVenues() ([]Venue, error) {
+	return s.repo.GetVenues()
+}
+
+// pageLimit applies the default page size and validates a page request.
+func pageLimit(limit, offset int) (int, error) {
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	if limit < 0 || limit > maxSearchLimit || offset < 0 {
+		return 0, InvalidPagination
+	}
+	return limit, nil
+}
+
+// SearchTracks finds tracks by title or artist. A zero limit uses the default
+// page size; an empty query lists the whole catalog.
+func (s *Service) SearchTracks(query string, limit, offset int) (*TrackSearchResult, error) {
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	tracks, total, err := s.repo.SearchTracks(query, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if tracks == nil {
+		tracks = []Track{}
+	}
+	return &TrackSearchResult{Tracks: tracks, Total: total, Limit: limit, Offset: offset}, nil
+}
+
+func (s *Service) GetArtists() ([]Artist, error) {
+	return s.repo.GetArtists()
+}
+
+// GetTopArtists ranks artists by plays or net revenue in [from, to).
+func (s *Service) GetTopArtists(sortBy string, limit int, from, to time.Time) ([]ArtistStat, error) {
+	if sortBy == "" {
+		sortBy = "plays"
+	}
+	if sortBy != "plays" && sortBy != "revenue" {
+		return nil, InvalidSortOrder
+	}
+	if limit == 0 {
+		limit = topArtists
+	}
+	if limit < 0 || limit > maxSearchLimit {
+		return nil, InvalidPagination
+	}
+
+	stats, err := s.repo.GetArtistStats(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+
+	sort.Slice(stats, func(i, j int) bool {
+		a, b := stats[i], stats[j]
+		if sortBy == "revenue" && a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		if a.Plays != b.Plays {
+			return a.Plays > b.Plays
+		}
+		if a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		return a.Name < b.Name
+	})
+	if len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+func (s *Service) GetArtistBreakdown(artistID int, from, to time.Time) (*ArtistBreakdown, error) {
+	artist, err := s.repo.GetArtistByID(artistID)
+	if err != nil {
+		return nil, ArtistNotFoundError
+	}
+
+	tracks, err := s.repo.GetArtistTrackStats(artistID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	sort.SliceStable(tracks, func(i, j int) bool {
+		return tracks[i].Plays > tracks[j].Plays
+	})
+
+	breakdown := &ArtistBreakdown{Artist: *artist, Tracks: tracks}
+	if breakdown.Tracks == nil {
+		breakdown.Tracks = []TrackStat{}
+	}
+	for _, track := range tracks {
+		breakdown.Plays += track.Plays
+		breakdown.Revenue += track.Revenue
+	}
+	return breakdown, nil
+}
+
+// GetHeatmap buckets plays by local weekday and hour. A venue filter implies
+// the venue's time zone; otherwise timeZone is used, defaulting to UTC.
+// Plays past the retention period only keep their UTC hour, so in zones
+// offset by a fraction of an hour they count in the local hour it starts in.
+func (s *Service) GetHeatmap(filter HeatmapFilter, timeZone string) (*Heatmap, error) {
+	filter, loc, err := s.checkHeatmapFilter(filter, timeZone)
+	if err != nil {
+		return nil, err
+	}
+
+	buckets, err := s.repo.GetPlayBuckets(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+
+	heatmap := &Heatmap{TimeZone: loc.String()}
+	for _, h := range buckets {
+		local := h.Start.In(loc)
+		cell := &heatmap.Cells[local.Weekday()][local.Hour()]
+		cell.Plays += h.Plays
+		cell.Revenue += h.Revenue
+	}
+	return heatmap, nil
+}
+
+// checkHeatmapFilter validates the filter and resolves the time zone hours
+// are shown in: the venue's if there is one, else timeZone or UTC.
+func (s *Service) checkHeatmapFilter(filter HeatmapFilter, timeZone string) (HeatmapFilter, *time.Location, error) {
+	if filter.VenueID != "" {
+		venue, err := s.repo.GetVenueByID(filter.VenueID)
+		if err != nil {
+			return filter, nil, VenueNotFoundError
+		}
+		timeZone = venue.TimeZone
+	}
+	if timeZone == "" {
+		timeZone = "UTC"
+	}
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return filter, nil, fmt.Errorf("%w: %q", InvalidTimeZone, timeZone)
+	}
+	if filter.TrackID != 0 {
+		if _, err := s.repo.GetTrackByID(filter.TrackID); err != nil {
+			return filter, nil, TrackNotFoundError
+		}
+	}
+	if filter.ArtistID != 0 {
+		if _, err := s.repo.GetArtistByID(filter.ArtistID); err != nil {
+			return filter, nil, ArtistNotFoundError
+		}
+	}
+	if filter.Tag != "" {
+		tag, err := s.getTag(filter.Tag)
+		if err != nil {
+			return filter, nil, err
+		}
+		filter.Tag = tag.Name
+	}
+	return filter, loc, nil
+}
+
+// GetTagStats splits plays and revenue by tag. A weekday or hour of -1
+// means any; otherwise only plays at that local weekday and hour count,
+// placed like GetHeatmap places them.
+func (s *Service) GetTagStats(filter HeatmapFilter, timeZone string, weekday, hour int) (*TagStats, error) {
+	if weekday < -1 || weekday > 6 || hour < -1 || hour > 23 {
+		return nil, InvalidWeekdayOrHour
+	}
+	filter, loc, err := s.checkHeatmapFilter(filter, timeZone)
+	if err != nil {
+		return nil, err
+	}
+	matches := func(t time.Time) bool {
+		local := t.In(loc)
+		return (weekday == -1 || int(local.Weekday()) == weekday) && (hour == -1 || local.Hour() == hour)
+	}
+
+	tagBuckets, err := s.repo.GetTagPlayBuckets(filter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	// Shares are of all revenue in the slot, not just the filtered tag's.
+	totalFilter := filter
+	totalFilter.Tag = ""
+	buckets, err := s.repo.GetPlayBuckets(totalFilter)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+
+	stats := &TagStats{TimeZone: loc.String(), Tags: []TagStat{}}
+	if weekday != -1 {
+		stats.Weekday = &weekday
+	}
+	if hour != -1 {
+		stats.Hour = &hour
+	}
+	for _, h := range buckets {
+		if matches(h.Start) {
+			stats.TotalPlays += h.Plays
+			stats.TotalRevenue += h.Revenue
+		}
+	}
+
+	byTag := make(map[int]*TagStat)
+	for _, h := range tagBuckets {
+		if !matches(h.Start) {
+			continue
+		}
+		stat, ok := byTag[h.TagID]
+		if !ok {
+			stat = &TagStat{TagID: h.TagID, Tag: h.Tag}
+			byTag[h.TagID] = stat
+		}
+		stat.Plays += h.Plays
+		stat.Revenue += h.Revenue
+	}
+	for _, stat := range byTag {
+		if stats.TotalRevenue > 0 {
+			stat.RevenueShare = math.Round(stat.Revenue/stats.TotalRevenue*10000) / 10000
+		}
+		stats.Tags = append(stats.Tags, *stat)
+	}
+	sort.Slice(stats.Tags, func(i, j int) bool {
+		a, b := stats.Tags[i], stats.Tags[j]
+		if a.Revenue != b.Revenue {
+			return a.Revenue > b.Revenue
+		}
+		if a.Plays != b.Plays {
+			return a.Plays > b.Plays
+		}
+		return a.Tag < b.Tag
+	})
+	return stats, nil
+}
+
+func (s *Service) GetTags() ([]Tag, error) {
+	tags, err := s.repo.GetTags()
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []Tag{}
+	}
+	return tags, nil
+}
+
+// getTag looks a tag up by its name in any case.
+func (s *Service) getTag(name string) (*Tag, error) {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	tag, err := s.repo.GetTagByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if tag == nil {
+		return nil, TagNotFoundError
+	}
+	return tag, nil
+}
+
+func (s *Service) CreateTag(name string) (*Tag, error) {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetTagByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, TagExists
+	}
+	return s.repo.CreateTag(name)
+}
+
+// RenameTag renames a tag on every track that has it.
+func (s *Service) RenameTag(id int, name string) (*Tag, error) {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.repo.GetTagByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != id {
+		return nil, TagExists
+	}
+	if err := s.repo.RenameTag(id, name); err != nil {
+		return nil, TagNotFoundError
+	}
+	return s.repo.GetTagByName(name)
+}
+
+// DeleteTag removes a tag from every track and deletes it.
+func (s *Service) DeleteTag(id int) error {
+	if err := s.repo.DeleteTag(id); err != nil {
+		return TagNotFoundError
+	}
+	return nil
+}
+
+// TagTrack adds a tag to a track, creating the tag if needed, and returns
+// the track.
+func (s *Service) TagTrack(trackID int, name string) (*Track, error) {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return nil, err
+	}
+	track, err := s.repo.GetTrackByID(trackID)
+	if err != nil {
+		return nil, TrackNotFoundError
+	}
+	if err := s.repo.TagTrack(track.ID, name); err != nil {
+		return nil, err
+	}
+	return s.repo.GetTrackByID(track.ID)
+}
+
+func (s *Service) UntagTrack(trackID int, name string) error {
+	name, err := normalizeTag(name)
+	if err != nil {
+		return err
+	}
+	track, err := s.repo.GetTrackByID(trackID)
+	if err != nil {
+		return TrackNotFoundError
+	}
+	if err := s.repo.UntagTrack(track.ID, name); err != nil {
+		return TagNotFoundError
+	}
+	return nil
+}
+
+// GetTagTopTracks returns the most played tracks with the tag; an empty tag
+// is the same as GetTopTracks.
+func (s *Service) GetTagTopTracks(name string) ([]TopTrackStat, error) {
+	if name == "" {
+		return s.GetTopTracks()
+	}
+	tag, err := s.getTag(name)
+	if err != nil {
+		return nil, err
+	}
+	top, err := s.repo.GetTagTopTracks(topTracks, tag.Name)
+	if err != nil {
+		return nil, FailedToGetStats
+	}
+	return top, nil
+}
+
+func (s *Service) GetCharts() ([]ChartSnapshot, error) {
+	snapshots, err := s.repo.GetChartSnapshots()
+	if err != nil {
+		return nil, err
+	}
+	if snapshots == nil {
+		snapshots = []ChartSnapshot{}
+	}
+	return snapshots, nil
+}
+
+// GetChart returns a chart snapshot with its entries; id 0 means the latest.
+func (s *Service) GetChart(id int) (*ChartSnapshot, error) {
+	if id == 0 {
+		snapshots, err := s.repo.GetChartSnapshots()
+		if err != nil {
+			return nil, err
+		}
+		if len(snapshots) == 0 {
+			return nil, ChartNotFoundError
+		}
+		id = snapshots[0].ID
+	}
+
+	snapshot, err := s.repo.GetChartSnapshot(id)
+	if err != nil {
+		return nil, ChartNotFoundError
+	}
+	return snapshot, nil
+}
+
+// walletTransactions returns the credit debit and loyalty reward, if any,
+// that go with a customer's play.
+func (s *Service) walletTransactions(log PlaybackLog) ([]WalletTransaction, error) {
+	customer, err := s.repo.GetCustomer(log.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+	}
+	if customer == nil {
+		customer = &Customer{ID: log.CustomerID}
+	}
+	rule, err := s.GetLoyaltyRule()
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToCreateLog, err)
+	}
+
+	var wallet []WalletTransaction
+	if log.PaidWithCredit {
+		if customer.Balance < log.AmountPaid-paymentTolerance {
+			return nil, fmt.Errorf("%w: balance %.2f, price %.2f", InsufficientCredit, customer.Balance, log.AmountPaid)
+		}
+		wallet = append(wallet, WalletTransaction{
+			CustomerID: log.CustomerID,
+			Kind:       WalletDebit,
+			Amount:     -log.AmountPaid,
+			CreatedAt:  log.PlayedAt,
+		})
+	}
+	if reward := loyaltyReward(customer.Plays+1, rule.FreeEvery, log.ListPrice); reward > 0 {
+		wallet = append(wallet, WalletTransaction{
+			CustomerID: log.CustomerID,
+			Kind:       WalletLoyalty,
+			Amount:     reward,
+			CreatedAt:  log.PlayedAt,
+		})
+	}
+	return wallet, nil
+}
+
+func (s *Service) GetCustomer(id string) (*Customer, error) {
+	customer, err := s.repo.GetCustomer(id)
+	if err != nil {
+		return nil, err
+	}
+	if customer == nil {
+		return nil, CustomerNotFoundError
+	}
+	return customer, nil
+}
+
+// TopUpCredit adds prepaid credit, registering the customer on first use.
+func (s *Service) TopUpCredit(customerID string, amount float64) (*Customer, error) {
+	if customerID == "" {
+		return nil, MissingCustomerID
+	}
+	if amount <= 0 {
+		return nil, InvalidTopUp
+	}
+	return s.repo.TopUpCredit(customerID, amount, time.Now())
+}
+
+func (s *Service) GetCustomerLogs(customerID string, limit, offset int) ([]PlaybackLog, error) {
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetCustomer(customerID); err != nil {
+		return nil, err
+	}
+
+	logs, err := s.repo.GetCustomerLogs(customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if logs == nil {
+		logs = []PlaybackLog{}
+	}
+	return logs, nil
+}
+
+func (s *Service) GetWalletTransactions(customerID string, limit, offset int) ([]WalletTransaction, error) {
+	limit, err := pageLimit(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.GetCustomer(customerID); err != nil {
+		return nil, err
+	}
+
+	transactions, err := s.repo.GetWalletTransactions(customerID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	if transactions == nil {
+		transactions = []WalletTransaction{}
+	}
+	return transactions, nil
+}
+
+func (s *Service) GetLoyaltyRule() (LoyaltyRule, error) {
+	value, err := s.repo.GetSetting(loyaltySetting)
+	if err != nil || value == "" {
+		return LoyaltyRule{}, err
+	}
+	freeEvery, err := strconv.Atoi(value)
+	if err != nil {
+		return LoyaltyRule{}, err
+	}
+	return LoyaltyRule{FreeEvery: freeEvery}, nil
+}
+
+// SetLoyaltyRule configures loyalty rewards; FreeEvery 0 disables them.
+func (s *Service) SetLoyaltyRule(rule LoyaltyRule) error {
+	if rule.FreeEvery < 0 {
+		return InvalidLoyaltyRule
+	}
+	return s.repo.SetSetting(loyaltySetting, strconv.Itoa(rule.FreeEvery))
+}
+
+// SaveRoyaltyRate sets the rate for an artist or, when TrackID is set, for a
+// single track, replacing any previous rate for the same target.
+func (s *Service) SaveRoyaltyRate(rate RoyaltyRate) (*RoyaltyRate, error) {
+	if rate.Rate < 0 || rate.Rate > 1 {
+		return nil, fmt.Errorf("%w: rate must be between 0 and 1", InvalidRoyaltyRate)
+	}
+	switch {
+	case rate.TrackID != 0 && rate.ArtistID == 0:
+		if _, err := s.repo.GetTrackByID(rate.TrackID); err != nil {
+			return nil, TrackNotFoundError
+		}
+	case rate.ArtistID != 0 && rate.TrackID == 0:
+		if _, err := s.repo.GetArtistByID(rate.ArtistID); err != nil {
+			return nil, ArtistNotFoundError
+		}
+	default:
+		return nil, fmt.Errorf("%w: exactly one of artist_id and track_id is required", InvalidRoyaltyRate)
+	}
+	return s.repo.SaveRoyaltyRate(rate)
+}
+
+func (s *Service) GetRoyaltyRates() ([]RoyaltyRate, error) {
+	rates, err := s.repo.GetRoyaltyRates()
+	if err != nil {
+		return nil, err
+	}
+	if rates == nil {
+		rates = []RoyaltyRate{}
+	}
+	return rates, nil
+}
+
+func (s *Service) DeleteRoyaltyRate(id int) error {
+	if err := s.repo.DeleteRoyaltyRate(id); err != nil {
+		return RoyaltyRateNotFoundError
+	}
+	return nil
+}
+
+// PreviewRoyaltyStatement computes a statement with the current rates
+// without closing it.
+func (s *Service) PreviewRoyaltyStatement(artistID int, from, to time.Time) (*RoyaltyStatement, error) {
+	if !from.Before(to) {
+		return nil, InvalidStatementPeriod
+	}
+	artist, err := s.repo.GetArtistByID(artistID)
+	if err != nil {
+		return nil, ArtistNotFoundError
+	}
+	tracks, err := s.repo.GetArtistTrackStats(artistID, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	rates, err := s.repo.GetRoyaltyRates()
+	if err != nil {
+		return nil, err
+	}
+	return buildStatement(*artist, tracks, rates, from, to), nil
+}
+
+// CloseRoyaltyStatement computes and persists the statement for a period
+// that has ended. Closed statements never change and may not overlap.
+func (s *Service) CloseRoyaltyStatement(artistID int, from, to time.Time) (*RoyaltyStatement, error) {
+	now := time.Now()
+	if to.After(now) {
+		return nil, InvalidStatementPeriod
+	}
+	statement, err := s.PreviewRoyaltyStatement(artistID, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	statement.ClosedAt = &now
+	return s.repo.SaveRoyaltyStatement(*statement)
+}
+
+func (s *Service) GetRoyaltyStatements(artistID int) ([]RoyaltyStatement, error) {
+	statements, err := s.repo.GetRoyaltyStatements(artistID)
+	if err != nil {
+		return nil, err
+	}
+	if statements == nil {
+		statements = []RoyaltyStatement{}
+	}
+	return statements, nil
+}
+
+func (s *Service) GetRoyaltyStatement(id int) (*RoyaltyStatement, error) {
+	statement, err := s.repo.GetRoyaltyStatement(id)
+	if err != nil {
+		return nil, StatementNotFoundError
+	}
+	return statement, nil
+}
+
+// GetUsageReport counts the venue's performances per track and local date,
+// from fromDate to toDate inclusive, in the venue's time zone. Rollups keep
+// no venue, so periods the retention job has rolled up are refused.
+func (s *Service) GetUsageReport(venueID, fromDate, toDate string) (*UsageReport, error) {
+	if venueID == "" {
+		return nil, MissingVenueID
+	}
+	venue, err := s.repo.GetVenueByID(venueID)
+	if err != nil {
+		return nil, VenueNotFoundError
+	}
+	loc, err := time.LoadLocation(venue.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", InvalidTimeZone, venue.TimeZone)
+	}
+	from, err := time.ParseInLocation(time.DateOnly, fromDate, loc)
+	if err != nil {
+		return nil, InvalidUsagePeriod
+	}
+	to, err := time.ParseInLocation(time.DateOnly, toDate, loc)
+	if err != nil || to.Before(from) {
+		return nil, InvalidUsagePeriod
+	}
+
+	end := to.AddDate(0, 0, 1)
+	// Rollups start on the UTC hour, which may be before local midnight.
+	purged, err := s.repo.HasRollups(from.Truncate(time.Hour), end)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+	if purged {
+		return nil, UsagePeriodPurged
+	}
+	plays, err := s.repo.GetVenueTrackPlays(venueID, from, end)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", FailedToGetStats, err)
+	}
+
+	type key struct {
+		date    string
+		trackID int
+	}
+	counts := make(map[key]int)
+	for _, p := range plays {
+		counts[key{p.Start.In(loc).Format(time.DateOnly), p.TrackID}] += p.Plays
+	}
+
+	report := &UsageReport{VenueID: venueID, From: fromDate, To: toDate, Rows: []UsageRow{}}
+	tracks := make(map[int]*Track)
+	for k, count := range counts {
+		track, ok := tracks[k.trackID]
+		if !ok {
+			if track, err = s.repo.GetTrackByID(k.trackID); err != nil {
+				track = &Track{ID: k.trackID}
+			}
+			tracks[k.trackID] = track
+		}
+		report.Rows = append(report.Rows, UsageRow{
+			VenueID: venueID,
+			Date:    k.date,
+			TrackID: track.ID,
+			ISRC:    track.ISRC,
+			Title:   track.Title,
+			Artist:  track.Artist,
+			Plays:   count,
+		})
+	}
+	sort.Slice(report.Rows, func(i, j int) bool {
+		a, b := report.Rows[i], report.Rows[j]
+		if a.Date != b.Date {
+			return a.Date < b.Date
+		}
+		if a.Title != b.Title {
+			return a.Title < b.Title
+		}
+		return a.TrackID < b.TrackID
+	})
+	return report, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defaultUsageColumns is the column layout used when a licensing body does
+// not ask for its own.
+const defaultUsageColumns = "venue_id,date,isrc,title,artist,plays"
+
+var usageFields = map[string]func(UsageRow) string{
+	"venue_id": func(row UsageRow) string { return row.VenueID },
+	"date":     func(row UsageRow) string { return row.Date },
+	"track_id": func(row UsageRow) string { return strconv.Itoa(row.TrackID) },
+	"isrc":     func(row UsageRow) string { return row.ISRC },
+	"title":    func(row UsageRow) string { return row.Title },
+	"artist":   func(row UsageRow) string { return row.Artist },
+	"plays":    func(row UsageRow) string { return strconv.Itoa(row.Plays) },
+}
+
+// UsageFormat is the fixed column layout of a usage report file.
+type UsageFormat struct {
+	Fields    []string
+	Headers   []string
+	Delimiter rune
+}
+
+// parseUsageFormat parses a column spec such as "isrc:ISRC,title:Work,plays",
+// where each field may be given its own header, and a one-character
+// delimiter. Empty values select the defaults.
+func parseUsageFormat(columns, delimiter string) (*UsageFormat, error) {
+	if columns == "" {
+		columns = defaultUsageColumns
+	}
+	format := &UsageFormat{Delimiter: ','}
+	for _, column := range strings.Split(columns, ",") {
+		field, header, renamed := strings.Cut(strings.TrimSpace(column), ":")
+		if _, ok := usageFields[field]; !ok {
+			return nil, fmt.Errorf("%w: unknown column %q", InvalidUsageFormat, field)
+		}
+		if !renamed {
+			header = field
+		}
+		format.Fields = append(format.Fields, field)
+		format.Headers = append(format.Headers, header)
+	}
+
+	if delimiter != "" {
+		r, size := utf8.DecodeRuneInString(delimiter)
+		if size != len(delimiter) || r == '"' || r == '\r' || r == '\n' || r == utf8.RuneError {
+			return nil, fmt.Errorf("%w: delimiter must be a single character", InvalidUsageFormat)
+		}
+		format.Delimiter = r
+	}
+	return format, nil
+}
+
+func writeUsageCSV(w io.Writer, report *UsageReport, format *UsageFormat) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = format.Delimiter
+	cw.Write(format.Headers)
+	for _, row := range report.Rows {
+		record := make([]string, len(format.Fields))
+		for i, field := range format.Fields {
+			record[i] = usageFields[field](row)
+		}
+		cw.Write(record)
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// usageFileName names the file for a venue and period.
+func usageFileName(report *UsageReport) string {
+	return fmt.Sprintf("usage_%s_%s_%s.csv", unsafeFileChars.ReplaceAllString(report.VenueID, "_"), report.From, report.To)
+}
+
+// runUsageReport implements the usage-report command, which writes one
+// usage file per venue for the period.
+func runUsageReport(args []string) error {
+	flags := flag.NewFlagSet("usage-report", flag.ContinueOnError)
+	db := flags.String("db", dbPath, "SQLite database `path`")
+	venueID := flags.String("venue", "", "venue to report on; every venue when empty")
+	from := flags.String("from", "", "first local `date` of the period (YYYY-MM-DD)")
+	to := flags.String("to", "", "last local `date` of the period, inclusive (YYYY-MM-DD)")
+	out := flags.String("out", ".", "`directory` to write the files to")
+	columns := flags.String("columns", defaultUsageColumns, "comma-separated columns, each optionally renamed as field:Header")
+	delimiter := flags.String("delimiter", ",", "field delimiter")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	format, err := parseUsageFormat(*columns, *delimiter)
+	if err != nil {
+		return err
+	}
+	repo, err := NewSQLiteRepository(*db)
+	if err != nil {
+		return err
+	}
+	service := NewService(repo)
+
+	venueIDs := []string{*venueID}
+	if *venueID == "" {
+		venues, err := service.GetVenues()
+		if err != nil {
+			return err
+		}
+		venueIDs = venueIDs[:0]
+		for _, venue := range venues {
+			venueIDs = append(venueIDs, venue.ID)
+		}
+	}
+
+	for _, id := range venueIDs {
+		report, err := service.GetUsageReport(id, *from, *to)
+		if err != nil {
+			return fmt.Errorf("venue %s: %w", id, err)
+		}
+		path := filepath.Join(*out, usageFileName(report))
+		if err := writeUsageFile(path, report, format); err != nil {
+			return fmt.Errorf("venue %s: %w", id, err)
+		}
+		slog.Info("usage report written", "venue_id", id, "path", path, "rows", len(report.Rows))
+	}
+	return nil
+}
+
+func writeUsageFile(path string, report *UsageReport, format *UsageFormat) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := writeUsageCSV(f, report, format); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
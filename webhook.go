@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+const (
+	EventPlaybackLogged = "playback.logged"
+	EventPlaybackVoided = "playback.voided"
+	EventPriceUpdated   = "price.updated"
+	EventDeviceOffline  = "device.offline"
+)
+
+var webhookEventTypes = []string{EventPlaybackLogged, EventPlaybackVoided, EventPriceUpdated, EventDeviceOffline}
+
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed"
+)
+
+const (
+	webhookBatchSize      = 100
+	webhookMaxAttempts    = 8
+	webhookInitialBackoff = 30 * time.Second
+	webhookMaxBackoff     = time.Hour
+	webhookTimeout        = 10 * time.Second
+
+	SignatureHeader = "X-Jukebox-Signature"
+	TimestampHeader = "X-Jukebox-Timestamp"
+)
+
+func (s WebhookSubscription) Subscribes(eventType string) bool {
+	return slices.Contains(s.EventTypes, eventType)
+}
+
+type webhookEnvelope struct {
+	ID        int             `json:"id"`
+	Type      string          `json:"type"`
+	CreatedAt time.Time       `json:"created_at"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// SignWebhook returns the value of the signature header for a delivery body:
+// hex HMAC-SHA256 of "<timestamp>.<body>" keyed with the subscription secret.
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// WebhookDispatcher fans out outbox events to matching subscriptions and
+// delivers them, retrying failed deliveries with exponential backoff.
+type WebhookDispatcher struct {
+	repo   IRepository
+	client *http.Client
+}
+
+func NewWebhookDispatcher(repo IRepository) *WebhookDispatcher {
+	return &WebhookDispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func (d *WebhookDispatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.RunOnce(ctx, time.Now()); err != nil {
+			slog.Error("webhook dispatcher failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *WebhookDispatcher) RunOnce(ctx context.Context, now time.Time) error {
+	for {
+		n, err := d.repo.DispatchOutbox(webhookBatchSize)
+		if err != nil {
+			return fmt.Errorf("failed to dispatch outbox: %w", err)
+		}
+		if n < webhookBatchSize {
+			break
+		}
+	}
+
+	deliveries, err := d.repo.GetDueDeliveries(now, webhookBatchSize)
+	if err != nil {
+		return fmt.Errorf("failed to get due deliveries: %w", err)
+	}
+
+	for _, delivery := range deliveries {
+		d.deliver(ctx, &delivery, now)
+		if err := d.repo.UpdateDelivery(delivery); err != nil {
+			return fmt.Errorf("failed to record delivery %d: %w", delivery.ID, err)
+		}
+	}
+	return nil
+}
+
+func (d *WebhookDispatcher) deliver(ctx context.Context, delivery *WebhookDelivery, now time.Time) {
+	delivery.Attempts++
+	statusCode, err := d.send(ctx, delivery, now)
+	delivery.LastStatusCode = statusCode
+
+	if err == nil {
+		delivery.Status = DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = DeliveryFailed
+		slog.Warn("webhook delivery failed permanently", "delivery_id", delivery.ID, "url", delivery.URL, "error", err)
+		return
+	}
+
+	backoff := webhookInitialBackoff << (delivery.Attempts - 1)
+	if backoff > webhookMaxBackoff {
+		backoff = webhookMaxBackoff
+	}
+	delivery.NextAttemptAt = now.Add(backoff)
+}
+
+func (d *WebhookDispatcher) send(ctx context.Context, delivery *WebhookDelivery, now time.Time) (int, error) {
+	body, err := json.Marshal(webhookEnvelope{
+		ID:        delivery.Event.ID,
+		Type:      delivery.Event.Type,
+		CreatedAt: delivery.Event.CreatedAt,
+		Data:      delivery.Event.Data,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	timestamp := now.Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Jukebox-Event", delivery.Event.Type)
+	req.Header.Set("X-Jukebox-Delivery", strconv.Itoa(delivery.ID))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(SignatureHeader, SignWebhook(delivery.Secret, timestamp, body))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}